@@ -0,0 +1,287 @@
+package bot
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/edge2992/yatzcli/engine"
+)
+
+// DefaultExecTimeout is how long an ExecStrategy waits for each decision.
+const DefaultExecTimeout = 5 * time.Second
+
+// ExecStrategy implements engine.Strategy with an external program, so bots
+// can be written in any language. The program is started on the first
+// decision and kept running. For each decision it reads an ExecRequest as
+// one line of JSON on stdin and must answer with an ExecResponse as one line
+// of JSON on stdout.
+//
+// A late, malformed or illegal answer is replaced by the Fallback strategy's
+// decision, and the program is restarted on the next decision, as it is when
+// it exits. Lines it writes between decisions are discarded. Decisions are
+// made one at a time.
+type ExecStrategy struct {
+	// Timeout bounds each decision, including the program's start-up on
+	// the first one. DefaultExecTimeout if zero.
+	Timeout time.Duration
+	// Fallback decides when the program doesn't. GreedyStrategy if nil.
+	Fallback engine.Strategy
+	// Stderr receives the program's standard error; discarded if nil.
+	Stderr io.Writer
+
+	path string
+	args []string
+
+	mu   sync.Mutex
+	proc *execProcess
+}
+
+// ExecRequest is the JSON object sent to the program for each decision.
+type ExecRequest struct {
+	Dice      [5]int            `json:"dice"`
+	RollCount int               `json:"roll_count"`
+	Scorecard engine.Scorecard  `json:"scorecard"`
+	Available []engine.Category `json:"available"`
+	Rules     string            `json:"rules"`
+	// TurnsLeft and Opponents are set when the game is known, as in
+	// battles.
+	TurnsLeft int            `json:"turns_left,omitempty"`
+	Opponents []ExecOpponent `json:"opponents,omitempty"`
+}
+
+// ExecOpponent is another player of the game in an ExecRequest.
+type ExecOpponent struct {
+	Name      string           `json:"name"`
+	Scorecard engine.Scorecard `json:"scorecard"`
+	TurnsLeft int              `json:"turns_left"`
+}
+
+// ExecResponse is the program's answer: {"type":"hold","indices":[0,1]}
+// rerolls all but the listed dice, and {"type":"score","category":"chance"}
+// scores.
+type ExecResponse struct {
+	Type     string          `json:"type"`
+	Indices  []int           `json:"indices,omitempty"`
+	Category engine.Category `json:"category,omitempty"`
+}
+
+// NewExecStrategy returns a strategy played by the program at path, run
+// with args.
+func NewExecStrategy(path string, args ...string) *ExecStrategy {
+	return &ExecStrategy{path: path, args: args}
+}
+
+func (s *ExecStrategy) Name() string {
+	return "exec:" + filepath.Base(s.path)
+}
+
+func (s *ExecStrategy) DecideAction(dice [5]int, rollCount int, scorecard engine.Scorecard, available []engine.Category) engine.TurnAction {
+	return s.DecideWithContext(engine.DecisionContext{
+		Dice:      dice,
+		RollCount: rollCount,
+		Scorecard: scorecard,
+		Available: available,
+	})
+}
+
+func (s *ExecStrategy) DecideWithContext(ctx engine.DecisionContext) engine.TurnAction {
+	req := ExecRequest{
+		Dice:      ctx.Dice,
+		RollCount: ctx.RollCount,
+		Scorecard: ctx.Scorecard,
+		Available: ctx.Available,
+		Rules:     ctx.Scorecard.Rules().Name(),
+		TurnsLeft: ctx.TurnsLeft,
+	}
+	for _, o := range ctx.Opponents {
+		req.Opponents = append(req.Opponents, ExecOpponent{Name: o.Name, Scorecard: o.Scorecard, TurnsLeft: o.TurnsLeft})
+	}
+
+	action, err := s.ask(req)
+	if err != nil {
+		fallback := s.Fallback
+		if fallback == nil {
+			fallback = &engine.GreedyStrategy{}
+		}
+		if s.Stderr != nil {
+			fmt.Fprintf(s.Stderr, "%s: %v; falling back to %s\n", s.Name(), err, fallback.Name())
+		}
+		return fallback.DecideAction(ctx.Dice, ctx.RollCount, ctx.Scorecard, ctx.Available)
+	}
+	return action
+}
+
+// Close stops the program, if it is running.
+func (s *ExecStrategy) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.proc == nil {
+		return nil
+	}
+	err := s.proc.stop()
+	s.proc = nil
+	return err
+}
+
+// ask sends req to the program and returns its validated answer.
+func (s *ExecStrategy) ask(req ExecRequest) (engine.TurnAction, error) {
+	data, err := json.Marshal(req)
+	if err != nil {
+		return engine.TurnAction{}, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.proc == nil {
+		if s.proc, err = startExecProcess(s.path, s.args, s.Stderr); err != nil {
+			return engine.TurnAction{}, err
+		}
+	}
+
+	timeout := s.Timeout
+	if timeout <= 0 {
+		timeout = DefaultExecTimeout
+	}
+	line, err := s.proc.exchange(append(data, '\n'), timeout)
+	var action engine.TurnAction
+	if err == nil {
+		action, err = parseExecResponse(line, req)
+	}
+	if err != nil {
+		// The program is in an unknown state: start afresh next time.
+		s.proc.stop()
+		s.proc = nil
+		return engine.TurnAction{}, err
+	}
+	return action, nil
+}
+
+// parseExecResponse decodes line and checks that it is legal in req.
+func parseExecResponse(line []byte, req ExecRequest) (engine.TurnAction, error) {
+	var resp ExecResponse
+	if err := json.Unmarshal(line, &resp); err != nil {
+		return engine.TurnAction{}, fmt.Errorf("invalid response %q: %w", line, err)
+	}
+
+	switch resp.Type {
+	case "hold":
+		if req.RollCount >= engine.MaxRolls {
+			return engine.TurnAction{}, errors.New("hold after the last roll")
+		}
+		held := 0
+		for _, i := range resp.Indices {
+			if i < 0 || i > 4 || held&(1<<i) != 0 {
+				return engine.TurnAction{}, fmt.Errorf("invalid hold indices %v", resp.Indices)
+			}
+			held |= 1 << i
+		}
+		return engine.TurnAction{Type: "hold", Indices: resp.Indices}, nil
+	case "score":
+		for _, c := range req.Available {
+			if c == resp.Category {
+				return engine.TurnAction{Type: "score", Category: c}, nil
+			}
+		}
+		return engine.TurnAction{}, fmt.Errorf("category %q is not available", resp.Category)
+	default:
+		return engine.TurnAction{}, fmt.Errorf("unknown action type %q", resp.Type)
+	}
+}
+
+// execProcess is a running strategy program.
+type execProcess struct {
+	cmd   *exec.Cmd
+	stdin io.WriteCloser
+	lines chan []byte // lines of stdout; closed when it ends
+}
+
+func startExecProcess(path string, args []string, stderr io.Writer) (*execProcess, error) {
+	cmd := exec.Command(path, args...)
+	cmd.Stderr = stderr
+	// Don't wait on children that inherited the pipes.
+	cmd.WaitDelay = time.Second
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("start strategy: %w", err)
+	}
+
+	p := &execProcess{cmd: cmd, stdin: stdin, lines: make(chan []byte, 1)}
+	go func() {
+		defer close(p.lines)
+		sc := bufio.NewScanner(stdout)
+		sc.Buffer(make([]byte, 64*1024), 1024*1024)
+		for sc.Scan() {
+			p.lines <- append([]byte(nil), sc.Bytes()...)
+		}
+	}()
+	return p, nil
+}
+
+// exchange writes request and waits up to timeout for a line in reply.
+// Lines written before the request are not a reply to it, and are dropped.
+func (p *execProcess) exchange(request []byte, timeout time.Duration) ([]byte, error) {
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	for pending := true; pending; {
+		select {
+		case _, ok := <-p.lines:
+			if !ok {
+				return nil, errors.New("strategy exited")
+			}
+		default:
+			pending = false
+		}
+	}
+
+	written := make(chan error, 1)
+	go func() {
+		_, err := p.stdin.Write(request)
+		written <- err
+	}()
+	select {
+	case err := <-written:
+		if err != nil {
+			return nil, fmt.Errorf("write to strategy: %w", err)
+		}
+	case <-timer.C:
+		return nil, fmt.Errorf("no answer within %v", timeout)
+	}
+
+	select {
+	case line, ok := <-p.lines:
+		if !ok {
+			return nil, errors.New("strategy exited")
+		}
+		return line, nil
+	case <-timer.C:
+		return nil, fmt.Errorf("no answer within %v", timeout)
+	}
+}
+
+// stop kills the program and waits for it.
+func (p *execProcess) stop() error {
+	p.cmd.Process.Kill()
+	err := p.cmd.Wait()
+	for range p.lines {
+	}
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return nil
+	}
+	return err
+}
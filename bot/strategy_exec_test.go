@@ -0,0 +1,163 @@
+package bot
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/edge2992/yatzcli/engine"
+)
+
+// The test binary doubles as the strategy program: with YATZ_TEST_BOT set
+// it plays as that kind of bot instead of running the tests.
+func TestMain(m *testing.M) {
+	if mode := os.Getenv("YATZ_TEST_BOT"); mode != "" {
+		runTestBot(mode)
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
+
+func runTestBot(mode string) {
+	in := bufio.NewScanner(os.Stdin)
+	for in.Scan() {
+		var req ExecRequest
+		if err := json.Unmarshal(in.Bytes(), &req); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(2)
+		}
+		switch mode {
+		case "play":
+			// Keep the first two dice, then score the last category.
+			resp := ExecResponse{Type: "hold", Indices: []int{0, 1}}
+			if req.RollCount >= engine.MaxRolls || len(req.Opponents) != 1 {
+				resp = ExecResponse{Type: "score", Category: req.Available[len(req.Available)-1]}
+			}
+			json.NewEncoder(os.Stdout).Encode(resp)
+		case "hold":
+			fmt.Println(`{"type":"hold","indices":[0,1,2,3,4]}`)
+		case "twice":
+			// Answer, then write a stray line that answers nothing.
+			fmt.Println(`{"type":"score","category":"chance"}`)
+			fmt.Println(`{"type":"score","category":"ones"}`)
+		case "garbage":
+			fmt.Println("roll them all!")
+		case "illegal":
+			fmt.Println(`{"type":"score","category":"sevens"}`)
+		case "slow":
+			time.Sleep(10 * time.Second)
+		case "crash-once":
+			// Exit on the first request, then play once restarted.
+			mark := os.Getenv("YATZ_TEST_BOT_MARK")
+			if _, err := os.Stat(mark); err != nil {
+				os.WriteFile(mark, nil, 0o600)
+				os.Exit(1)
+			}
+			fmt.Println(`{"type":"score","category":"chance"}`)
+		}
+	}
+}
+
+func newTestExecStrategy(t *testing.T, mode string) *ExecStrategy {
+	t.Setenv("YATZ_TEST_BOT", mode)
+	t.Setenv("YATZ_TEST_BOT_MARK", filepath.Join(t.TempDir(), "mark"))
+	s := NewExecStrategy(os.Args[0])
+	s.Timeout = 2 * time.Second
+	t.Cleanup(func() { s.Close() })
+	return s
+}
+
+func TestExecStrategy_Decides(t *testing.T) {
+	s := newTestExecStrategy(t, "play")
+	sc := engine.NewScorecard()
+	avail := sc.AvailableCategories()
+	assert.Equal(t, "exec:"+filepath.Base(os.Args[0]), s.Name())
+
+	// Without opponents the bot scores at once.
+	action := s.DecideAction([5]int{1, 2, 3, 4, 5}, 1, sc, avail)
+	assert.Equal(t, engine.TurnAction{Type: "score", Category: engine.Chance}, action)
+
+	action = s.DecideWithContext(engine.DecisionContext{
+		Dice:      [5]int{1, 2, 3, 4, 5},
+		RollCount: 2,
+		Scorecard: sc,
+		Available: avail,
+		TurnsLeft: 13,
+		Opponents: []engine.Opponent{{Name: "B", Scorecard: engine.NewScorecard(), TurnsLeft: 13}},
+	})
+	assert.Equal(t, engine.TurnAction{Type: "hold", Indices: []int{0, 1}}, action)
+}
+
+func TestExecStrategy_FallsBack(t *testing.T) {
+	sc := engine.NewScorecard()
+	avail := sc.AvailableCategories()
+	dice := [5]int{6, 6, 6, 6, 6}
+	greedy := (&engine.GreedyStrategy{}).DecideAction(dice, engine.MaxRolls, sc, avail)
+
+	for _, mode := range []string{"hold", "garbage", "illegal", "slow"} {
+		t.Run(mode, func(t *testing.T) {
+			s := newTestExecStrategy(t, mode)
+			s.Timeout = 500 * time.Millisecond
+			start := time.Now()
+			assert.Equal(t, greedy, s.DecideAction(dice, engine.MaxRolls, sc, avail))
+			assert.Less(t, time.Since(start), 5*time.Second)
+		})
+	}
+}
+
+func TestExecStrategy_IgnoresStrayLines(t *testing.T) {
+	s := newTestExecStrategy(t, "twice")
+	sc := engine.NewScorecard()
+	avail := sc.AvailableCategories()
+	dice := [5]int{1, 2, 3, 4, 6}
+
+	for range 3 {
+		assert.Equal(t, engine.TurnAction{Type: "score", Category: engine.Chance}, s.DecideAction(dice, engine.MaxRolls, sc, avail))
+		// Let the stray line arrive before the next request.
+		time.Sleep(50 * time.Millisecond)
+	}
+}
+
+func TestExecStrategy_RestartsAfterInvalidAnswer(t *testing.T) {
+	s := newTestExecStrategy(t, "garbage")
+	sc := engine.NewScorecard()
+	s.DecideAction([5]int{1, 2, 3, 4, 6}, engine.MaxRolls, sc, sc.AvailableCategories())
+	assert.Nil(t, s.proc)
+}
+
+func TestExecStrategy_RestartsAfterExit(t *testing.T) {
+	s := newTestExecStrategy(t, "crash-once")
+	sc := engine.NewScorecard()
+	avail := sc.AvailableCategories()
+	dice := [5]int{1, 1, 1, 1, 2}
+
+	assert.Equal(t, engine.TurnAction{Type: "score", Category: engine.ThreeOfAKind}, s.DecideAction(dice, engine.MaxRolls, sc, avail))
+	assert.Equal(t, engine.TurnAction{Type: "score", Category: engine.Chance}, s.DecideAction(dice, engine.MaxRolls, sc, avail))
+}
+
+func TestExecStrategy_Battle(t *testing.T) {
+	s := newTestExecStrategy(t, "play")
+	state, err := engine.RunBattle(engine.BattleConfig{
+		Players: []engine.BattlePlayer{
+			{Name: "Exec", Strategy: s},
+			{Name: "Greedy", Strategy: &engine.GreedyStrategy{}},
+		},
+		Seed: 1,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, engine.PhaseFinished, state.Phase)
+}
+
+func TestExecStrategy_MissingProgram(t *testing.T) {
+	s := NewExecStrategy(filepath.Join(t.TempDir(), "no-such-bot"))
+	sc := engine.NewScorecard()
+	action := s.DecideAction([5]int{2, 2, 3, 3, 3}, 1, sc, sc.AvailableCategories())
+	assert.Equal(t, engine.TurnAction{Type: "score", Category: engine.FullHouse}, action)
+}
@@ -150,18 +150,20 @@ func (s *LLMStrategy) buildUserPrompt(dice [5]int, rollCount int, scorecard engi
 
 	b.WriteString("利用可能カテゴリ:\n")
 	for _, c := range available {
-		score := engine.CalcScore(c, dice)
+		score := engine.ScoreFor(c, dice, scorecard)
 		b.WriteString(fmt.Sprintf("  %s: %d点\n", string(c), score))
 	}
 
 	b.WriteString("記入済みカテゴリ:\n")
-	for _, c := range engine.AllCategories {
+	for _, c := range scorecard.Rules().Categories() {
 		if scorecard.IsFilled(c) {
 			b.WriteString(fmt.Sprintf("  %s: %d点\n", string(c), scorecard.GetScore(c)))
 		}
 	}
 
-	b.WriteString(fmt.Sprintf("上段合計: %d/63\n", scorecard.UpperTotal()))
+	if threshold, value := scorecard.Rules().UpperBonus(); value > 0 {
+		b.WriteString(fmt.Sprintf("上段合計: %d/%d\n", scorecard.UpperTotal(), threshold))
+	}
 
 	if rollCount >= engine.MaxRolls {
 		b.WriteString("\n3回目のロール済みです。必ずscoreを選択してください。\n")
@@ -0,0 +1,90 @@
+package cli
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/edge2992/yatzcli/engine"
+)
+
+// hintOptions is the number of top-valued options listed in a hint.
+const hintOptions = 3
+
+// hint is a coach recommendation for one game state.
+type hint struct {
+	state       *engine.GameState // state the hint was computed for
+	strategy    string
+	recommended string
+	options     []engine.ActionValue
+}
+
+// toggleHint shows a hint for the current state, or hides a visible one.
+func (m model) toggleHint() model {
+	if m.coach == nil || m.lastState == nil {
+		return m
+	}
+	if m.hint != nil && m.hint.state == m.lastState {
+		m.hint = nil
+		return m
+	}
+	m.hint = computeHint(m.coach, m.lastState)
+	return m
+}
+
+func computeHint(strategy engine.Strategy, gs *engine.GameState) *hint {
+	h := &hint{state: gs, strategy: strategy.Name()}
+	if gs.RollCount == 0 {
+		h.recommended = "Roll the dice"
+		return h
+	}
+
+	sc := gs.Players[gs.CurrentPlayerIndex].Scorecard
+	action := strategy.DecideAction(gs.Dice, gs.RollCount, sc, gs.AvailableCategories)
+	h.recommended = describeHintAction(action, gs.Dice)
+
+	h.options = engine.EvaluatorFor(strategy).Evaluate(gs.Dice, gs.RollCount, sc)
+	sort.SliceStable(h.options, func(i, j int) bool { return h.options[i].Value > h.options[j].Value })
+	if len(h.options) > hintOptions {
+		h.options = h.options[:hintOptions]
+	}
+	return h
+}
+
+func describeHintAction(a engine.TurnAction, dice [5]int) string {
+	if a.Type == "score" {
+		return "Score " + categoryName(a.Category)
+	}
+	if len(a.Indices) == 0 {
+		return "Reroll all dice"
+	}
+	keys := make([]string, len(a.Indices))
+	values := make([]string, len(a.Indices))
+	for i, idx := range a.Indices {
+		keys[i] = fmt.Sprint(idx + 1)
+		values[i] = fmt.Sprint(dice[idx])
+	}
+	return fmt.Sprintf("Hold %s (dice %s) and reroll", strings.Join(values, " "), strings.Join(keys, ","))
+}
+
+func (m model) viewHint(b *strings.Builder) {
+	h := m.hint
+	if h == nil || h.state != m.lastState {
+		return
+	}
+	b.WriteString(fmt.Sprintf("  Hint (%s): %s\n", h.strategy, h.recommended))
+	if len(h.options) > 0 {
+		b.WriteString("  Top options (expected value):\n")
+		for _, o := range h.options {
+			b.WriteString(fmt.Sprintf("    %-40s %7.2f\n", describeHintAction(o.Action, m.lastState.Dice), o.Value))
+		}
+	}
+	b.WriteString("\n")
+}
+
+func (m model) hintKeyHelp() string {
+	if m.coach == nil {
+		return ""
+	}
+	return "  [?] Hint"
+}
@@ -20,10 +20,12 @@ type chatMsg ChatEntry
 
 type aiTickMsg struct{}
 
+type clockTickMsg struct{}
+
 type uiState int
 
 const (
-	stateRolling  uiState = iota
+	stateRolling uiState = iota
 	stateChoosing
 	stateWaiting
 	stateShowingAI
@@ -54,6 +56,10 @@ type model struct {
 	stateUpdateCh  <-chan *engine.GameState
 	aiResults      []engine.AITurnResult
 	aiResultIndex  int
+	coach          engine.Strategy
+	hint           *hint
+	turnDeadline   func() time.Time
+	connectionInfo string
 }
 
 func newModel(client engine.GameClient, playerName string) model {
@@ -68,12 +74,22 @@ func newModel(client engine.GameClient, playerName string) model {
 			}
 		}
 	}
-	return model{
+	m := model{
 		client:     client,
 		playerName: playerName,
 		playerID:   playerID,
 		lastState:  s,
 	}
+	// A restored game may start mid-turn or already be over.
+	if s != nil {
+		switch s.Phase {
+		case engine.PhaseChoosing:
+			m.state = stateChoosing
+		case engine.PhaseFinished:
+			m.state = stateGameOver
+		}
+	}
+	return m
 }
 
 func listenForChat(chatCh <-chan ChatEntry) tea.Cmd {
@@ -104,9 +120,36 @@ func (m model) Init() tea.Cmd {
 	if m.stateUpdateCh != nil {
 		cmds = append(cmds, listenForStateUpdate(m.stateUpdateCh))
 	}
+	if m.turnDeadline != nil {
+		cmds = append(cmds, clockTickCmd())
+	}
 	return tea.Batch(cmds...)
 }
 
+// turnIndex orders turns over the whole game.
+func turnIndex(gs *engine.GameState) int {
+	return (gs.Round-1)*len(gs.Players) + gs.CurrentPlayerIndex
+}
+
+func clockTickCmd() tea.Cmd {
+	return tea.Tick(time.Second, func(t time.Time) tea.Msg {
+		return clockTickMsg{}
+	})
+}
+
+// clockLabel returns the time left in a timed turn, or "" if untimed.
+func (m model) clockLabel() string {
+	if m.turnDeadline == nil {
+		return ""
+	}
+	deadline := m.turnDeadline()
+	if deadline.IsZero() {
+		return ""
+	}
+	left := max(time.Until(deadline).Round(time.Second), 0)
+	return fmt.Sprintf("  |  ⏱ %s", left)
+}
+
 func aiTickCmd() tea.Cmd {
 	return tea.Tick(time.Second, func(t time.Time) tea.Msg {
 		return aiTickMsg{}
@@ -156,10 +199,20 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 		return m, nil
 	case stateUpdateMsg:
+		ourTurn := m.state == stateRolling || m.state == stateChoosing
+		if ourTurn && turnIndex(msg.state) < turnIndex(m.lastState) {
+			// An opponent's move queued before our turn started.
+			return m, listenForStateUpdate(m.stateUpdateCh)
+		}
 		m.lastState = msg.state
 		if msg.state.Phase == engine.PhaseFinished {
 			m.state = stateGameOver
 			m.opponentStatus = ""
+		} else if ourTurn && msg.state.CurrentPlayer != m.playerID {
+			// Our clock ran out and the server played the turn for us
+			m.state = stateWaiting
+			m.hint = nil
+			m.err = "time's up: your turn was played for you"
 		} else if m.state == stateWaiting && msg.state.CurrentPlayer == m.playerID {
 			// Our turn now
 			m.state = stateRolling
@@ -189,6 +242,8 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.lastState = msg.state
 		m.held = [5]bool{}
 		return m.enterAIShowOrNext()
+	case clockTickMsg:
+		return m, clockTickCmd()
 	case aiTickMsg:
 		if m.state == stateShowingAI {
 			return m.advanceAIResult()
@@ -253,6 +308,8 @@ func (m model) updateRolling(msg tea.KeyPressMsg) (tea.Model, tea.Cmd) {
 			m.cursor = 0
 		}
 		return m, nil
+	case "?":
+		return m.toggleHint(), nil
 	}
 	return m, nil
 }
@@ -288,6 +345,8 @@ func (m model) updateChoosing(msg tea.KeyPressMsg) (tea.Model, tea.Cmd) {
 			gs, err := client.Score(cat)
 			return scoreResultMsg{state: gs, err: err}
 		}
+	case "?":
+		return m.toggleHint(), nil
 	}
 	return m, nil
 }
@@ -333,37 +392,40 @@ func (m model) View() tea.View {
 
 func (m model) viewRolling(b *strings.Builder) {
 	gs := m.lastState
-	b.WriteString(fmt.Sprintf("  Round %d/13  |  Player: %s  |  Rolls: %d/%d\n\n",
-		gs.Round, m.currentPlayerName(), gs.RollCount, engine.MaxRolls))
+	b.WriteString(fmt.Sprintf("  Round %d/%d  |  Player: %s  |  Rolls: %d/%d%s\n\n",
+		gs.Round, maxRounds(gs), m.currentPlayerName(), gs.RollCount, engine.MaxRolls, m.clockLabel()))
 
 	m.viewDice(b)
 	b.WriteString("\n")
 	m.viewScorecard(b)
 	b.WriteString("\n")
 
+	m.viewHint(b)
+
 	if gs.RollCount == 0 {
-		b.WriteString("  [r] Roll dice  [q] Quit\n")
+		b.WriteString("  [r] Roll dice" + m.hintKeyHelp() + "  [q] Quit\n")
 	} else {
-		b.WriteString("  [r] Reroll  [1-5] Toggle hold  [s] Score  [q] Quit\n")
+		b.WriteString("  [r] Reroll  [1-5] Toggle hold  [s] Score" + m.hintKeyHelp() + "  [q] Quit\n")
 	}
 }
 
 func (m model) viewChoosing(b *strings.Builder) {
 	gs := m.lastState
-	b.WriteString(fmt.Sprintf("  Round %d/13  |  Player: %s  |  Choose a category\n\n",
-		gs.Round, m.currentPlayerName()))
+	b.WriteString(fmt.Sprintf("  Round %d/%d  |  Player: %s  |  Choose a category%s\n\n",
+		gs.Round, maxRounds(gs), m.currentPlayerName(), m.clockLabel()))
 
 	m.viewDice(b)
 	b.WriteString("\n")
 
 	avail := gs.AvailableCategories
+	sc := gs.Players[gs.CurrentPlayerIndex].Scorecard
 	b.WriteString("  Available categories:\n\n")
 	for i, cat := range avail {
 		cursor := "  "
 		if i == m.cursor {
 			cursor = "> "
 		}
-		score := engine.CalcScore(cat, gs.Dice)
+		score := engine.ScoreFor(cat, gs.Dice, sc)
 		b.WriteString(fmt.Sprintf("  %s%-16s  %3d pts\n", cursor, categoryName(cat), score))
 	}
 	b.WriteString("\n")
@@ -371,10 +433,12 @@ func (m model) viewChoosing(b *strings.Builder) {
 	m.viewScorecard(b)
 	b.WriteString("\n")
 
+	m.viewHint(b)
+
 	if gs.Phase == engine.PhaseRolling {
-		b.WriteString("  [j/k] Move  [enter] Select  [esc] Back  [q] Quit\n")
+		b.WriteString("  [j/k] Move  [enter] Select  [esc] Back" + m.hintKeyHelp() + "  [q] Quit\n")
 	} else {
-		b.WriteString("  [j/k] Move  [enter] Select  [q] Quit\n")
+		b.WriteString("  [j/k] Move  [enter] Select" + m.hintKeyHelp() + "  [q] Quit\n")
 	}
 }
 
@@ -387,7 +451,7 @@ func (m model) viewWaiting(b *strings.Builder) {
 			opponent = p.Name
 		}
 	}
-	b.WriteString(fmt.Sprintf("  Round %d/13  |  %s のターンを待っています...\n\n", gs.Round, opponent))
+	b.WriteString(fmt.Sprintf("  Round %d/%d  |  %s のターンを待っています...\n\n", gs.Round, maxRounds(gs), opponent))
 	if m.opponentStatus != "" {
 		b.WriteString(fmt.Sprintf("  ▶ %s\n\n", m.opponentStatus))
 	}
@@ -395,6 +459,9 @@ func (m model) viewWaiting(b *strings.Builder) {
 	b.WriteString("\n")
 	m.viewScorecard(b)
 	b.WriteString("\n")
+	if m.connectionInfo != "" {
+		b.WriteString(fmt.Sprintf("  Connected: %s\n", m.connectionInfo))
+	}
 	b.WriteString("  [q] Quit\n")
 }
 
@@ -476,6 +543,7 @@ func (m model) viewDice(b *strings.Builder) {
 func (m model) viewScorecard(b *strings.Builder) {
 	gs := m.lastState
 	players := gs.Players
+	rules := rulesOf(gs)
 
 	nameWidth := 16
 	b.WriteString(fmt.Sprintf("  %-*s", nameWidth, "Category"))
@@ -485,7 +553,7 @@ func (m model) viewScorecard(b *strings.Builder) {
 	b.WriteString("\n")
 	b.WriteString("  " + strings.Repeat("-", nameWidth+10*len(players)) + "\n")
 
-	for _, cat := range engine.AllCategories {
+	for _, cat := range rules.Categories() {
 		b.WriteString(fmt.Sprintf("  %-*s", nameWidth, categoryName(cat)))
 		for _, p := range players {
 			if p.Scorecard.IsFilled(cat) {
@@ -498,16 +566,26 @@ func (m model) viewScorecard(b *strings.Builder) {
 	}
 
 	b.WriteString("  " + strings.Repeat("-", nameWidth+10*len(players)) + "\n")
-	b.WriteString(fmt.Sprintf("  %-*s", nameWidth, "Upper Bonus"))
-	for _, p := range players {
-		if p.Scorecard.HasUpperBonus() {
-			b.WriteString(fmt.Sprintf("  %8d", engine.UpperBonusValue))
-		} else {
-			ut := p.Scorecard.UpperTotal()
-			b.WriteString(fmt.Sprintf("  %5d/%d", ut, engine.UpperBonusThreshold))
+	if threshold, value := rules.UpperBonus(); value > 0 {
+		b.WriteString(fmt.Sprintf("  %-*s", nameWidth, "Upper Bonus"))
+		for _, p := range players {
+			if p.Scorecard.HasUpperBonus() {
+				b.WriteString(fmt.Sprintf("  %8d", value))
+			} else {
+				ut := p.Scorecard.UpperTotal()
+				b.WriteString(fmt.Sprintf("  %5d/%d", ut, threshold))
+			}
 		}
+		b.WriteString("\n")
+	}
+
+	if rules.YahtzeeBonus() > 0 {
+		b.WriteString(fmt.Sprintf("  %-*s", nameWidth, "Yahtzee Bonus"))
+		for _, p := range players {
+			b.WriteString(fmt.Sprintf("  %8d", p.Scorecard.YahtzeeBonus()))
+		}
+		b.WriteString("\n")
 	}
-	b.WriteString("\n")
 
 	b.WriteString(fmt.Sprintf("  %-*s", nameWidth, "TOTAL"))
 	for _, p := range players {
@@ -540,6 +618,17 @@ func categoryName(c engine.Category) string {
 		engine.LargeStraight: "Large Straight",
 		engine.Yahtzee:       "Yahtzee",
 		engine.Chance:        "Chance",
+
+		engine.LittleStraight: "Little Straight",
+		engine.BigStraight:    "Big Straight",
+		engine.Choice:         "Choice",
+		engine.Yacht:          "Yacht",
+
+		engine.Escalera:       "Escalera",
+		engine.Full:           "Full",
+		engine.Poker:          "Poker",
+		engine.Generala:       "Generala",
+		engine.DoubleGenerala: "Double Generala",
 	}
 	if name, ok := names[c]; ok {
 		return name
@@ -547,6 +636,24 @@ func categoryName(c engine.Category) string {
 	return string(c)
 }
 
+// rulesOf returns the rule set of the game described by gs. Scorecards carry
+// their rule set, so this works for states received over the network too.
+func rulesOf(gs *engine.GameState) engine.RuleSet {
+	if len(gs.Players) == 0 {
+		return engine.DefaultRules()
+	}
+	return gs.Players[0].Scorecard.Rules()
+}
+
+// maxRounds returns the number of rounds in the game described by gs,
+// falling back to the classic count for states from older peers.
+func maxRounds(gs *engine.GameState) int {
+	if gs.MaxRounds == 0 {
+		return engine.MaxRounds
+	}
+	return gs.MaxRounds
+}
+
 func formatDiceCompact(dice [5]int) string {
 	return fmt.Sprintf("%d %d %d %d %d", dice[0], dice[1], dice[2], dice[3], dice[4])
 }
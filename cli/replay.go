@@ -0,0 +1,141 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+
+	tea "charm.land/bubbletea/v2"
+
+	"github.com/edge2992/yatzcli/engine"
+)
+
+type replayModel struct {
+	events []engine.Event
+	states []engine.GameState
+	index  int
+}
+
+// RunReplay launches a TUI stepping through a recorded game. states[i] is the
+// game state after events[i], as returned by engine.Replay.
+func RunReplay(events []engine.Event, states []engine.GameState) error {
+	if len(states) == 0 || len(states) > len(events) {
+		return fmt.Errorf("replay needs one state per event (got %d events, %d states)", len(events), len(states))
+	}
+	p := tea.NewProgram(replayModel{events: events, states: states})
+	_, err := p.Run()
+	return err
+}
+
+func (m replayModel) Init() tea.Cmd {
+	return nil
+}
+
+func (m replayModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	key, ok := msg.(tea.KeyPressMsg)
+	if !ok {
+		return m, nil
+	}
+	last := len(m.states) - 1
+	switch key.String() {
+	case "q", "ctrl+c":
+		return m, tea.Quit
+	case "right", "l", "n", "space":
+		m.index = min(m.index+1, last)
+	case "left", "h", "p":
+		m.index = max(m.index-1, 0)
+	case "down", "j":
+		// Jump to the next turn.
+		m.index = min(m.nextOf(engine.EventTurnAdvance, engine.EventGameOver), last)
+	case "up", "k":
+		m.index = m.prevOf(engine.EventTurnAdvance, engine.EventGameStart)
+	case "home", "g":
+		m.index = 0
+	case "end", "G":
+		m.index = last
+	}
+	return m, nil
+}
+
+func (m replayModel) nextOf(types ...engine.EventType) int {
+	for i := m.index + 1; i < len(m.states); i++ {
+		for _, t := range types {
+			if m.events[i].Type == t {
+				return i
+			}
+		}
+	}
+	return len(m.states) - 1
+}
+
+func (m replayModel) prevOf(types ...engine.EventType) int {
+	for i := m.index - 1; i > 0; i-- {
+		for _, t := range types {
+			if m.events[i].Type == t {
+				return i
+			}
+		}
+	}
+	return 0
+}
+
+func (m replayModel) View() tea.View {
+	var b strings.Builder
+	gs := m.states[m.index]
+	e := m.events[m.index]
+
+	b.WriteString("  === Replay ===\n\n")
+	b.WriteString(fmt.Sprintf("  Event %d/%d  |  Round %d/%d\n\n",
+		m.index+1, len(m.states), min(gs.Round, maxRounds(&gs)), maxRounds(&gs)))
+	b.WriteString("  " + describeEvent(e, &gs) + "\n\n")
+
+	view := model{lastState: &gs}
+	for _, i := range e.Held {
+		if i >= 0 && i < len(view.held) {
+			view.held[i] = true
+		}
+	}
+	view.viewDice(&b)
+	b.WriteString("\n")
+	view.viewScorecard(&b)
+	b.WriteString("\n")
+	b.WriteString("  [←/→] Step  [↑/↓] Turn  [g/G] Start/End  [q] Quit\n")
+	return tea.NewView(b.String())
+}
+
+func describeEvent(e engine.Event, gs *engine.GameState) string {
+	name := playerName(gs, e.Player)
+	switch e.Type {
+	case engine.EventGameStart:
+		return fmt.Sprintf("Game start (%s rules)", gs.Rules)
+	case engine.EventRoll:
+		return fmt.Sprintf("%s rolled %s", name, formatDiceCompact(e.Dice))
+	case engine.EventHold:
+		return fmt.Sprintf("%s held %d and rerolled: %s", name, len(e.Held), formatDiceCompact(e.Dice))
+	case engine.EventScore:
+		s := fmt.Sprintf("%s scored %d in %s", name, e.Score, categoryName(e.Category))
+		if e.YahtzeeBonus > 0 {
+			s += fmt.Sprintf(" (+%d Yahtzee bonus)", e.YahtzeeBonus)
+		}
+		return s
+	case engine.EventTurnAdvance:
+		return fmt.Sprintf("%s's turn", name)
+	case engine.EventGameOver:
+		winner := gs.Players[0]
+		for _, p := range gs.Players[1:] {
+			if p.Scorecard.Total() > winner.Scorecard.Total() {
+				winner = p
+			}
+		}
+		return fmt.Sprintf("Game over: %s wins with %d points", winner.Name, winner.Scorecard.Total())
+	}
+	return string(e.Type)
+}
+
+func playerName(gs *engine.GameState, id string) string {
+	for _, p := range gs.Players {
+		if p.ID == id {
+			return p.Name
+		}
+	}
+	return id
+}
@@ -25,6 +25,7 @@ type spectatorModel struct {
 	results    <-chan engine.AITurnResult
 	errCh      <-chan error
 	players    []engine.BattlePlayer
+	rules      engine.RuleSet
 	speed      time.Duration
 	state      spectatorState
 	current    *engine.AITurnResult
@@ -35,18 +36,24 @@ type spectatorModel struct {
 }
 
 // RunSpectator launches the spectator TUI for watching AI battles.
+// A nil rules uses engine.DefaultRules.
 func RunSpectator(
 	results <-chan engine.AITurnResult,
 	errCh <-chan error,
 	players []engine.BattlePlayer,
+	rules engine.RuleSet,
 	speed time.Duration,
 ) error {
+	if rules == nil {
+		rules = engine.DefaultRules()
+	}
 	m := spectatorModel{
 		results:    results,
 		errCh:      errCh,
 		players:    players,
+		rules:      rules,
 		speed:      speed,
-		totalTurns: 13 * len(players),
+		totalTurns: len(rules.Categories()) * len(players),
 	}
 	p := tea.NewProgram(m)
 	_, err := p.Run()
@@ -211,21 +218,28 @@ func (m spectatorModel) buildScorecards() (map[string]*engine.Scorecard, []strin
 	scorecards := make(map[string]*engine.Scorecard)
 	names := make([]string, len(m.players))
 	for i, p := range m.players {
-		sc := engine.NewScorecard()
+		sc := engine.NewScorecardWithRules(m.rules)
 		scorecards[p.Name] = &sc
 		names[i] = p.Name
 	}
 	for _, r := range m.history {
 		if sc, ok := scorecards[r.PlayerName]; ok {
 			sc.Fill(r.Category, r.Score)
+			if r.YahtzeeBonus > 0 {
+				sc.AddYahtzeeBonus()
+			}
 		}
 	}
 	return scorecards, names
 }
 
 // writeScorecard writes a scorecard table to the builder.
-// If showBonus is true, the upper bonus row is included.
+// If showBonus is true, the upper and Yahtzee bonus rows are included.
 func writeScorecard(b *strings.Builder, scorecards map[string]*engine.Scorecard, names []string, showBonus bool) {
+	if len(names) == 0 {
+		return
+	}
+	rules := scorecards[names[0]].Rules()
 	nameWidth := 16
 	b.WriteString(fmt.Sprintf("  %-*s", nameWidth, "Category"))
 	for _, name := range names {
@@ -234,7 +248,7 @@ func writeScorecard(b *strings.Builder, scorecards map[string]*engine.Scorecard,
 	b.WriteString("\n")
 	b.WriteString("  " + strings.Repeat("-", nameWidth+10*len(names)) + "\n")
 
-	for _, cat := range engine.AllCategories {
+	for _, cat := range rules.Categories() {
 		b.WriteString(fmt.Sprintf("  %-*s", nameWidth, categoryName(cat)))
 		for _, name := range names {
 			sc := scorecards[name]
@@ -249,19 +263,28 @@ func writeScorecard(b *strings.Builder, scorecards map[string]*engine.Scorecard,
 
 	b.WriteString("  " + strings.Repeat("-", nameWidth+10*len(names)) + "\n")
 
-	if showBonus {
+	threshold, value := rules.UpperBonus()
+	if showBonus && value > 0 {
 		b.WriteString(fmt.Sprintf("  %-*s", nameWidth, "Upper Bonus"))
 		for _, name := range names {
 			sc := scorecards[name]
 			if sc.HasUpperBonus() {
-				b.WriteString(fmt.Sprintf("  %8d", engine.UpperBonusValue))
+				b.WriteString(fmt.Sprintf("  %8d", value))
 			} else {
-				b.WriteString(fmt.Sprintf("  %5d/%d", sc.UpperTotal(), engine.UpperBonusThreshold))
+				b.WriteString(fmt.Sprintf("  %5d/%d", sc.UpperTotal(), threshold))
 			}
 		}
 		b.WriteString("\n")
 	}
 
+	if showBonus && rules.YahtzeeBonus() > 0 {
+		b.WriteString(fmt.Sprintf("  %-*s", nameWidth, "Yahtzee Bonus"))
+		for _, name := range names {
+			b.WriteString(fmt.Sprintf("  %8d", scorecards[name].YahtzeeBonus()))
+		}
+		b.WriteString("\n")
+	}
+
 	b.WriteString(fmt.Sprintf("  %-*s", nameWidth, "TOTAL"))
 	for _, name := range names {
 		sc := scorecards[name]
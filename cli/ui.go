@@ -2,6 +2,7 @@ package cli
 
 import (
 	"fmt"
+	"time"
 
 	tea "charm.land/bubbletea/v2"
 
@@ -22,6 +23,30 @@ func WithStateUpdateChannel(ch <-chan *engine.GameState) GameOption {
 	}
 }
 
+// WithCoach enables the hint key, which recommends moves from strategy.
+func WithCoach(strategy engine.Strategy) GameOption {
+	return func(m *model) {
+		m.coach = strategy
+	}
+}
+
+// WithTurnDeadline shows a countdown during the player's turns. deadline
+// returns when the turn will be played for them, or the zero time if the
+// turn is untimed.
+func WithTurnDeadline(deadline func() time.Time) GameOption {
+	return func(m *model) {
+		m.turnDeadline = deadline
+	}
+}
+
+// WithConnectionInfo shows info, e.g. the protocol features negotiated with
+// a server, while waiting for other players.
+func WithConnectionInfo(info string) GameOption {
+	return func(m *model) {
+		m.connectionInfo = info
+	}
+}
+
 func WithInitialWaiting() GameOption {
 	return func(m *model) {
 		m.state = stateWaiting
@@ -0,0 +1,197 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+
+	tea "charm.land/bubbletea/v2"
+
+	"github.com/edge2992/yatzcli/engine"
+)
+
+type watchStateMsg struct{ state *engine.GameState }
+type watchDoneMsg struct{ err error }
+
+// watchModel renders a networked game for a spectator. It follows the
+// spectator view used for AI battles, but is driven by the state stream:
+// every update replaces the shown state and scored categories are worked
+// out by comparing scorecards.
+type watchModel struct {
+	states <-chan *engine.GameState
+	chatCh <-chan ChatEntry
+	done   <-chan error
+
+	state  *engine.GameState
+	scored string // last scoring move, e.g. "Alice scored Chance for 23 pts"
+	chat   []ChatEntry
+	over   bool
+	err    error
+	lost   bool // the stream ended before the game did
+}
+
+// RunWatch launches the spectator TUI for a game streamed over the network.
+// states delivers every state update and is closed when the connection ends;
+// done then reports why. chat may be nil.
+func RunWatch(states <-chan *engine.GameState, chat <-chan ChatEntry, done <-chan error) error {
+	m := watchModel{states: states, chatCh: chat, done: done}
+	p := tea.NewProgram(m)
+	_, err := p.Run()
+	return err
+}
+
+func (m watchModel) Init() tea.Cmd {
+	cmds := []tea.Cmd{waitForState(m.states, m.done)}
+	if m.chatCh != nil {
+		cmds = append(cmds, listenForChat(m.chatCh))
+	}
+	return tea.Batch(cmds...)
+}
+
+func waitForState(states <-chan *engine.GameState, done <-chan error) tea.Cmd {
+	return func() tea.Msg {
+		gs, ok := <-states
+		if !ok {
+			return watchDoneMsg{err: <-done}
+		}
+		return watchStateMsg{state: gs}
+	}
+}
+
+func (m watchModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case watchStateMsg:
+		if m.state != nil {
+			if s := scoredMove(m.state, msg.state); s != "" {
+				m.scored = s
+			}
+		}
+		m.state = msg.state
+		if m.state.Phase == engine.PhaseFinished {
+			m.over = true
+		}
+		return m, waitForState(m.states, m.done)
+
+	case watchDoneMsg:
+		m.err = msg.err
+		m.lost = !m.over
+		return m, nil
+
+	case chatMsg:
+		m.chat = append(m.chat, ChatEntry(msg))
+		return m, listenForChat(m.chatCh)
+
+	case tea.KeyPressMsg:
+		switch msg.String() {
+		case "q", "ctrl+c":
+			return m, tea.Quit
+		}
+	}
+	return m, nil
+}
+
+func (m watchModel) View() tea.View {
+	var b strings.Builder
+
+	if m.over {
+		m.viewGameOver(&b)
+	} else {
+		m.viewWatching(&b)
+	}
+
+	if len(m.chat) > 0 {
+		b.WriteString("\n  ─── Chat ────────────────────────\n")
+		for _, c := range m.chat {
+			b.WriteString(fmt.Sprintf("  %s: %s\n", c.Name, c.Text))
+		}
+	}
+
+	if m.err != nil {
+		b.WriteString(fmt.Sprintf("\n  Error: %v\n", m.err))
+	} else if m.lost {
+		b.WriteString("\n  Connection closed.\n")
+	}
+
+	return tea.NewView(b.String())
+}
+
+func (m watchModel) viewWatching(b *strings.Builder) {
+	b.WriteString("  === Watching ===\n\n")
+
+	gs := m.state
+	if gs == nil {
+		b.WriteString("  Waiting for the game to start...\n")
+		b.WriteString("\n  [q] Quit\n")
+		return
+	}
+
+	b.WriteString(fmt.Sprintf("  Round %d/%d  |  %s  |  Roll %d/3\n\n",
+		gs.Round, gs.MaxRounds, playerName(gs, gs.CurrentPlayer), gs.RollCount))
+
+	b.WriteString("  Dice: ")
+	for i, d := range gs.Dice {
+		if gs.RollCount == 0 {
+			b.WriteString("[ - ]")
+		} else {
+			b.WriteString(fmt.Sprintf("[ %d ]", d))
+		}
+		if i < 4 {
+			b.WriteString(" ")
+		}
+	}
+	b.WriteString("\n\n")
+
+	if m.scored != "" {
+		b.WriteString(fmt.Sprintf("  Last: %s\n\n", m.scored))
+	}
+
+	scorecards, names := stateScorecards(gs)
+	writeScorecard(b, scorecards, names, false)
+
+	b.WriteString("\n  [q] Quit\n")
+}
+
+func (m watchModel) viewGameOver(b *strings.Builder) {
+	b.WriteString("  ===  GAME OVER  ===\n\n")
+
+	scorecards, names := stateScorecards(m.state)
+	writeScorecard(b, scorecards, names, true)
+
+	winner := m.state.Players[0]
+	for _, p := range m.state.Players[1:] {
+		if p.Scorecard.Total() > winner.Scorecard.Total() {
+			winner = p
+		}
+	}
+	b.WriteString(fmt.Sprintf("\n  Winner: %s with %d points!\n\n", winner.Name, winner.Scorecard.Total()))
+	b.WriteString("  [q] Quit\n")
+}
+
+// stateScorecards returns the players' scorecards keyed by name, in seat
+// order, for writeScorecard.
+func stateScorecards(gs *engine.GameState) (map[string]*engine.Scorecard, []string) {
+	scorecards := make(map[string]*engine.Scorecard, len(gs.Players))
+	names := make([]string, len(gs.Players))
+	for i := range gs.Players {
+		p := &gs.Players[i]
+		scorecards[p.Name] = &p.Scorecard
+		names[i] = p.Name
+	}
+	return scorecards, names
+}
+
+// scoredMove describes the category filled between prev and next, or
+// returns "" if nothing was scored.
+func scoredMove(prev, next *engine.GameState) string {
+	for i, p := range next.Players {
+		if i >= len(prev.Players) {
+			break
+		}
+		before := prev.Players[i].Scorecard
+		for _, cat := range before.Rules().Categories() {
+			if !before.IsFilled(cat) && p.Scorecard.IsFilled(cat) {
+				return fmt.Sprintf("%s scored %s for %d pts", p.Name, categoryName(cat), p.Scorecard.GetScore(cat))
+			}
+		}
+	}
+	return ""
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/edge2992/yatzcli/engine"
+)
+
+var analyzeCmd = &cobra.Command{
+	Use:   "analyze <replay>",
+	Short: "Grade every decision in a recorded game",
+	Long: `Grade every hold and score decision in a game event log by its expected-value
+loss against the best alternative. Uses the optimal strategy table when it is
+available (see "yatz precompute"), and the statistical evaluator otherwise.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runAnalyze,
+}
+
+func init() {
+	analyzeCmd.Flags().String("table", "", "Optimal strategy table (default: precomputed table if present)")
+	analyzeCmd.Flags().Bool("statistical", false, "Use the statistical evaluator instead of the optimal table")
+	analyzeCmd.Flags().Int("top", 5, "Number of worst mistakes to list")
+}
+
+func runAnalyze(cmd *cobra.Command, args []string) error {
+	tablePath, _ := cmd.Flags().GetString("table")
+	statistical, _ := cmd.Flags().GetBool("statistical")
+	top, _ := cmd.Flags().GetInt("top")
+
+	f, err := os.Open(args[0])
+	if err != nil {
+		return err
+	}
+	events, err := engine.ReadEvents(f)
+	f.Close()
+	if err != nil {
+		return err
+	}
+
+	var ev engine.DecisionEvaluator = &engine.StatisticalEvaluator{}
+	if !statistical {
+		strategy, err := loadOptimalStrategy(tablePath)
+		switch {
+		case err == nil:
+			ev = engine.EvaluatorFor(strategy)
+		case tablePath != "":
+			return err
+		default:
+			fmt.Fprintln(os.Stderr, "Optimal table not found; using the statistical evaluator (run `yatz precompute` for exact grading).")
+		}
+	}
+
+	analysis, err := engine.AnalyzeGame(events, ev)
+	if err != nil {
+		return fmt.Errorf("analyze %s: %w", args[0], err)
+	}
+	printAnalysis(os.Stdout, analysis, top)
+	return nil
+}
+
+// mistakeMark labels a loss in expected points, chess style.
+func mistakeMark(loss float64) string {
+	switch {
+	case loss >= 10:
+		return "??"
+	case loss >= 3:
+		return "?"
+	case loss > 0.01:
+		return "?!"
+	}
+	return ""
+}
+
+func formatAction(a engine.TurnAction, dice [5]int) string {
+	if a.Type == "score" {
+		return "score " + string(a.Category)
+	}
+	if len(a.Indices) == 0 {
+		return "reroll all"
+	}
+	held := make([]string, len(a.Indices))
+	for i, idx := range a.Indices {
+		held[i] = fmt.Sprint(dice[idx])
+	}
+	return "hold " + strings.Join(held, " ")
+}
+
+func printAnalysis(w io.Writer, a *engine.GameAnalysis, top int) {
+	fmt.Fprintf(w, "=== Decision Analysis (%s evaluator) ===\n", a.Evaluator)
+
+	round := 0
+	for _, d := range a.Decisions {
+		if d.Round != round {
+			round = d.Round
+			fmt.Fprintf(w, "\nRound %d\n", round)
+		}
+		line := fmt.Sprintf("  %-12s roll %d  [%d %d %d %d %d]  %-22s",
+			d.PlayerName, d.RollCount, d.Dice[0], d.Dice[1], d.Dice[2], d.Dice[3], d.Dice[4],
+			formatAction(d.Chosen, d.Dice))
+		if mark := mistakeMark(d.Loss); mark != "" {
+			line += fmt.Sprintf(" %-2s -%.2f  (best: %s)", mark, d.Loss, formatAction(d.Best, d.Dice))
+		}
+		fmt.Fprintln(w, strings.TrimRight(line, " "))
+	}
+
+	if mistakes := a.WorstMistakes(top); len(mistakes) > 0 {
+		fmt.Fprintf(w, "\n=== Worst Mistakes ===\n")
+		for i, d := range mistakes {
+			fmt.Fprintf(w, "%d. Round %d, %s, roll %d [%d %d %d %d %d]: %s instead of %s (-%.2f)\n",
+				i+1, d.Round, d.PlayerName, d.RollCount, d.Dice[0], d.Dice[1], d.Dice[2], d.Dice[3], d.Dice[4],
+				formatAction(d.Chosen, d.Dice), formatAction(d.Best, d.Dice), d.Loss)
+		}
+	}
+
+	fmt.Fprintf(w, "\n=== Accuracy ===\n")
+	fmt.Fprintf(w, "%-16s %9s %9s %10s\n", "Player", "Decisions", "Accuracy", "EV Lost")
+	for _, p := range a.Players {
+		fmt.Fprintf(w, "%-16s %9d %8.1f%% %10.2f\n", p.Name, p.Decisions, p.Accuracy(), p.TotalLoss)
+	}
+}
@@ -1,9 +1,13 @@
 package main
 
 import (
+	"context"
 	"fmt"
+	"io"
 	"os"
+	"os/signal"
 	"sort"
+	"strconv"
 	"strings"
 	"time"
 
@@ -12,6 +16,7 @@ import (
 	"github.com/edge2992/yatzcli/bot"
 	"github.com/edge2992/yatzcli/cli"
 	"github.com/edge2992/yatzcli/engine"
+	"github.com/edge2992/yatzcli/stats"
 )
 
 var battleCmd = &cobra.Command{
@@ -22,13 +27,17 @@ var battleCmd = &cobra.Command{
 }
 
 func init() {
-	battleCmd.Flags().StringSlice("players", []string{"Greedy:greedy", "Statistical:statistical"}, `Players in "Name:strategy" format (greedy, statistical, llm:persona.md)`)
+	battleCmd.Flags().StringSlice("players", []string{"Greedy:greedy", "Statistical:statistical"}, `Players in "Name:strategy" format (greedy, statistical, win, optimal, exec:./mybot, llm:persona.md)`)
 	battleCmd.Flags().Duration("speed", time.Second, "Turn display speed")
 	battleCmd.Flags().Int64("seed", 0, "Random seed (0=random)")
 	battleCmd.Flags().String("api-key", "", "Claude API key (or ANTHROPIC_API_KEY env)")
-	battleCmd.Flags().String("model", "claude-haiku-4-5-20251001", "Claude model for LLM strategy")
-	battleCmd.Flags().Int("rounds", 1, "Number of consecutive games")
+	battleCmd.Flags().String("model", defaultModel, "Claude model for LLM strategy")
+	battleCmd.Flags().Int("rounds", 1, "Number of games; game i is played with seed+i")
+	battleCmd.Flags().Int("workers", 0, "Games played at once with --rounds (0 = one per CPU)")
 	battleCmd.Flags().Bool("quiet", false, "No TUI, show results only")
+	battleCmd.Flags().String("rules", "yahtzee", rulesFlagUsage())
+	battleCmd.Flags().String("record", "", "Write the game's event log to file (JSONL, single game only)")
+	battleCmd.Flags().Bool("save-stats", false, "Record every game of --rounds in the local stats (a single game always is)")
 }
 
 func parseBattlePlayers(playerSpecs []string, apiKey string, model string) ([]engine.BattlePlayer, error) {
@@ -54,12 +63,42 @@ func parseBattlePlayers(playerSpecs []string, apiKey string, model string) ([]en
 	return players, nil
 }
 
+// closeStrategies stops the strategies that hold resources, such as
+// strategy programs.
+func closeStrategies(players []engine.BattlePlayer) {
+	for _, p := range players {
+		if c, ok := p.Strategy.(io.Closer); ok {
+			c.Close()
+		}
+	}
+}
+
 func resolveStrategy(spec string, apiKey string, model string) (engine.Strategy, error) {
 	switch {
 	case spec == "greedy":
 		return &engine.GreedyStrategy{}, nil
 	case spec == "statistical":
 		return &engine.StatisticalStrategy{}, nil
+	case strings.HasPrefix(spec, "statistical:"):
+		depth, err := strconv.Atoi(strings.TrimPrefix(spec, "statistical:"))
+		if err != nil || depth < 1 {
+			return nil, fmt.Errorf("invalid statistical depth in %q: expected statistical:<rerolls>, at least 1", spec)
+		}
+		return &engine.StatisticalStrategy{Depth: depth}, nil
+	case spec == "win":
+		return &engine.WinProbabilityStrategy{}, nil
+	case spec == "optimal":
+		return loadOptimalStrategy("")
+	case strings.HasPrefix(spec, "optimal:"):
+		return loadOptimalStrategy(strings.TrimPrefix(spec, "optimal:"))
+	case strings.HasPrefix(spec, "exec:"):
+		command := strings.Fields(strings.TrimPrefix(spec, "exec:"))
+		if len(command) == 0 {
+			return nil, fmt.Errorf("invalid strategy %q: expected exec:<program>", spec)
+		}
+		s := bot.NewExecStrategy(command[0], command[1:]...)
+		s.Stderr = os.Stderr
+		return s, nil
 	case spec == "llm":
 		return bot.NewLLMStrategy(apiKey, model, nil), nil
 	case strings.HasPrefix(spec, "llm:"):
@@ -70,7 +109,7 @@ func resolveStrategy(spec string, apiKey string, model string) (engine.Strategy,
 		}
 		return bot.NewLLMStrategy(apiKey, model, persona), nil
 	default:
-		return nil, fmt.Errorf("unknown strategy %q (available: greedy, statistical, llm, llm:<persona.md>)", spec)
+		return nil, fmt.Errorf("unknown strategy %q (available: greedy, statistical, statistical:<depth>, win, optimal, optimal:<table>, exec:<program>, llm, llm:<persona.md>)", spec)
 	}
 }
 
@@ -82,6 +121,15 @@ func runBattle(cmd *cobra.Command, args []string) error {
 	quiet, _ := cmd.Flags().GetBool("quiet")
 	apiKey, _ := cmd.Flags().GetString("api-key")
 	model, _ := cmd.Flags().GetString("model")
+	rulesName, _ := cmd.Flags().GetString("rules")
+	record, _ := cmd.Flags().GetString("record")
+	workers, _ := cmd.Flags().GetInt("workers")
+	saveStats, _ := cmd.Flags().GetBool("save-stats")
+
+	rules, err := engine.RuleSetByName(rulesName)
+	if err != nil {
+		return err
+	}
 
 	if apiKey == "" {
 		apiKey = os.Getenv("ANTHROPIC_API_KEY")
@@ -91,42 +139,82 @@ func runBattle(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return err
 	}
+	defer closeStrategies(players)
 
-	if quiet {
-		return runQuietBattle(players, seed, rounds)
+	var onEvent func(engine.Event)
+	if record != "" {
+		if rounds > 1 {
+			return fmt.Errorf("--record supports a single game, got --rounds %d", rounds)
+		}
+		log, closeLog, err := openEventLog(record)
+		if err != nil {
+			return err
+		}
+		defer func() {
+			if err := closeLog(); err != nil {
+				fmt.Fprintln(os.Stderr, err)
+			}
+		}()
+		onEvent = log.Record
 	}
 
-	if rounds > 1 {
-		return runQuietBattle(players, seed, rounds)
+	if quiet || rounds > 1 {
+		return runQuietBattle(players, rules, seed, rounds, workers, saveStats || rounds == 1, onEvent)
 	}
 
-	return runTUIBattle(players, seed, speed)
+	return runTUIBattle(players, rules, seed, speed, onEvent)
 }
 
-func runQuietBattle(players []engine.BattlePlayer, seed int64, rounds int) error {
-	type stats struct {
+// runQuietBattle plays rounds games and prints a summary. With saveStats,
+// every game is recorded in the stats database, opened once for the run.
+func runQuietBattle(players []engine.BattlePlayer, rules engine.RuleSet, seed int64, rounds, workers int, saveStats bool, onEvent func(engine.Event)) error {
+	type tally struct {
 		wins     int
 		total    int
 		maxScore int
 	}
-	playerStats := make(map[string]*stats)
+	playerStats := make(map[string]*tally)
 	for _, p := range players {
-		playerStats[p.Name] = &stats{}
+		playerStats[p.Name] = &tally{}
 	}
 
-	for r := 0; r < rounds; r++ {
-		gameSeed := seed
-		if seed != 0 {
-			gameSeed = seed + int64(r)
+	var store *stats.Store
+	if saveStats {
+		var err error
+		if store, err = openStatsStore(); err != nil {
+			fmt.Fprintf(os.Stderr, "warning: %v\n", err)
+		} else {
+			defer store.Close()
 		}
+	}
 
-		state, err := engine.RunBattle(engine.BattleConfig{
-			Players: players,
-			Seed:    gameSeed,
-		})
-		if err != nil {
-			return fmt.Errorf("game %d failed: %w", r+1, err)
+	// Ctrl-C stops the simulation and reports the games played so far.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	cfg := engine.SimulateConfig{
+		Players: players,
+		Games:   rounds,
+		Seed:    seed,
+		Rules:   rules,
+		Workers: workers,
+	}
+	if onEvent != nil {
+		cfg.OnEvent = func(_ int, e engine.Event) { onEvent(e) }
+	}
+
+	played := 0
+	for res := range engine.Simulate(ctx, cfg) {
+		if res.Err != nil {
+			stop()
+			return fmt.Errorf("game %d (seed %d) failed: %w", res.Game+1, res.Seed, res.Err)
 		}
+		state := res.State
+		if store != nil {
+			if _, err := store.Add(battleRecord(players, *state)); err != nil {
+				fmt.Fprintf(os.Stderr, "warning: %v\n", err)
+			}
+		}
+		played++
 
 		// Find winner
 		bestScore := -1
@@ -145,6 +233,9 @@ func runQuietBattle(players []engine.BattlePlayer, seed int64, rounds int) error
 		}
 		playerStats[winner].wins++
 	}
+	if played == 0 {
+		return ctx.Err()
+	}
 
 	// Sort players by wins descending
 	type row struct {
@@ -159,13 +250,16 @@ func runQuietBattle(players []engine.BattlePlayer, seed int64, rounds int) error
 		rows = append(rows, row{
 			name:     p.Name,
 			wins:     st.wins,
-			avgScore: float64(st.total) / float64(rounds),
+			avgScore: float64(st.total) / float64(played),
 			maxScore: st.maxScore,
 		})
 	}
 	sort.Slice(rows, func(i, j int) bool { return rows[i].wins > rows[j].wins })
 
-	fmt.Fprintf(os.Stdout, "\n=== Battle Results (%d games) ===\n", rounds)
+	if played < rounds {
+		fmt.Fprintf(os.Stdout, "\nInterrupted after %d of %d games.\n", played, rounds)
+	}
+	fmt.Fprintf(os.Stdout, "\n=== Battle Results (%d games) ===\n", played)
 	fmt.Fprintf(os.Stdout, "%-16s %6s %11s %11s\n", "Player", "Wins", "Avg Score", "Max Score")
 	for _, r := range rows {
 		fmt.Fprintf(os.Stdout, "%-16s %6d %11.1f %11d\n", r.name, r.wins, r.avgScore, r.maxScore)
@@ -174,12 +268,14 @@ func runQuietBattle(players []engine.BattlePlayer, seed int64, rounds int) error
 	return nil
 }
 
-func runTUIBattle(players []engine.BattlePlayer, seed int64, speed time.Duration) error {
+func runTUIBattle(players []engine.BattlePlayer, rules engine.RuleSet, seed int64, speed time.Duration, onEvent func(engine.Event)) error {
 	resultCh := make(chan engine.AITurnResult, 64)
 
 	cfg := engine.BattleConfig{
 		Players: players,
 		Seed:    seed,
+		Rules:   rules,
+		OnEvent: onEvent,
 		OnTurnDone: func(result engine.AITurnResult) {
 			resultCh <- result
 		},
@@ -189,10 +285,22 @@ func runTUIBattle(players []engine.BattlePlayer, seed int64, speed time.Duration
 	// errCh is buffered (cap=1) so the goroutine never blocks after closing resultCh.
 	errCh := make(chan error, 1)
 	go func() {
-		_, err := engine.RunBattle(cfg)
+		state, err := engine.RunBattle(cfg)
+		if err == nil {
+			recordGame(battleRecord(players, *state))
+		}
 		close(resultCh)
 		errCh <- err
 	}()
 
-	return cli.RunSpectator(resultCh, errCh, players, speed)
+	return cli.RunSpectator(resultCh, errCh, players, rules, speed)
+}
+
+// battleRecord is the stats record of a finished battle.
+func battleRecord(players []engine.BattlePlayer, state engine.GameState) stats.Game {
+	strategies := make(map[string]string)
+	for i, p := range state.Players {
+		strategies[p.ID] = players[i].Strategy.Name()
+	}
+	return stats.NewGame(stats.ModeBattle, state, "", strategies)
 }
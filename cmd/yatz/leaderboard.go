@@ -0,0 +1,75 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/edge2992/yatzcli/p2p"
+)
+
+var leaderboardCmd = &cobra.Command{
+	Use:   "leaderboard [address]",
+	Short: "Show the best-rated players on a lobby server",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		limit, _ := cmd.Flags().GetInt("limit")
+		opts, err := clientSecurityOptions(cmd, args[0])
+		if err != nil {
+			return err
+		}
+		lc, err := p2p.DialLobby(args[0], "Guest", opts...)
+		if err != nil {
+			return err
+		}
+		defer lc.Close()
+		entries, err := lc.Leaderboard(limit)
+		if err != nil {
+			return err
+		}
+		if len(entries) == 0 {
+			fmt.Println("No rated games yet.")
+			return nil
+		}
+		fmt.Printf("%4s  %-20s %7s %6s %6s %6s\n", "#", "Player", "Rating", "±", "Games", "Wins")
+		for i, e := range entries {
+			fmt.Printf("%4d  %-20s %7.0f %6.0f %6d %6d\n", i+1, e.Name, e.Rating, 2*e.Deviation, e.Games, e.Wins)
+		}
+		return nil
+	},
+}
+
+// accountKey returns this machine's account key, which signs the player in
+// to their account on servers that keep ratings. It is generated on first
+// use.
+func accountKey() (string, error) {
+	path, err := configPath("account.key")
+	if err != nil {
+		return "", err
+	}
+	data, err := os.ReadFile(path)
+	if err == nil {
+		return strings.TrimSpace(string(data)), nil
+	}
+	if !errors.Is(err, os.ErrNotExist) {
+		return "", fmt.Errorf("read account key: %w", err)
+	}
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("generate account key: %w", err)
+	}
+	key := hex.EncodeToString(buf)
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return "", fmt.Errorf("save account key: %w", err)
+	}
+	if err := os.WriteFile(path, []byte(key+"\n"), 0o600); err != nil {
+		return "", fmt.Errorf("save account key: %w", err)
+	}
+	return key, nil
+}
@@ -3,62 +3,141 @@ package main
 import (
 	"fmt"
 	"os"
+	"strings"
 
 	"github.com/spf13/cobra"
 
-	"github.com/edge2992/yatzcli/cli"
 	"github.com/edge2992/yatzcli/engine"
 	"github.com/edge2992/yatzcli/match"
 	mcpserver "github.com/edge2992/yatzcli/mcp"
 	"github.com/edge2992/yatzcli/p2p"
+	"github.com/edge2992/yatzcli/stats"
 )
 
+// defaultModel is the Claude model behind llm strategies unless --model says
+// otherwise.
+const defaultModel = "claude-haiku-4-5-20251001"
+
 var rootCmd = &cobra.Command{
 	Use:   "yatz",
 	Short: "Yahtzee CLI game",
 }
 
-var playCmd = &cobra.Command{
-	Use:   "play",
-	Short: "Play a local game against AI",
+var hostCmd = &cobra.Command{
+	Use:   "host",
+	Short: "Host a P2P game",
 	RunE: func(cmd *cobra.Command, args []string) error {
-		opponents, _ := cmd.Flags().GetInt("opponents")
-		playerName, _ := cmd.Flags().GetString("name")
+		port, _ := cmd.Flags().GetInt("port")
+		name, _ := cmd.Flags().GetString("name")
+		rulesName, _ := cmd.Flags().GetString("rules")
+		players, _ := cmd.Flags().GetInt("players")
+		grace, _ := cmd.Flags().GetDuration("grace")
+		wsPort, _ := cmd.Flags().GetInt("ws-port")
+		fairDice, _ := cmd.Flags().GetBool("fair-dice")
 
-		names := []string{playerName}
-		for i := 0; i < opponents; i++ {
-			names = append(names, fmt.Sprintf("AI_%d", i+1))
+		if players < 2 {
+			return fmt.Errorf("--players must be at least 2, got %d", players)
 		}
-
-		game := engine.NewGame(names, nil)
-		var ais []*engine.AIPlayer
-		for i := 1; i <= opponents; i++ {
-			pid := fmt.Sprintf("player-%d", i)
-			ais = append(ais, engine.NewAIPlayer(game, pid))
+		rules, err := engine.RuleSetByName(rulesName)
+		if err != nil {
+			return err
 		}
-
-		client := engine.NewLocalClient(game, "player-0", ais)
-		return cli.RunGame(client, playerName)
+		opts, err := coachOptions(cmd)
+		if err != nil {
+			return err
+		}
+		clockOpts, err := turnClockOptions(cmd)
+		if err != nil {
+			return err
+		}
+		opts = append(opts, clockOpts...)
+		secOpts, err := serverSecurityOptions(cmd)
+		if err != nil {
+			return err
+		}
+		opts = append(opts, secOpts...)
+		opts = append(opts, p2p.WithRules(rules), p2p.WithPlayers(players), p2p.WithGracePeriod(grace), p2p.WithWebSocketPort(wsPort), p2p.WithGameRecorder(stats.Record))
+		if fairDice {
+			opts = append(opts, p2p.WithVerifiableDice())
+		}
+		return p2p.RunHost(port, name, opts...)
 	},
 }
 
-var hostCmd = &cobra.Command{
-	Use:   "host",
-	Short: "Host a P2P game",
+var joinCmd = &cobra.Command{
+	Use:   "join [address]",
+	Short: "Join a P2P game (host:port, or a ws:// or wss:// URL)",
+	Args:  cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		port, _ := cmd.Flags().GetInt("port")
 		name, _ := cmd.Flags().GetString("name")
-		return p2p.RunHost(port, name)
+		tableID, _ := cmd.Flags().GetString("table")
+		seats, _ := cmd.Flags().GetInt("create")
+		rulesName, _ := cmd.Flags().GetString("rules")
+		opts, err := coachOptions(cmd)
+		if err != nil {
+			return err
+		}
+		secOpts, err := clientSecurityOptions(cmd, args[0])
+		if err != nil {
+			return err
+		}
+		opts = append(opts, secOpts...)
+		key, err := accountKey()
+		if err != nil {
+			return err
+		}
+		opts = append(opts, p2p.WithGameRecorder(stats.Record), p2p.WithAccountKey(key))
+		if tableID != "" && seats > 0 {
+			return fmt.Errorf("--table and --create are mutually exclusive")
+		}
+		if tableID != "" || seats > 0 {
+			req := p2p.TableRequest{TableID: tableID, Seats: seats, Rules: rulesName}
+			return p2p.RunLobbyGuest(args[0], name, req, opts...)
+		}
+		return p2p.RunGuest(args[0], name, opts...)
 	},
 }
 
-var joinCmd = &cobra.Command{
-	Use:   "join [address]",
-	Short: "Join a P2P game",
+var tablesCmd = &cobra.Command{
+	Use:   "tables [address]",
+	Short: "List the open tables on a lobby server",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		opts, err := clientSecurityOptions(cmd, args[0])
+		if err != nil {
+			return err
+		}
+		lc, err := p2p.DialLobby(args[0], "Guest", opts...)
+		if err != nil {
+			return err
+		}
+		defer lc.Close()
+		tables, err := lc.ListTables()
+		if err != nil {
+			return err
+		}
+		if len(tables) == 0 {
+			fmt.Println("No open tables. Create one with: yatz join " + args[0] + " --create 2")
+			return nil
+		}
+		for _, t := range tables {
+			fmt.Printf("%-6s %-9s %d/%d  %s\n", t.ID, t.Rules, len(t.Players), t.Seats, strings.Join(t.Players, ", "))
+		}
+		return nil
+	},
+}
+
+var watchCmd = &cobra.Command{
+	Use:   "watch [address]",
+	Short: "Watch a game on a yatz serve server",
 	Args:  cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		name, _ := cmd.Flags().GetString("name")
-		return p2p.RunGuest(args[0], name)
+		opts, err := clientSecurityOptions(cmd, args[0])
+		if err != nil {
+			return err
+		}
+		return p2p.RunWatch(args[0], name, opts...)
 	},
 }
 
@@ -68,6 +147,13 @@ var matchCmd = &cobra.Command{
 	RunE: func(cmd *cobra.Command, args []string) error {
 		name, _ := cmd.Flags().GetString("name")
 		serverURL, _ := cmd.Flags().GetString("server")
+		rating, _ := cmd.Flags().GetFloat64("rating")
+
+		opts, err := coachOptions(cmd)
+		if err != nil {
+			return err
+		}
+		opts = append(opts, p2p.WithGameRecorder(stats.Record))
 
 		port, err := match.GetFreePort()
 		if err != nil {
@@ -75,7 +161,7 @@ var matchCmd = &cobra.Command{
 		}
 
 		fmt.Printf("Searching for opponent...\n")
-		result, err := match.FindMatch(serverURL, name, port)
+		result, err := match.FindMatch(serverURL, name, port, rating)
 		if err != nil {
 			return fmt.Errorf("matchmaking failed: %w", err)
 		}
@@ -83,9 +169,9 @@ var matchCmd = &cobra.Command{
 		fmt.Printf("Matched with %s!\n", result.OpponentName)
 
 		if result.IsHost {
-			return p2p.RunHost(port, name)
+			return p2p.RunHost(port, name, opts...)
 		}
-		return p2p.RunGuest(result.OpponentAddr, name)
+		return p2p.RunGuest(result.OpponentAddr, name, opts...)
 	},
 }
 
@@ -100,32 +186,124 @@ var mcpCmd = &cobra.Command{
 func init() {
 	playCmd.Flags().IntP("opponents", "o", 1, "Number of AI opponents (1-3)")
 	playCmd.Flags().StringP("name", "n", "Player", "Your player name")
+	playCmd.Flags().String("rules", "yahtzee", rulesFlagUsage())
+	playCmd.Flags().String("resume", "", "Resume a saved game from file")
+	playCmd.Flags().String("record", "", "Write the game's event log to file (JSONL)")
+	addCoachFlag(playCmd)
 	rootCmd.AddCommand(playCmd)
 
 	hostCmd.Flags().IntP("port", "p", 9876, "Port to listen on")
 	hostCmd.Flags().StringP("name", "n", "Host", "Your player name")
+	hostCmd.Flags().String("rules", "yahtzee", rulesFlagUsage())
+	hostCmd.Flags().Int("players", 2, "Number of players including you; waits for players-1 guests")
+	hostCmd.Flags().Int("ws-port", 0, "Also accept guests over WebSocket on this port (join with ws://host:port)")
+	hostCmd.Flags().Duration("grace", p2p.DefaultGracePeriod, "How long a dropped guest's seat is held for them to reconnect")
+	hostCmd.Flags().Bool("fair-dice", false, "Roll verifiable dice from seeds every player commits to, so guests can check no roll was rigged")
+	addCoachFlag(hostCmd)
+	addTurnClockFlags(hostCmd)
+	addServerSecurityFlags(hostCmd)
 	rootCmd.AddCommand(hostCmd)
 
 	joinCmd.Flags().StringP("name", "n", "Guest", "Your player name")
+	joinCmd.Flags().String("table", "", "Join this table on a lobby server (see yatz tables)")
+	joinCmd.Flags().Int("create", 0, "Create a table with this many seats on a lobby server; it starts when full")
+	joinCmd.Flags().String("rules", "", "Rules of a table created with --create (default: the server's)")
+	addCoachFlag(joinCmd)
+	addClientSecurityFlags(joinCmd)
 	rootCmd.AddCommand(joinCmd)
 
+	addClientSecurityFlags(tablesCmd)
+	rootCmd.AddCommand(tablesCmd)
+
+	leaderboardCmd.Flags().Int("limit", 20, "Number of players to show (0 for all)")
+	addClientSecurityFlags(leaderboardCmd)
+	rootCmd.AddCommand(leaderboardCmd)
+
+	watchCmd.Flags().StringP("name", "n", "Spectator", "Your name in chat")
+	addClientSecurityFlags(watchCmd)
+	rootCmd.AddCommand(watchCmd)
+
 	matchCmd.Flags().StringP("name", "n", "Player", "Your player name")
 	matchCmd.Flags().String("server", "", "Matchmaking server WebSocket URL")
+	matchCmd.Flags().Float64("rating", 0, "Your rating (see yatz leaderboard), to be paired with a player of similar rating; not verified")
+	addCoachFlag(matchCmd)
 	rootCmd.AddCommand(matchCmd)
 
 	rootCmd.AddCommand(mcpCmd)
 
+	statsCmd.Flags().String("player", "", "Show this player's stats instead of yours")
+	rootCmd.AddCommand(statsCmd)
+
 	serveCmd.Flags().IntP("port", "p", 9876, "Port to listen on")
+	serveCmd.Flags().Int("ws-port", 0, "Also accept players over WebSocket on this port (join with ws://host:port)")
 	serveCmd.Flags().Int("players", 2, "Number of players")
+	serveCmd.Flags().String("rules", "yahtzee", rulesFlagUsage())
+	serveCmd.Flags().StringSlice("ai", nil, `Fill seats with in-process AI players, as "count:strategy" (e.g. 1:statistical)`)
+	serveCmd.Flags().Duration("ai-delay", p2p.DefaultAIDelay, "Pause between an AI player's rolls so others can follow")
+	serveCmd.Flags().Bool("lobby", false, "Keep running and host many games at tables players create and join")
+	serveCmd.Flags().Duration("grace", p2p.DefaultGracePeriod, "How long a dropped player's seat is held for them to reconnect")
+	serveCmd.Flags().Bool("fair-dice", false, "Roll verifiable dice from seeds every player commits to, so players can check no roll was rigged")
+	serveCmd.Flags().String("ratings", "", "Keep player accounts and Glicko-2 ratings in this database file, and rate every game between signed-in players")
+	addModelFlag(serveCmd)
+	addTurnClockFlags(serveCmd)
+	addServerSecurityFlags(serveCmd)
 	rootCmd.AddCommand(serveCmd)
 
 	botCmd.Flags().String("addr", "localhost:9876", "Game server address")
 	botCmd.Flags().StringP("name", "n", "Claude", "Bot player name")
 	botCmd.Flags().String("strategy", "", "Path to strategy file (uses built-in if empty)")
-	botCmd.Flags().StringP("model", "m", "claude-haiku-4-5-20251001", "Claude model to use (e.g. claude-haiku-4-5-20251001, claude-sonnet-4-6)")
+	botCmd.Flags().StringP("model", "m", defaultModel, "Claude model to use (e.g. claude-sonnet-4-6)")
 	rootCmd.AddCommand(botCmd)
 
 	rootCmd.AddCommand(battleCmd)
+
+	rootCmd.AddCommand(tournamentCmd)
+
+	rootCmd.AddCommand(precomputeCmd)
+
+	rootCmd.AddCommand(replayCmd)
+
+	rootCmd.AddCommand(analyzeCmd)
+}
+
+func rulesFlagUsage() string {
+	return fmt.Sprintf("Rule set (%s)", strings.Join(engine.RuleSetNames(), ", "))
+}
+
+// addCoachFlag adds --coach[=strategy], enabling the TUI hint key.
+func addCoachFlag(cmd *cobra.Command) {
+	cmd.Flags().String("coach", "", "Enable the [?] hint key, optionally with a strategy (e.g. --coach=optimal); P2P games need every player to agree")
+	cmd.Flags().Lookup("coach").NoOptDefVal = "statistical"
+	addModelFlag(cmd)
+}
+
+// addModelFlag adds --model, the Claude model for llm strategies, once.
+func addModelFlag(cmd *cobra.Command) {
+	if cmd.Flags().Lookup("model") == nil {
+		cmd.Flags().String("model", defaultModel, "Claude model for LLM strategies")
+	}
+}
+
+// coachStrategy resolves the --coach flag; it returns nil when unset.
+func coachStrategy(cmd *cobra.Command) (engine.Strategy, error) {
+	spec, _ := cmd.Flags().GetString("coach")
+	if spec == "" {
+		return nil, nil
+	}
+	model, _ := cmd.Flags().GetString("model")
+	strategy, err := resolveStrategy(spec, os.Getenv("ANTHROPIC_API_KEY"), model)
+	if err != nil {
+		return nil, fmt.Errorf("coach: %w", err)
+	}
+	return strategy, nil
+}
+
+func coachOptions(cmd *cobra.Command) ([]p2p.Option, error) {
+	strategy, err := coachStrategy(cmd)
+	if err != nil || strategy == nil {
+		return nil, err
+	}
+	return []p2p.Option{p2p.WithCoach(strategy)}, nil
 }
 
 func main() {
@@ -134,3 +312,24 @@ func main() {
 		os.Exit(1)
 	}
 }
+
+func addTurnClockFlags(cmd *cobra.Command) {
+	cmd.Flags().Duration("turn-timeout", 0, "Time limit per turn (e.g. 60s); 0 means untimed")
+	cmd.Flags().String("autoplay", "statistical", "Strategy that plays a turn when its clock runs out")
+	addModelFlag(cmd)
+}
+
+// turnClockOptions resolves --turn-timeout and --autoplay.
+func turnClockOptions(cmd *cobra.Command) ([]p2p.Option, error) {
+	limit, _ := cmd.Flags().GetDuration("turn-timeout")
+	if limit <= 0 {
+		return nil, nil
+	}
+	spec, _ := cmd.Flags().GetString("autoplay")
+	model, _ := cmd.Flags().GetString("model")
+	strategy, err := resolveStrategy(spec, os.Getenv("ANTHROPIC_API_KEY"), model)
+	if err != nil {
+		return nil, fmt.Errorf("autoplay: %w", err)
+	}
+	return []p2p.Option{p2p.WithTurnClock(limit, strategy)}, nil
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"github.com/edge2992/yatzcli/cli"
+	"github.com/edge2992/yatzcli/engine"
+	"github.com/edge2992/yatzcli/stats"
+)
+
+var playCmd = &cobra.Command{
+	Use:   "play",
+	Short: "Play a local game against AI",
+	Long: `Play a local game against AI. Quitting an unfinished game saves it
+automatically; continue it later with --resume.`,
+	RunE: runPlay,
+}
+
+func runPlay(cmd *cobra.Command, args []string) error {
+	opponents, _ := cmd.Flags().GetInt("opponents")
+	playerName, _ := cmd.Flags().GetString("name")
+	rulesName, _ := cmd.Flags().GetString("rules")
+	resume, _ := cmd.Flags().GetString("resume")
+	record, _ := cmd.Flags().GetString("record")
+
+	coach, err := coachStrategy(cmd)
+	if err != nil {
+		return err
+	}
+
+	var game *engine.Game
+	if resume != "" {
+		for _, flag := range []string{"rules", "opponents"} {
+			if cmd.Flags().Changed(flag) {
+				return fmt.Errorf("--%s can't be changed when resuming a game", flag)
+			}
+		}
+		g, err := engine.LoadGame(resume)
+		if err != nil {
+			return fmt.Errorf("resume %s: %w", resume, err)
+		}
+		if g.Phase == engine.PhaseFinished {
+			return fmt.Errorf("resume %s: the game is already finished", resume)
+		}
+		game = g
+		// The local player is always player-0; the rest are AI.
+		playerName = game.Players[0].Name
+	} else {
+		rules, err := engine.RuleSetByName(rulesName)
+		if err != nil {
+			return err
+		}
+		names := []string{playerName}
+		for i := 0; i < opponents; i++ {
+			names = append(names, fmt.Sprintf("AI_%d", i+1))
+		}
+		game = engine.NewGameWithRules(names, nil, rules)
+	}
+
+	var ais []*engine.AIPlayer
+	for _, p := range game.Players[1:] {
+		ais = append(ais, engine.NewAIPlayer(game, p.ID))
+	}
+
+	if record != "" {
+		log, closeLog, err := openEventLog(record)
+		if err != nil {
+			return err
+		}
+		defer func() {
+			if err := closeLog(); err != nil {
+				fmt.Fprintln(os.Stderr, err)
+			}
+		}()
+		game.OnEvent(log.Record)
+	}
+
+	var opts []cli.GameOption
+	if coach != nil {
+		opts = append(opts, cli.WithCoach(coach))
+	}
+
+	client := engine.NewLocalClient(game, "player-0", ais)
+	if err := cli.RunGame(client, playerName, opts...); err != nil {
+		return err
+	}
+	if game.Phase == engine.PhaseFinished {
+		strategies := make(map[string]string)
+		for i, ai := range ais {
+			strategies[game.Players[i+1].ID] = ai.Strategy().Name()
+		}
+		recordGame(stats.NewGame(stats.ModeLocal, game.GetState(), "player-0", strategies))
+		// The save is played out: resuming it again would replay and
+		// record the game twice.
+		if resume != "" {
+			if err := os.Remove(resume); err != nil {
+				fmt.Fprintf(os.Stderr, "warning: remove finished save: %v\n", err)
+			}
+		}
+		return nil
+	}
+
+	path := resume
+	if path == "" {
+		p, err := defaultSavePath()
+		if err != nil {
+			return err
+		}
+		path = p
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("create save directory: %w", err)
+	}
+	if err := engine.SaveGame(game, path); err != nil {
+		return fmt.Errorf("save game: %w", err)
+	}
+	fmt.Printf("Game saved. Resume with: yatz play --resume %s\n", path)
+	return nil
+}
+
+func defaultSavePath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("locate config directory: %w", err)
+	}
+	return filepath.Join(dir, "yatzcli", "saved-game.json"), nil
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/edge2992/yatzcli/engine"
+)
+
+var precomputeCmd = &cobra.Command{
+	Use:   "precompute",
+	Short: "Solve the optimal strategy table",
+	Long:  `Solve every solitaire game state and write the table used by the "optimal" strategy. This takes about a minute.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		out, _ := cmd.Flags().GetString("out")
+		if out == "" {
+			path, err := defaultOptimalTablePath()
+			if err != nil {
+				return err
+			}
+			out = path
+		}
+		if err := os.MkdirAll(filepath.Dir(out), 0o755); err != nil {
+			return fmt.Errorf("create table directory: %w", err)
+		}
+
+		fmt.Println("Solving optimal strategy table...")
+		start := time.Now()
+		table := engine.SolveOptimalTable()
+		if err := engine.SaveOptimalTable(table, out); err != nil {
+			return err
+		}
+		fmt.Printf("Expected score %.2f, solved in %s\n", table.ExpectedScore(), time.Since(start).Round(time.Second))
+		fmt.Printf("Wrote %s\n", out)
+		return nil
+	},
+}
+
+func init() {
+	precomputeCmd.Flags().String("out", "", "Output file (default: user cache directory)")
+}
+
+func defaultOptimalTablePath() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("locate cache directory: %w", err)
+	}
+	return filepath.Join(dir, "yatzcli", "optimal.bin.gz"), nil
+}
+
+// loadOptimalStrategy loads the table at path, or the default location when
+// path is empty.
+func loadOptimalStrategy(path string) (engine.Strategy, error) {
+	if path == "" {
+		p, err := defaultOptimalTablePath()
+		if err != nil {
+			return nil, err
+		}
+		path = p
+	}
+	table, err := engine.LoadOptimalTable(path)
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("optimal table %s not found: run `yatz precompute` first", path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("load optimal table %s: %w", path, err)
+	}
+	return engine.NewOptimalStrategy(table)
+}
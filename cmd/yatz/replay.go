@@ -0,0 +1,63 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/edge2992/yatzcli/cli"
+	"github.com/edge2992/yatzcli/engine"
+)
+
+var replayCmd = &cobra.Command{
+	Use:   "replay <file>",
+	Short: "Step through a recorded game",
+	Long:  `Replay a game event log written with --record. The events are re-applied on a fresh game to verify the log before it is shown.`,
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		verifyOnly, _ := cmd.Flags().GetBool("verify")
+
+		f, err := os.Open(args[0])
+		if err != nil {
+			return err
+		}
+		events, err := engine.ReadEvents(f)
+		f.Close()
+		if err != nil {
+			return err
+		}
+
+		states, err := engine.Replay(events)
+		if err != nil {
+			return fmt.Errorf("verify %s: %w", args[0], err)
+		}
+		if verifyOnly {
+			fmt.Printf("%s: %d events verified\n", args[0], len(events))
+			return nil
+		}
+		return cli.RunReplay(events, states)
+	},
+}
+
+func init() {
+	replayCmd.Flags().Bool("verify", false, "Only verify the log, without the TUI")
+}
+
+// openEventLog creates path and returns an event log writing to it. The
+// returned close function reports any write error.
+func openEventLog(path string) (*engine.EventLog, func() error, error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("create event log: %w", err)
+	}
+	log := engine.NewEventLog(f)
+	closeFn := func() error {
+		if err := log.Err(); err != nil {
+			f.Close()
+			return err
+		}
+		return f.Close()
+	}
+	return log, closeFn, nil
+}
@@ -4,11 +4,17 @@ import (
 	"fmt"
 	"math/rand"
 	"net"
+	"os"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/spf13/cobra"
 
+	"github.com/edge2992/yatzcli/engine"
 	"github.com/edge2992/yatzcli/p2p"
+	"github.com/edge2992/yatzcli/rating"
+	"github.com/edge2992/yatzcli/stats"
 )
 
 var serveCmd = &cobra.Command{
@@ -17,14 +23,105 @@ var serveCmd = &cobra.Command{
 	RunE: func(cmd *cobra.Command, args []string) error {
 		port, _ := cmd.Flags().GetInt("port")
 		players, _ := cmd.Flags().GetInt("players")
+		rulesName, _ := cmd.Flags().GetString("rules")
+		grace, _ := cmd.Flags().GetDuration("grace")
+		lobby, _ := cmd.Flags().GetBool("lobby")
+		aiSpecs, _ := cmd.Flags().GetStringSlice("ai")
+		aiDelay, _ := cmd.Flags().GetDuration("ai-delay")
+		wsPort, _ := cmd.Flags().GetInt("ws-port")
+		fairDice, _ := cmd.Flags().GetBool("fair-dice")
+		ratingsPath, _ := cmd.Flags().GetString("ratings")
 
-		ln, err := net.Listen("tcp", fmt.Sprintf(":%d", port))
+		rules, err := engine.RuleSetByName(rulesName)
+		if err != nil {
+			return err
+		}
+		opts, err := turnClockOptions(cmd)
+		if err != nil {
+			return err
+		}
+		secOpts, err := serverSecurityOptions(cmd)
+		if err != nil {
+			return err
+		}
+		opts = append(opts, secOpts...)
+		opts = append(opts, p2p.WithRules(rules), p2p.WithGracePeriod(grace), p2p.WithGameRecorder(stats.Record))
+		if fairDice {
+			if lobby {
+				return fmt.Errorf("--fair-dice is not supported with --lobby")
+			}
+			opts = append(opts, p2p.WithVerifiableDice())
+		}
+		model, _ := cmd.Flags().GetString("model")
+		aiSeats, err := parseAISeats(aiSpecs, model)
+		if err != nil {
+			return err
+		}
+		if len(aiSeats) > 0 {
+			if lobby {
+				return fmt.Errorf("--ai is not supported with --lobby")
+			}
+			if len(aiSeats) >= players {
+				return fmt.Errorf("--ai fills %d of %d seats; leave at least one for a remote player", len(aiSeats), players)
+			}
+			opts = append(opts, p2p.WithAISeats(aiSeats...), p2p.WithAIDelay(aiDelay))
+		}
+
+		if ratingsPath != "" {
+			store, err := rating.Open(ratingsPath)
+			if err != nil {
+				return err
+			}
+			defer store.Close()
+			opts = append(opts, p2p.WithRatings(store))
+		}
+
+		var ln net.Listener
+		ln, err = net.Listen("tcp", fmt.Sprintf(":%d", port))
 		if err != nil {
 			return fmt.Errorf("listen: %w", err)
 		}
+		if wsPort > 0 {
+			wsLn, err := net.Listen("tcp", fmt.Sprintf(":%d", wsPort))
+			if err != nil {
+				ln.Close()
+				return fmt.Errorf("listen: %w", err)
+			}
+			fmt.Printf("WebSocket listener on port %d\n", wsPort)
+			ln = p2p.MultiListener(ln, p2p.ListenWebSocket(wsLn))
+		}
 		defer ln.Close()
 
-		fmt.Printf("Game server listening on port %d, waiting for %d players...\n", port, players)
-		return p2p.RunServer(ln, players, rand.NewSource(time.Now().UnixNano()))
+		if lobby {
+			fmt.Printf("Lobby server listening on port %d\n", port)
+			return p2p.NewLobby(opts...).Serve(ln)
+		}
+
+		fmt.Printf("Game server listening on port %d, waiting for %d players...\n", port, players-len(aiSeats))
+		return p2p.RunServer(ln, players, rand.NewSource(time.Now().UnixNano()), opts...)
 	},
 }
+
+// parseAISeats parses --ai specs in "count:strategy" form into seats named
+// AI-1, AI-2, ...; llm seats use model.
+func parseAISeats(specs []string, model string) ([]p2p.AISeat, error) {
+	var seats []p2p.AISeat
+	for _, spec := range specs {
+		countStr, stratSpec, ok := strings.Cut(spec, ":")
+		if !ok {
+			return nil, fmt.Errorf("invalid --ai spec %q: expected count:strategy", spec)
+		}
+		count, err := strconv.Atoi(countStr)
+		if err != nil || count < 1 {
+			return nil, fmt.Errorf("invalid --ai spec %q: count must be a positive number", spec)
+		}
+		for range count {
+			strategy, err := resolveStrategy(stratSpec, os.Getenv("ANTHROPIC_API_KEY"), model)
+			if err != nil {
+				return nil, fmt.Errorf("--ai: %w", err)
+			}
+			seats = append(seats, p2p.AISeat{Name: fmt.Sprintf("AI-%d", len(seats)+1), Strategy: strategy})
+		}
+	}
+	return seats, nil
+}
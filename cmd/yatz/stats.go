@@ -0,0 +1,106 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/edge2992/yatzcli/stats"
+)
+
+var statsCmd = &cobra.Command{
+	Use:   "stats",
+	Short: "Show statistics of the games played on this machine",
+	Long: `Show statistics of every finished game recorded on this machine: games
+played with yatz play, battle, host, join, match, serve and the MCP server.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		player, _ := cmd.Flags().GetString("player")
+
+		path, err := stats.DefaultPath()
+		if err != nil {
+			return err
+		}
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			fmt.Println("No games recorded yet.")
+			return nil
+		}
+		store, err := stats.Open(path)
+		if err != nil {
+			return err
+		}
+		games, err := store.Games()
+		store.Close()
+		if err != nil {
+			return err
+		}
+
+		who := "You"
+		if player != "" {
+			who = player
+		}
+		printProfile(who, stats.ProfileOf(games, player))
+		printStrategyResults(stats.StrategyResults(games))
+		return nil
+	},
+}
+
+// openStatsStore opens the stats database, for commands that record many
+// games in one run.
+func openStatsStore() (*stats.Store, error) {
+	path, err := stats.DefaultPath()
+	if err != nil {
+		return nil, err
+	}
+	return stats.Open(path)
+}
+
+// recordGame adds g to the stats database. A failure only warns: the game
+// itself went fine.
+func recordGame(g stats.Game) {
+	if err := stats.Record(g); err != nil {
+		fmt.Fprintf(os.Stderr, "warning: %v\n", err)
+	}
+}
+
+func printProfile(who string, p stats.Profile) {
+	fmt.Printf("=== %s ===\n", who)
+	if p.Games == 0 {
+		fmt.Println("No games recorded.")
+		return
+	}
+	fmt.Printf("Games played:   %d\n", p.Games)
+	fmt.Printf("Wins:           %d (%.1f%%)\n", p.Wins, 100*p.WinRate())
+	fmt.Printf("Average score:  %.1f\n", p.AverageScore())
+	fmt.Printf("Best score:     %d\n", p.BestScore)
+	fmt.Printf("Yahtzees:       %d, in %.1f%% of games\n", p.Yahtzees, 100*p.YahtzeeRate())
+	fmt.Printf("Upper bonus:    %.1f%% of games\n", 100*p.UpperBonusRate())
+
+	fmt.Printf("\n%-18s %8s\n", "Category", "Average")
+	for _, c := range p.Categories {
+		fmt.Printf("%-18s %8.1f\n", c.Category, c.Average())
+	}
+	printVersus("Opponent", p.Opponents)
+	printVersus("Strategy", p.Strategies)
+}
+
+func printVersus(title string, rows []stats.Versus) {
+	if len(rows) == 0 {
+		return
+	}
+	fmt.Printf("\n%-18s %6s %6s %6s %9s\n", title, "Games", "Wins", "Losses", "Win rate")
+	for _, v := range rows {
+		fmt.Printf("%-18s %6d %6d %6d %8.1f%%\n", v.Name, v.Games, v.Wins, v.Losses, 100*v.WinRate())
+	}
+}
+
+func printStrategyResults(results []stats.StrategyResult) {
+	if len(results) == 0 {
+		return
+	}
+	fmt.Printf("\n=== AI strategies, all games ===\n")
+	fmt.Printf("%-18s %6s %6s %9s %10s\n", "Strategy", "Games", "Wins", "Win rate", "Avg score")
+	for _, r := range results {
+		fmt.Printf("%-18s %6d %6d %8.1f%% %10.1f\n", r.Strategy, r.Games, r.Wins, 100*r.WinRate(), r.AverageScore())
+	}
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"github.com/edge2992/yatzcli/p2p"
+)
+
+// addServerSecurityFlags adds --tls and --password to a command that
+// accepts players.
+func addServerSecurityFlags(cmd *cobra.Command) {
+	cmd.Flags().Bool("tls", false, "Encrypt connections with a self-signed certificate, generated on first use")
+	cmd.Flags().String("password", "", "Room password players must give to join")
+}
+
+// serverSecurityOptions resolves --tls and --password. With --tls it prints
+// the certificate fingerprint for players to pin.
+func serverSecurityOptions(cmd *cobra.Command) ([]p2p.Option, error) {
+	useTLS, _ := cmd.Flags().GetBool("tls")
+	password, _ := cmd.Flags().GetString("password")
+
+	var opts []p2p.Option
+	if password != "" {
+		opts = append(opts, p2p.WithPassword(password))
+	}
+	if !useTLS {
+		return opts, nil
+	}
+	dir, err := configPath("tls")
+	if err != nil {
+		return nil, err
+	}
+	cert, err := p2p.LoadOrCreateCertificate(dir)
+	if err != nil {
+		return nil, err
+	}
+	fmt.Printf("TLS fingerprint: %s\n", p2p.Fingerprint(cert))
+	return append(opts, p2p.WithTLS(p2p.ServerTLSConfig(cert))), nil
+}
+
+// addClientSecurityFlags adds --tls, --fingerprint and --password to a
+// command that connects to a server.
+func addClientSecurityFlags(cmd *cobra.Command) {
+	cmd.Flags().Bool("tls", false, "Connect over TLS; the server's certificate is trusted on first use unless --fingerprint is given")
+	cmd.Flags().String("fingerprint", "", "Only accept a TLS server with this SHA-256 certificate fingerprint (implies --tls)")
+	cmd.Flags().String("password", "", "Room password")
+}
+
+// clientSecurityOptions resolves --tls, --fingerprint and --password for a
+// connection to addr. Without --fingerprint, the fingerprint seen on the
+// first connection to addr is pinned in the known_hosts file.
+func clientSecurityOptions(cmd *cobra.Command, addr string) ([]p2p.Option, error) {
+	useTLS, _ := cmd.Flags().GetBool("tls")
+	fingerprint, _ := cmd.Flags().GetString("fingerprint")
+	password, _ := cmd.Flags().GetString("password")
+
+	var opts []p2p.Option
+	if password != "" {
+		opts = append(opts, p2p.WithPassword(password))
+	}
+	switch {
+	case fingerprint != "":
+		opts = append(opts, p2p.WithTLS(p2p.PinnedTLSConfig(fingerprint)))
+	case useTLS:
+		path, err := configPath("known_hosts")
+		if err != nil {
+			return nil, err
+		}
+		cfg := p2p.TrustOnFirstUseTLSConfig(path, addr, func(fp string) {
+			fmt.Printf("Trusting %s with fingerprint %s\n", addr, fp)
+		})
+		opts = append(opts, p2p.WithTLS(cfg))
+	}
+	return opts, nil
+}
+
+// configPath returns name under the yatzcli config directory.
+func configPath(name string) (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("locate config directory: %w", err)
+	}
+	return filepath.Join(dir, "yatzcli", name), nil
+}
@@ -0,0 +1,173 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/edge2992/yatzcli/engine"
+	"github.com/edge2992/yatzcli/tournament"
+)
+
+var tournamentCmd = &cobra.Command{
+	Use:   "tournament",
+	Short: "Rank AI strategies in a round-robin or Swiss tournament",
+	Long: `Play AI strategies against each other in many games at once and rank them.
+Every seed is played from both seats with the same dice for both players, so
+luck cancels out; results come with 95% confidence intervals.`,
+	RunE: runTournament,
+}
+
+func init() {
+	tournamentCmd.Flags().StringSlice("players", []string{"Greedy:greedy", "Statistical:statistical"}, `Entries in "Name:strategy" format (greedy, statistical, win, optimal, exec:./mybot, llm:persona.md)`)
+	tournamentCmd.Flags().String("format", string(tournament.RoundRobin), "Pairing format (round-robin, swiss)")
+	tournamentCmd.Flags().Int("games", tournament.DefaultGames, "Seeds per match; each is played from both seats")
+	tournamentCmd.Flags().Int("rounds", 0, "Swiss rounds (0 = enough to rank the entries)")
+	tournamentCmd.Flags().Int64("seed", 0, "First seed (0=random)")
+	tournamentCmd.Flags().Int("workers", 0, "Games played at once (0 = one per CPU)")
+	tournamentCmd.Flags().String("rules", "yahtzee", rulesFlagUsage())
+	tournamentCmd.Flags().Int("bin", 25, "Width of the score distribution bins")
+	tournamentCmd.Flags().String("csv", "", "Write every game to this CSV file")
+	tournamentCmd.Flags().String("json", "", "Write the games, standings and head-to-head records to this JSON file")
+	tournamentCmd.Flags().String("api-key", "", "Claude API key (or ANTHROPIC_API_KEY env)")
+	tournamentCmd.Flags().String("model", defaultModel, "Claude model for LLM strategy")
+}
+
+func runTournament(cmd *cobra.Command, args []string) error {
+	playerSpecs, _ := cmd.Flags().GetStringSlice("players")
+	format, _ := cmd.Flags().GetString("format")
+	games, _ := cmd.Flags().GetInt("games")
+	rounds, _ := cmd.Flags().GetInt("rounds")
+	seed, _ := cmd.Flags().GetInt64("seed")
+	workers, _ := cmd.Flags().GetInt("workers")
+	rulesName, _ := cmd.Flags().GetString("rules")
+	bin, _ := cmd.Flags().GetInt("bin")
+	csvPath, _ := cmd.Flags().GetString("csv")
+	jsonPath, _ := cmd.Flags().GetString("json")
+	apiKey, _ := cmd.Flags().GetString("api-key")
+	model, _ := cmd.Flags().GetString("model")
+
+	rules, err := engine.RuleSetByName(rulesName)
+	if err != nil {
+		return err
+	}
+	if apiKey == "" {
+		apiKey = os.Getenv("ANTHROPIC_API_KEY")
+	}
+	entries, err := parseBattlePlayers(playerSpecs, apiKey, model)
+	if err != nil {
+		return err
+	}
+	defer closeStrategies(entries)
+
+	played := 0
+	result, err := tournament.Run(tournament.Config{
+		Entries: entries,
+		Format:  tournament.Format(format),
+		Games:   games,
+		Rounds:  rounds,
+		Seed:    seed,
+		Workers: workers,
+		Rules:   rules,
+		OnGame: func(tournament.GameResult) {
+			played++
+			fmt.Fprintf(os.Stderr, "\rGames played: %d", played)
+		},
+	})
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return err
+	}
+
+	printStandings(result)
+	printHeadToHead(result)
+	printDistributions(result, bin)
+
+	if csvPath != "" {
+		if err := writeExport(csvPath, result.WriteCSV); err != nil {
+			return err
+		}
+	}
+	if jsonPath != "" {
+		if err := writeExport(jsonPath, result.WriteJSON); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func printStandings(r *tournament.Result) {
+	fmt.Printf("\n=== Tournament (%s, %s rules, %d games, seed %d) ===\n", r.Format, r.Rules, len(r.Games), r.Seed)
+	fmt.Printf("%-16s %6s %5s %5s %5s %7s %17s %7s %5s %5s\n", "Entry", "Games", "Wins", "Draws", "Losses", "Points", "Mean score (95%)", "Median", "Min", "Max")
+	for _, s := range r.Standings() {
+		fmt.Printf("%-16s %6d %5d %5d %5d %7.1f %9.1f ± %5.1f %7.1f %5d %5d\n",
+			s.Name, s.Games, s.Wins, s.Draws, s.Losses, s.Points, s.Mean, s.CI95, s.Median, s.Min, s.Max)
+	}
+}
+
+// printHeadToHead prints each row entry's win rate and mean score margin
+// against each column entry. A * marks a margin beyond luck at 95%.
+func printHeadToHead(r *tournament.Result) {
+	h2h := r.HeadToHead()
+	fmt.Printf("\n=== Head to head (win rate, mean margin; * = significant at 95%%) ===\n%-16s", "")
+	for _, name := range r.Entries {
+		fmt.Printf(" %16s", name)
+	}
+	fmt.Println()
+	for i, name := range r.Entries {
+		fmt.Printf("%-16s", name)
+		for j := range r.Entries {
+			rec := h2h[i][j]
+			if rec.Games == 0 {
+				fmt.Printf(" %16s", "-")
+				continue
+			}
+			mark := ""
+			if rec.Significant() {
+				mark = "*"
+			}
+			rate := (float64(rec.Wins) + float64(rec.Draws)/2) / float64(rec.Games)
+			fmt.Printf(" %16s", fmt.Sprintf("%3.0f%% %+6.1f%s", 100*rate, rec.MeanDiff, mark))
+		}
+		fmt.Println()
+	}
+}
+
+func printDistributions(r *tournament.Result, width int) {
+	if width <= 0 {
+		return
+	}
+	const barWidth = 40
+	fmt.Printf("\n=== Score distributions ===\n")
+	for _, name := range r.Entries {
+		bins := r.Distribution(name, width)
+		most := 0
+		for _, b := range bins {
+			most = max(most, b.Count)
+		}
+		fmt.Printf("%s\n", name)
+		for _, b := range bins {
+			bar := 0
+			if most > 0 {
+				bar = b.Count * barWidth / most
+			}
+			fmt.Printf("  %4d-%-4d %5d %s\n", b.Lo, b.Hi-1, b.Count, strings.Repeat("#", bar))
+		}
+	}
+}
+
+// writeExport creates path and writes it with write.
+func writeExport(path string, write func(w io.Writer) error) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := write(f); err != nil {
+		f.Close()
+		return fmt.Errorf("write %s: %w", path, err)
+	}
+	return f.Close()
+}
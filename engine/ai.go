@@ -22,12 +22,21 @@ func NewAIPlayerWithStrategy(game *Game, playerID string, strategy Strategy) *AI
 	return &AIPlayer{game: game, playerID: playerID, strategy: strategy}
 }
 
+// Strategy returns the strategy the AI plays with.
+func (ai *AIPlayer) Strategy() Strategy {
+	return ai.strategy
+}
+
+// PlayTurn plays the rest of the current turn, which must be the AI's.
 func (ai *AIPlayer) PlayTurn() (AITurnResult, error) {
 	if ai.game.Players[ai.game.Current].ID != ai.playerID {
 		return AITurnResult{}, errors.New("not AI's turn")
 	}
-	if err := ai.game.Roll(); err != nil {
-		return AITurnResult{}, err
+	// A turn taken over mid-way (e.g. after a timeout) keeps its dice.
+	if ai.game.RollCount == 0 {
+		if err := ai.game.Roll(); err != nil {
+			return AITurnResult{}, err
+		}
 	}
 
 	playerName := ai.game.Players[ai.game.Current].Name
@@ -35,8 +44,8 @@ func (ai *AIPlayer) PlayTurn() (AITurnResult, error) {
 	var holdHistory []HoldStep
 
 	for {
-		available := scorecard.AvailableCategories()
-		action := ai.strategy.DecideAction(ai.game.Dice, ai.game.RollCount, scorecard, available)
+		available := ai.game.GetAvailableCategories()
+		action := ai.decide(scorecard, available)
 
 		if action.Type == "hold" && ai.game.RollCount < MaxRolls {
 			holdHistory = append(holdHistory, HoldStep{
@@ -53,10 +62,14 @@ func (ai *AIPlayer) PlayTurn() (AITurnResult, error) {
 		// fall back to best available category.
 		dice := ai.game.Dice
 		category := action.Category
-		if action.Type != "score" || category == "" {
-			category = bestCategoryForDice(dice, available)
+		if action.Type != "score" || !containsCategory(available, category) {
+			category = bestCategoryForDice(dice, available, scorecard)
+		}
+		score := ScoreFor(category, dice, scorecard)
+		bonus := 0
+		if EarnsYahtzeeBonus(dice, scorecard) {
+			bonus = scorecard.Rules().YahtzeeBonus()
 		}
-		score := CalcScore(category, dice)
 		if err := ai.game.Score(category); err != nil {
 			return AITurnResult{}, err
 		}
@@ -65,8 +78,35 @@ func (ai *AIPlayer) PlayTurn() (AITurnResult, error) {
 			Dice:         dice,
 			Category:     category,
 			Score:        score,
+			YahtzeeBonus: bonus,
 			StrategyName: ai.strategy.Name(),
 			HoldHistory:  holdHistory,
 		}, nil
 	}
 }
+
+// decide asks the strategy for its next action, with the whole game as
+// context if it is a ContextStrategy.
+func (ai *AIPlayer) decide(scorecard Scorecard, available []Category) TurnAction {
+	cs, ok := ai.strategy.(ContextStrategy)
+	if !ok {
+		return ai.strategy.DecideAction(ai.game.Dice, ai.game.RollCount, scorecard, available)
+	}
+	g := ai.game
+	ctx := DecisionContext{
+		Dice:      g.Dice,
+		RollCount: g.RollCount,
+		Scorecard: scorecard,
+		Available: available,
+		TurnsLeft: len(scorecard.AvailableCategories()),
+	}
+	for i := 1; i < len(g.Players); i++ {
+		p := g.Players[(g.Current+i)%len(g.Players)]
+		ctx.Opponents = append(ctx.Opponents, Opponent{
+			Name:      p.Name,
+			Scorecard: p.Scorecard.clone(),
+			TurnsLeft: len(p.Scorecard.AvailableCategories()),
+		})
+	}
+	return cs.DecideWithContext(ctx)
+}
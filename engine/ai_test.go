@@ -62,3 +62,66 @@ func TestAIPlayer_PlayTurn_NotMyTurn(t *testing.T) {
 		t.Error("expected error when not AI's turn, got nil")
 	}
 }
+
+func TestAIPlayer_PlayTurn_MidTurn(t *testing.T) {
+	g := NewGame([]string{"AI", "Human"}, rand.NewSource(42))
+	ai := NewAIPlayer(g, "player-0")
+
+	// The turn was started by someone else: rolled twice already.
+	if err := g.Roll(); err != nil {
+		t.Fatalf("Roll() failed: %v", err)
+	}
+	if err := g.Hold([]int{0, 1}); err != nil {
+		t.Fatalf("Hold() failed: %v", err)
+	}
+
+	if _, err := ai.PlayTurn(); err != nil {
+		t.Fatalf("PlayTurn() mid-turn failed: %v", err)
+	}
+	if g.Players[g.Current].ID != "player-1" {
+		t.Errorf("expected turn to pass to player-1, got %s", g.Players[g.Current].ID)
+	}
+}
+
+// contextRecorder scores greedily and records the contexts it decides in.
+type contextRecorder struct {
+	GreedyStrategy
+	contexts []DecisionContext
+}
+
+func (r *contextRecorder) DecideWithContext(ctx DecisionContext) TurnAction {
+	r.contexts = append(r.contexts, ctx)
+	return r.DecideAction(ctx.Dice, ctx.RollCount, ctx.Scorecard, ctx.Available)
+}
+
+func TestAIPlayer_PlayTurn_DecisionContext(t *testing.T) {
+	g := NewGame([]string{"A", "AI", "C"}, rand.NewSource(42))
+	if err := g.Roll(); err != nil {
+		t.Fatalf("Roll() failed: %v", err)
+	}
+	if err := g.Score(Chance); err != nil {
+		t.Fatalf("Score() failed: %v", err)
+	}
+
+	rec := &contextRecorder{}
+	if _, err := NewAIPlayerWithStrategy(g, "player-1", rec).PlayTurn(); err != nil {
+		t.Fatalf("PlayTurn() failed: %v", err)
+	}
+	if len(rec.contexts) != 1 {
+		t.Fatalf("expected 1 decision, got %d", len(rec.contexts))
+	}
+	ctx := rec.contexts[0]
+	if ctx.RollCount != 1 || ctx.TurnsLeft != 13 || len(ctx.Available) != 13 {
+		t.Errorf("unexpected context: roll %d, %d turns left, %d available", ctx.RollCount, ctx.TurnsLeft, len(ctx.Available))
+	}
+	if len(ctx.Opponents) != 2 {
+		t.Fatalf("expected 2 opponents, got %d", len(ctx.Opponents))
+	}
+	// Opponents come in turn order from the AI: C, then A.
+	if ctx.Opponents[0].Name != "C" || ctx.Opponents[0].TurnsLeft != 13 {
+		t.Errorf("unexpected first opponent %s with %d turns left", ctx.Opponents[0].Name, ctx.Opponents[0].TurnsLeft)
+	}
+	if ctx.Opponents[1].Name != "A" || ctx.Opponents[1].TurnsLeft != 12 || !ctx.Opponents[1].Scorecard.IsFilled(Chance) {
+		t.Errorf("unexpected second opponent %s with %d turns left", ctx.Opponents[1].Name, ctx.Opponents[1].TurnsLeft)
+	}
+}
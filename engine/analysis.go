@@ -0,0 +1,323 @@
+package engine
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+)
+
+// ActionValue is a possible action at a decision point and its value.
+type ActionValue struct {
+	Action TurnAction
+	Value  float64
+}
+
+// DecisionEvaluator values every legal action at a decision point. Values are
+// only compared with each other, so any consistent unit works.
+type DecisionEvaluator interface {
+	Name() string
+	Evaluate(dice [5]int, rollCount int, scorecard Scorecard) []ActionValue
+}
+
+// OptimalEvaluator values actions by expected final score under optimal
+// solitaire play. Without a table, or under rules other than YahtzeeRules,
+// it falls back to StatisticalEvaluator.
+type OptimalEvaluator struct {
+	Table *OptimalTable
+}
+
+func (e *OptimalEvaluator) Name() string { return "optimal" }
+
+func (e *OptimalEvaluator) Evaluate(dice [5]int, rollCount int, scorecard Scorecard) []ActionValue {
+	if e.Table == nil || scorecard.Rules().Name() != DefaultRules().Name() {
+		return (&StatisticalEvaluator{}).Evaluate(dice, rollCount, scorecard)
+	}
+
+	mask, upper, y50 := optimalState(scorecard)
+	r := rollIndex(dice)
+	var out []ActionValue
+
+	legal := legalPlacements(mask, r)
+	for c := 0; c < numCategories; c++ {
+		if legal&(1<<c) == 0 {
+			continue
+		}
+		reward, nmask, nupper, ny50 := placement(mask, upper, y50, r, c)
+		out = append(out, ActionValue{
+			Action: TurnAction{Type: "score", Category: AllCategories[c]},
+			Value:  float64(reward) + e.Table.value(nmask, nupper, ny50),
+		})
+	}
+
+	if rollCount < MaxRolls {
+		ts := newTurnSolver(e.Table)
+		ts.fillFinal(mask, upper, y50)
+		keepExpectations(ts.final, ts.keepEV)
+		if MaxRolls-rollCount > 1 {
+			bestKeepValues(ts.keepEV, ts.roll)
+			keepExpectations(ts.roll, ts.keepEV)
+		}
+		full := multisets.keepIndexByKey[multisets.rolls[r].key()]
+		for _, k := range multisets.rollSubKeeps[r] {
+			if k == full {
+				continue
+			}
+			out = append(out, ActionValue{
+				Action: TurnAction{Type: "hold", Indices: holdIndicesForKeep(dice, multisets.keeps[k])},
+				Value:  ts.keepEV[k],
+			})
+		}
+		out = append(out, holdAllAction(out))
+	}
+	return out
+}
+
+// holdAllAction is the hold of all five dice, which rerolls nothing. It is
+// valued as the best score among out, where the turn still ends.
+func holdAllAction(out []ActionValue) ActionValue {
+	best := ActionValue{Action: TurnAction{Type: "hold", Indices: []int{0, 1, 2, 3, 4}}, Value: math.Inf(-1)}
+	for _, o := range out {
+		if o.Action.Type == "score" && o.Value > best.Value {
+			best.Value = o.Value
+		}
+	}
+	return best
+}
+
+// EvaluatorFor returns the evaluator matching how strategy values its own
+// choices: an OptimalStrategy's table, a StatisticalStrategy's depth and
+// category values, or a default StatisticalEvaluator otherwise.
+func EvaluatorFor(strategy Strategy) DecisionEvaluator {
+	switch s := strategy.(type) {
+	case *OptimalStrategy:
+		if s.Table != nil {
+			return &OptimalEvaluator{Table: s.Table}
+		}
+	case *StatisticalStrategy:
+		return &StatisticalEvaluator{Depth: s.Depth, Value: s.Value}
+	}
+	return &StatisticalEvaluator{}
+}
+
+// StatisticalEvaluator values actions the way a StatisticalStrategy with the
+// same Depth and Value does: scoring by the category's value and holds by
+// the expected value of the best play over the rerolls they plan for.
+type StatisticalEvaluator struct {
+	Depth int
+	Value CategoryValue
+}
+
+func (e *StatisticalEvaluator) Name() string { return "statistical" }
+
+func (e *StatisticalEvaluator) Evaluate(dice [5]int, rollCount int, scorecard Scorecard) []ActionValue {
+	available := PlaceableCategories(dice, scorecard)
+	var out []ActionValue
+	for _, c := range available {
+		value := float64(ScoreFor(c, dice, scorecard))
+		if e.Value != nil {
+			value = e.Value(c, dice, scorecard)
+		}
+		out = append(out, ActionValue{
+			Action: TurnAction{Type: "score", Category: c},
+			Value:  value,
+		})
+	}
+	if rollCount >= MaxRolls {
+		return out
+	}
+
+	rerolls := MaxRolls - rollCount
+	if e.Depth > 0 {
+		rerolls = min(rerolls, e.Depth)
+	}
+	var t evTable
+	t.reset(available, scorecard, e.Value, rerolls)
+	bonus := 0.0
+	if e.Value == nil {
+		bonus = upperBonusIncentive(scorecard)
+	}
+	seen := make(map[int]bool)
+	for _, hold := range holdCombinations() {
+		if len(hold) == 5 {
+			continue
+		}
+		key := keepKey(dice, hold)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		out = append(out, ActionValue{
+			Action: TurnAction{Type: "hold", Indices: hold},
+			Value:  t.keepValue(multisets.keepIndexByKey[key]) + bonus,
+		})
+	}
+	return append(out, holdAllAction(out))
+}
+
+// keepKey identifies the multiset of dice values held by hold.
+func keepKey(dice [5]int, hold []int) int {
+	var fc faceCounts
+	held := 0
+	for _, i := range hold {
+		if held&(1<<i) == 0 {
+			held |= 1 << i
+			fc[dice[i]-1]++
+		}
+	}
+	return fc.key()
+}
+
+// accurateLoss is the largest loss still counted as the best decision.
+const accurateLoss = 0.01
+
+// Decision is one graded hold or score choice.
+type Decision struct {
+	Seq        int // event sequence number
+	Player     string
+	PlayerName string
+	Round      int
+	RollCount  int // rolls made before the decision
+	Dice       [5]int
+	Chosen     TurnAction
+	ChosenEV   float64
+	Best       TurnAction
+	BestEV     float64
+	Loss       float64 // BestEV - ChosenEV, never negative
+}
+
+// PlayerAccuracy summarizes one player's decisions.
+type PlayerAccuracy struct {
+	Player    string
+	Name      string
+	Decisions int
+	Best      int     // decisions within accurateLoss of the best
+	TotalLoss float64 // summed expected-value loss
+}
+
+// Accuracy returns the percentage of decisions that matched the best choice.
+func (p PlayerAccuracy) Accuracy() float64 {
+	if p.Decisions == 0 {
+		return 100
+	}
+	return 100 * float64(p.Best) / float64(p.Decisions)
+}
+
+// GameAnalysis grades every decision in a recorded game.
+type GameAnalysis struct {
+	Evaluator string
+	Decisions []Decision
+	Players   []PlayerAccuracy
+}
+
+// AnalyzeGame verifies an event log with Replay and grades each hold and
+// score decision against the best alternative according to ev.
+func AnalyzeGame(events []Event, ev DecisionEvaluator) (*GameAnalysis, error) {
+	if ev == nil {
+		return nil, errors.New("analyze: no evaluator")
+	}
+	states, err := Replay(events)
+	if err != nil {
+		return nil, err
+	}
+
+	a := &GameAnalysis{Evaluator: ev.Name()}
+	index := make(map[string]int)
+	for _, p := range states[0].Players {
+		index[p.ID] = len(a.Players)
+		a.Players = append(a.Players, PlayerAccuracy{Player: p.ID, Name: p.Name})
+	}
+
+	for i := 1; i < len(events); i++ {
+		e := events[i]
+		var chosen TurnAction
+		switch e.Type {
+		case EventHold:
+			chosen = TurnAction{Type: "hold", Indices: e.Held}
+		case EventScore:
+			chosen = TurnAction{Type: "score", Category: e.Category}
+		default:
+			continue
+		}
+
+		before := states[i-1]
+		sc := before.Players[before.CurrentPlayerIndex].Scorecard
+		d, err := gradeDecision(ev, before.Dice, before.RollCount, sc, chosen)
+		if err != nil {
+			return nil, fmt.Errorf("event %d: %w", e.Seq, err)
+		}
+		d.Seq = e.Seq
+		d.Player = e.Player
+		d.PlayerName = before.Players[before.CurrentPlayerIndex].Name
+		d.Round = before.Round
+		a.Decisions = append(a.Decisions, d)
+
+		p := &a.Players[index[e.Player]]
+		p.Decisions++
+		p.TotalLoss += d.Loss
+		if d.Loss <= accurateLoss {
+			p.Best++
+		}
+	}
+	return a, nil
+}
+
+func gradeDecision(ev DecisionEvaluator, dice [5]int, rollCount int, sc Scorecard, chosen TurnAction) (Decision, error) {
+	options := ev.Evaluate(dice, rollCount, sc)
+	if len(options) == 0 {
+		return Decision{}, errors.New("no legal actions to grade")
+	}
+
+	d := Decision{RollCount: rollCount, Dice: dice, Chosen: chosen}
+	bestIdx, chosenIdx := 0, -1
+	for i, o := range options {
+		if o.Value > options[bestIdx].Value {
+			bestIdx = i
+		}
+		if sameAction(dice, o.Action, chosen) {
+			chosenIdx = i
+		}
+	}
+	if chosenIdx < 0 {
+		return Decision{}, fmt.Errorf("%s is not a legal action", describeAction(chosen))
+	}
+	d.Best, d.BestEV = options[bestIdx].Action, options[bestIdx].Value
+	d.ChosenEV = options[chosenIdx].Value
+	d.Loss = max(d.BestEV-d.ChosenEV, 0)
+	return d, nil
+}
+
+// sameAction reports whether a and b are equivalent; holds are compared by
+// the dice values kept, not their positions.
+func sameAction(dice [5]int, a, b TurnAction) bool {
+	if a.Type != b.Type {
+		return false
+	}
+	if a.Type == "score" {
+		return a.Category == b.Category
+	}
+	return keepKey(dice, a.Indices) == keepKey(dice, b.Indices)
+}
+
+func describeAction(a TurnAction) string {
+	if a.Type == "score" {
+		return fmt.Sprintf("score %s", a.Category)
+	}
+	return fmt.Sprintf("hold %v", a.Indices)
+}
+
+// WorstMistakes returns up to n decisions with the largest loss, worst first.
+// Decisions within accurateLoss of the best are never included.
+func (a *GameAnalysis) WorstMistakes(n int) []Decision {
+	var mistakes []Decision
+	for _, d := range a.Decisions {
+		if d.Loss > accurateLoss {
+			mistakes = append(mistakes, d)
+		}
+	}
+	sort.SliceStable(mistakes, func(i, j int) bool { return mistakes[i].Loss > mistakes[j].Loss })
+	if len(mistakes) > n {
+		mistakes = mistakes[:n]
+	}
+	return mistakes
+}
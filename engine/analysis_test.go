@@ -0,0 +1,120 @@
+package engine
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAnalyzeGame(t *testing.T) {
+	events := recordBattle(t, 21)
+
+	a, err := AnalyzeGame(events, &StatisticalEvaluator{})
+	require.NoError(t, err)
+	assert.Equal(t, "statistical", a.Evaluator)
+	require.Len(t, a.Players, 2)
+
+	// Greedy scores immediately every turn: one decision per round.
+	greedy := a.Players[0]
+	assert.Equal(t, "G", greedy.Name)
+	assert.Equal(t, 13, greedy.Decisions)
+	assert.Greater(t, greedy.TotalLoss, a.Players[1].TotalLoss)
+	assert.Less(t, greedy.Accuracy(), a.Players[1].Accuracy())
+
+	for _, d := range a.Decisions {
+		assert.GreaterOrEqual(t, d.Loss, 0.0)
+		assert.InDelta(t, d.BestEV-d.ChosenEV, d.Loss, 1e-9)
+	}
+
+	worst := a.WorstMistakes(3)
+	require.Len(t, worst, 3)
+	assert.GreaterOrEqual(t, worst[0].Loss, worst[1].Loss)
+	assert.GreaterOrEqual(t, worst[1].Loss, worst[2].Loss)
+}
+
+func TestAnalyzeGame_RejectsInvalidLog(t *testing.T) {
+	events := recordBattle(t, 22)
+	_, err := AnalyzeGame(events[1:], &StatisticalEvaluator{})
+	assert.Error(t, err)
+}
+
+func TestAnalyzeGame_HoldAll(t *testing.T) {
+	g := NewGame([]string{"A"}, NewSeededSource(4))
+	var events []Event
+	g.OnEvent(func(e Event) { events = append(events, e) })
+	require.NoError(t, g.Roll())
+	require.NoError(t, g.Hold([]int{0, 1, 2, 3, 4}))
+	require.NoError(t, g.Score(Chance))
+
+	for _, ev := range []DecisionEvaluator{&StatisticalEvaluator{}, &OptimalEvaluator{Table: solveOptimalTable(numCategories - 1)}} {
+		t.Run(ev.Name(), func(t *testing.T) {
+			a, err := AnalyzeGame(events, ev)
+			require.NoError(t, err)
+			require.Len(t, a.Decisions, 2)
+			hold := a.Decisions[0]
+			assert.Equal(t, []int{0, 1, 2, 3, 4}, hold.Chosen.Indices)
+			assert.Greater(t, hold.Loss, 0.0)
+			assert.Equal(t, "hold", hold.Best.Type)
+		})
+	}
+}
+
+func TestGradeDecision_Optimal(t *testing.T) {
+	ev := &OptimalEvaluator{Table: solveOptimalTable(numCategories - 1)}
+	sc := scorecardWithOpen(Chance)
+	dice := [5]int{6, 4, 5, 1, 3}
+
+	d, err := gradeDecision(ev, dice, 1, sc, TurnAction{Type: "score", Category: Chance})
+	require.NoError(t, err)
+	assert.Equal(t, "hold", d.Best.Type)
+	assert.Equal(t, []int{0, 2}, d.Best.Indices)
+	assert.Greater(t, d.Loss, 1.0)
+
+	// Holds are matched by dice values, whatever their order.
+	d, err = gradeDecision(ev, dice, 1, sc, TurnAction{Type: "hold", Indices: []int{2, 0}})
+	require.NoError(t, err)
+	assert.InDelta(t, 0, d.Loss, 1e-9)
+
+	_, err = gradeDecision(ev, dice, 1, sc, TurnAction{Type: "score", Category: Yahtzee})
+	assert.Error(t, err)
+}
+
+func TestOptimalEvaluator_FallsBackForOtherRules(t *testing.T) {
+	ev := &OptimalEvaluator{}
+	sc := NewScorecardWithRules(&KniffelRules{})
+	options := ev.Evaluate([5]int{1, 2, 3, 4, 5}, 3, sc)
+	assert.Len(t, options, 13)
+}
+
+func TestEvaluatorFor(t *testing.T) {
+	assert.Equal(t, "statistical", EvaluatorFor(&GreedyStrategy{}).Name())
+	assert.Equal(t, "statistical", EvaluatorFor(&OptimalStrategy{}).Name())
+	assert.Equal(t, "optimal", EvaluatorFor(&OptimalStrategy{Table: &OptimalTable{}}).Name())
+	assert.Equal(t, &StatisticalEvaluator{Depth: 1}, EvaluatorFor(&StatisticalStrategy{Depth: 1}))
+}
+
+func TestStatisticalEvaluator_MatchesStrategy(t *testing.T) {
+	// The evaluator's best action is the strategy's choice, up to ties.
+	// The strategy never rerolls all five dice, so states where that is
+	// best are left out.
+	table := solveOptimalTable(numCategories - 1)
+	strategies := []*StatisticalStrategy{{}, {Depth: 1}, {Value: table.CategoryValue}}
+	rng := rand.New(rand.NewSource(6))
+	for range 30 {
+		sc := scorecardWithOpen(AllCategories[rng.Intn(numCategories)], AllCategories[rng.Intn(numCategories)])
+		dice := randomDice(rng)
+		avail := PlaceableCategories(dice, sc)
+		for _, s := range strategies {
+			for rollCount := 1; rollCount <= MaxRolls; rollCount++ {
+				d, err := gradeDecision(EvaluatorFor(s), dice, rollCount, sc, s.DecideAction(dice, rollCount, sc, avail))
+				require.NoError(t, err)
+				if d.Best.Type == "hold" && len(d.Best.Indices) == 0 {
+					continue
+				}
+				assert.InDelta(t, 0, d.Loss, 1e-9, "depth %d, dice %v, roll %d", s.Depth, dice, rollCount)
+			}
+		}
+	}
+}
@@ -16,7 +16,10 @@ type BattlePlayer struct {
 type BattleConfig struct {
 	Players    []BattlePlayer
 	Seed       int64
+	Dice       DiceSource // if set, rolls the dice instead of Seed
+	Rules      RuleSet    // nil uses DefaultRules
 	OnTurnDone func(result AITurnResult)
+	OnEvent    func(e Event) // receives the game's event stream, see Game.OnEvent
 }
 
 // BattleResult holds the final results of a battle.
@@ -37,10 +40,12 @@ func RunBattle(cfg BattleConfig) (*GameState, error) {
 	}
 
 	var src rand.Source
-	if cfg.Seed != 0 {
-		src = rand.NewSource(cfg.Seed)
+	if cfg.Dice != nil {
+		src = cfg.Dice
+	} else if cfg.Seed != 0 {
+		src = NewSeededSource(cfg.Seed)
 	} else {
-		src = rand.NewSource(time.Now().UnixNano())
+		src = NewSeededSource(time.Now().UnixNano())
 	}
 
 	names := make([]string, len(cfg.Players))
@@ -48,7 +53,10 @@ func RunBattle(cfg BattleConfig) (*GameState, error) {
 		names[i] = p.Name
 	}
 
-	game := NewGame(names, src)
+	game := NewGameWithRules(names, src, cfg.Rules)
+	if cfg.OnEvent != nil {
+		game.OnEvent(cfg.OnEvent)
+	}
 
 	ais := make([]*AIPlayer, len(cfg.Players))
 	for i, p := range cfg.Players {
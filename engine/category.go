@@ -37,3 +37,4 @@ func IsValidCategory(c Category) bool {
 
 const UpperBonusThreshold = 63
 const UpperBonusValue = 35
+const YahtzeeBonusValue = 100
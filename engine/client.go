@@ -12,6 +12,7 @@ type AITurnResult struct {
 	Dice         [5]int
 	Category     Category
 	Score        int
+	YahtzeeBonus int // bonus points awarded for an extra Yahtzee this turn
 	StrategyName string
 	HoldHistory  []HoldStep
 }
@@ -2,6 +2,17 @@ package engine
 
 import "math/rand"
 
+// DiceSource is a rand.Source that decides each roll's dice from the roll's
+// place in the game instead of its next values, so anyone who knows how it
+// derives them can check every roll. A game whose source is a DiceSource
+// rolls with DiceFor.
+type DiceSource interface {
+	rand.Source
+	// DiceFor returns the dice of roll (1 to MaxRolls) of the turn of the
+	// player at index player in round. Held dice keep their old value.
+	DiceFor(round, player, roll int) [5]int
+}
+
 func RollAll(src rand.Source) [5]int {
 	r := rand.New(src)
 	var dice [5]int
@@ -11,6 +22,15 @@ func RollAll(src rand.Source) [5]int {
 	return dice
 }
 
+// keepHeld returns fresh with the dice at the indices in hold kept from
+// dice.
+func keepHeld(dice, fresh [5]int, hold []int) [5]int {
+	for _, i := range hold {
+		fresh[i] = dice[i]
+	}
+	return fresh
+}
+
 func Reroll(dice [5]int, hold []int, src rand.Source) [5]int {
 	holdSet := make(map[int]bool)
 	for _, i := range hold {
@@ -27,3 +47,45 @@ func Reroll(dice [5]int, hold []int, src rand.Source) [5]int {
 	}
 	return result
 }
+
+// PairedDice is a DiceSource that gives every player the same dice: a
+// roll's dice depend only on the seed, the round and the roll number. Two
+// strategies playing the same seed face the same luck, so comparing their
+// scores measures their play.
+type PairedDice struct {
+	seed uint64
+	n    uint64 // values drawn through Int63
+}
+
+// NewPairedDice returns the PairedDice for seed.
+func NewPairedDice(seed int64) *PairedDice {
+	return &PairedDice{seed: uint64(seed)}
+}
+
+func (p *PairedDice) DiceFor(round, player, roll int) [5]int {
+	x := p.seed ^ uint64(round)<<32 ^ uint64(roll)<<16
+	var dice [5]int
+	for i := range dice {
+		x = splitmix64(x)
+		dice[i] = int(x%6) + 1
+	}
+	return dice
+}
+
+func (p *PairedDice) Int63() int64 {
+	p.n++
+	return int64(splitmix64(p.seed^p.n) >> 1)
+}
+
+func (p *PairedDice) Seed(seed int64) {
+	p.seed, p.n = uint64(seed), 0
+}
+
+// splitmix64 is one step of the SplitMix64 generator: it scrambles x into
+// a well-mixed 64-bit value.
+func splitmix64(x uint64) uint64 {
+	x += 0x9e3779b97f4a7c15
+	x = (x ^ x>>30) * 0xbf58476d1ce4e5b9
+	x = (x ^ x>>27) * 0x94d049bb133111eb
+	return x ^ x>>31
+}
@@ -58,3 +58,31 @@ func TestReroll_HoldNone(t *testing.T) {
 		}
 	}
 }
+
+func TestPairedDice(t *testing.T) {
+	p := NewPairedDice(7)
+	counts := make(map[int]int)
+	for round := 1; round <= 13; round++ {
+		for roll := 1; roll <= MaxRolls; roll++ {
+			dice := p.DiceFor(round, 0, roll)
+			if other := p.DiceFor(round, 1, roll); other != dice {
+				t.Fatalf("round %d roll %d: players got %v and %v", round, roll, dice, other)
+			}
+			for _, d := range dice {
+				if d < 1 || d > 6 {
+					t.Fatalf("die %d out of range", d)
+				}
+				counts[d]++
+			}
+		}
+	}
+	if len(counts) != 6 {
+		t.Errorf("faces rolled: %v", counts)
+	}
+	if NewPairedDice(7).DiceFor(3, 0, 2) != p.DiceFor(3, 0, 2) {
+		t.Error("same seed should give the same dice")
+	}
+	if NewPairedDice(8).DiceFor(1, 0, 1) == p.DiceFor(1, 0, 1) && NewPairedDice(8).DiceFor(2, 0, 1) == p.DiceFor(2, 0, 1) {
+		t.Error("different seeds gave the same dice")
+	}
+}
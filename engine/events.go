@@ -0,0 +1,192 @@
+package engine
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// EventType identifies a game event.
+type EventType string
+
+const (
+	EventGameStart   EventType = "game_start"
+	EventRoll        EventType = "roll"
+	EventHold        EventType = "hold"
+	EventScore       EventType = "score"
+	EventTurnAdvance EventType = "turn_advance"
+	EventGameOver    EventType = "game_over"
+)
+
+// Event is one entry in a game's event stream. Which fields are set depends
+// on Type:
+//   - game_start: Snapshot of the game when recording began
+//   - roll, hold: Player, Round, Dice after the roll; hold also sets Held
+//   - score: Player, Round, Dice, Category, Score and YahtzeeBonus points
+//   - turn_advance: Player and Round of the next turn
+//   - game_over: Totals in player order
+type Event struct {
+	Seq          int       `json:"seq"`
+	Type         EventType `json:"type"`
+	Player       string    `json:"player,omitempty"`
+	Round        int       `json:"round,omitempty"`
+	Dice         [5]int    `json:"dice"`
+	Held         []int     `json:"held,omitempty"`
+	Category     Category  `json:"category,omitempty"`
+	Score        int       `json:"score,omitempty"`
+	YahtzeeBonus int       `json:"yahtzee_bonus,omitempty"`
+	Totals       []int     `json:"totals,omitempty"`
+	Snapshot     *Snapshot `json:"snapshot,omitempty"`
+}
+
+// OnEvent registers fn to receive the game's events. fn is first called with
+// a game_start event describing the current state, so the events it receives
+// can be replayed on their own.
+func (g *Game) OnEvent(fn func(Event)) {
+	snap := g.Snapshot()
+	fn(Event{Type: EventGameStart, Dice: g.Dice, Snapshot: &snap})
+	g.listeners = append(g.listeners, fn)
+}
+
+func (g *Game) emit(e Event) {
+	for _, fn := range g.listeners {
+		fn(e)
+	}
+}
+
+// EventLog writes events as JSON lines, numbering them in order.
+type EventLog struct {
+	enc *json.Encoder
+	seq int
+	err error
+}
+
+func NewEventLog(w io.Writer) *EventLog {
+	return &EventLog{enc: json.NewEncoder(w)}
+}
+
+// Record writes e. It can be passed directly to Game.OnEvent; the first
+// write error is kept and reported by Err.
+func (l *EventLog) Record(e Event) {
+	if l.err != nil {
+		return
+	}
+	e.Seq = l.seq
+	l.seq++
+	if err := l.enc.Encode(e); err != nil {
+		l.err = fmt.Errorf("write event %d: %w", e.Seq, err)
+	}
+}
+
+// Err returns the first error encountered by Record.
+func (l *EventLog) Err() error {
+	return l.err
+}
+
+// ReadEvents reads a JSON lines event log.
+func ReadEvents(r io.Reader) ([]Event, error) {
+	dec := json.NewDecoder(r)
+	var events []Event
+	for {
+		var e Event
+		err := dec.Decode(&e)
+		if err == io.EOF {
+			return events, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("read event %d: %w", len(events), err)
+		}
+		events = append(events, e)
+	}
+}
+
+// Replay re-applies events on a fresh game restored from the leading
+// game_start event and verifies that every event reproduces what was
+// recorded. It returns the game state after each event.
+func Replay(events []Event) ([]GameState, error) {
+	if len(events) == 0 || events[0].Type != EventGameStart || events[0].Snapshot == nil {
+		return nil, errors.New("event log must begin with a game_start snapshot")
+	}
+	if events[0].Snapshot.RNG == nil {
+		return nil, errors.New("event log has no RNG state; dice cannot be reproduced")
+	}
+	g, err := Restore(*events[0].Snapshot)
+	if err != nil {
+		return nil, fmt.Errorf("event 0: %w", err)
+	}
+
+	states := []GameState{g.GetState()}
+	for i, e := range events[1:] {
+		if err := applyEvent(g, e); err != nil {
+			return states, fmt.Errorf("event %d (%s): %w", i+1, e.Type, err)
+		}
+		states = append(states, g.GetState())
+	}
+	return states, nil
+}
+
+func applyEvent(g *Game, e Event) error {
+	current := g.Players[g.Current]
+	switch e.Type {
+	case EventRoll, EventHold, EventScore:
+		if e.Player != current.ID {
+			return fmt.Errorf("expected %s to act, got %s", current.ID, e.Player)
+		}
+	}
+
+	switch e.Type {
+	case EventRoll:
+		if err := g.Roll(); err != nil {
+			return err
+		}
+		return checkDice(g, e)
+	case EventHold:
+		if err := g.Hold(e.Held); err != nil {
+			return err
+		}
+		return checkDice(g, e)
+	case EventScore:
+		if err := checkDice(g, e); err != nil {
+			return err
+		}
+		sc := current.Scorecard
+		bonus := 0
+		if EarnsYahtzeeBonus(g.Dice, sc) {
+			bonus = sc.Rules().YahtzeeBonus()
+		}
+		score := ScoreFor(e.Category, g.Dice, sc)
+		if err := g.Score(e.Category); err != nil {
+			return err
+		}
+		if score != e.Score || bonus != e.YahtzeeBonus {
+			return fmt.Errorf("recorded %d (+%d bonus), replay scored %d (+%d bonus)", e.Score, e.YahtzeeBonus, score, bonus)
+		}
+		return nil
+	case EventTurnAdvance:
+		if g.Phase == PhaseFinished || g.Players[g.Current].ID != e.Player || g.Round != e.Round {
+			return fmt.Errorf("recorded turn of %s in round %d, replay is at %s in round %d",
+				e.Player, e.Round, g.Players[g.Current].ID, g.Round)
+		}
+		return nil
+	case EventGameOver:
+		if g.Phase != PhaseFinished {
+			return errors.New("game is not finished")
+		}
+		for i, p := range g.Players {
+			if i >= len(e.Totals) || e.Totals[i] != p.Scorecard.Total() {
+				return fmt.Errorf("recorded totals %v differ for %s (%d)", e.Totals, p.ID, p.Scorecard.Total())
+			}
+		}
+		return nil
+	default:
+		return fmt.Errorf("unexpected event type %q", e.Type)
+	}
+}
+
+func checkDice(g *Game, e Event) error {
+	if g.Dice != e.Dice {
+		return fmt.Errorf("recorded dice %v, replay rolled %v", e.Dice, g.Dice)
+	}
+	return nil
+}
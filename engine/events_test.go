@@ -0,0 +1,104 @@
+package engine
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func recordBattle(t *testing.T, seed int64) []Event {
+	t.Helper()
+	var buf bytes.Buffer
+	log := NewEventLog(&buf)
+	_, err := RunBattle(BattleConfig{
+		Players: []BattlePlayer{
+			{Name: "G", Strategy: &GreedyStrategy{}},
+			{Name: "S", Strategy: &StatisticalStrategy{}},
+		},
+		Seed:    seed,
+		OnEvent: log.Record,
+	})
+	require.NoError(t, err)
+	require.NoError(t, log.Err())
+
+	events, err := ReadEvents(&buf)
+	require.NoError(t, err)
+	return events
+}
+
+func TestEvents_RecordAndReplay(t *testing.T) {
+	events := recordBattle(t, 11)
+
+	require.NotEmpty(t, events)
+	assert.Equal(t, EventGameStart, events[0].Type)
+	assert.Equal(t, EventGameOver, events[len(events)-1].Type)
+	for i, e := range events {
+		assert.Equal(t, i, e.Seq)
+	}
+
+	counts := make(map[EventType]int)
+	for _, e := range events {
+		counts[e.Type]++
+	}
+	assert.Equal(t, 26, counts[EventScore])
+	assert.Equal(t, 25, counts[EventTurnAdvance])
+	assert.Equal(t, 26, counts[EventRoll])
+
+	states, err := Replay(events)
+	require.NoError(t, err)
+	require.Len(t, states, len(events))
+	final := states[len(states)-1]
+	assert.Equal(t, PhaseFinished, final.Phase)
+	assert.Equal(t, events[len(events)-1].Totals, []int{
+		final.Players[0].Scorecard.Total(),
+		final.Players[1].Scorecard.Total(),
+	})
+}
+
+func TestEvents_ReplayDetectsTampering(t *testing.T) {
+	events := recordBattle(t, 12)
+	for i := range events {
+		if events[i].Type == EventScore {
+			events[i].Score += 5
+			break
+		}
+	}
+	_, err := Replay(events)
+	assert.Error(t, err)
+
+	events = recordBattle(t, 12)
+	events[1].Dice[0] = events[1].Dice[0]%6 + 1
+	_, err = Replay(events)
+	assert.ErrorContains(t, err, "recorded dice")
+}
+
+func TestEvents_ReplayRequiresStart(t *testing.T) {
+	events := recordBattle(t, 13)
+	_, err := Replay(events[1:])
+	assert.Error(t, err)
+
+	events[0].Snapshot.RNG = nil
+	_, err = Replay(events)
+	assert.ErrorContains(t, err, "RNG")
+}
+
+func TestGame_OnEventFromMidGame(t *testing.T) {
+	g := NewGame([]string{"A", "B"}, NewSeededSource(8))
+	require.NoError(t, g.Roll())
+
+	var events []Event
+	g.OnEvent(func(e Event) { events = append(events, e) })
+	require.NoError(t, g.Hold([]int{0, 1}))
+	require.NoError(t, g.Score(Chance))
+
+	require.Len(t, events, 4)
+	assert.Equal(t, []EventType{EventGameStart, EventHold, EventScore, EventTurnAdvance},
+		[]EventType{events[0].Type, events[1].Type, events[2].Type, events[3].Type})
+	assert.Equal(t, []int{0, 1}, events[1].Held)
+	assert.Equal(t, "player-1", events[3].Player)
+
+	_, err := Replay(events)
+	assert.NoError(t, err)
+}
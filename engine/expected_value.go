@@ -1,5 +1,7 @@
 package engine
 
+import "math"
+
 // allHoldCombinations contains all 32 possible hold combinations (subsets of {0,1,2,3,4}).
 // Computed once at package init time.
 var allHoldCombinations = func() [][]int {
@@ -20,75 +22,123 @@ func holdCombinations() [][]int {
 	return allHoldCombinations
 }
 
-// expectedValue calculates the average best score across all possible reroll outcomes
-// for a given hold combination.
-func expectedValue(dice [5]int, hold []int, available []Category, scorecard Scorecard) float64 {
-	holdSet := make(map[int]bool, len(hold))
-	for _, i := range hold {
-		holdSet[i] = true
+// evTable evaluates the holds of one decision on dice multisets. It values
+// each of the 252 distinct rolls at most once, and memoizes the expected
+// value of each keep, so holds that keep the same dice values are evaluated
+// once. evTable doesn't allocate, so it can live on the stack.
+type evTable struct {
+	available []Category
+	scorecard Scorecard
+	value     CategoryValue // nil values categories by their points
+
+	// rolls[r] is the value of reaching roll r after the first reroll:
+	// the best score it can be placed for if no reroll follows, filled
+	// lazily, or the best of scoring and holding again otherwise.
+	rolls  [numRolls]float64
+	scored [numRolls]bool
+	keepEV [numKeeps]float64
+	known  [numKeeps]bool
+}
+
+// reset prepares t to evaluate holds against available on scorecard, with
+// rerolls rerolls left (at least one) counting the one being decided.
+func (t *evTable) reset(available []Category, scorecard Scorecard, value CategoryValue, rerolls int) {
+	t.available, t.scorecard, t.value = available, scorecard, value
+	t.scored = [numRolls]bool{}
+	t.known = [numKeeps]bool{}
+	if rerolls <= 1 {
+		return
+	}
+	for r := range multisets.rolls {
+		t.rollValue(r)
 	}
+	for range rerolls - 1 {
+		for k := range multisets.keeps {
+			t.keepEV[k] = t.expectation(k)
+		}
+		bestKeepValues(t.keepEV[:], t.rolls[:])
+	}
+}
 
-	// Count free dice (not held)
-	var freeDice int
-	for i := 0; i < 5; i++ {
-		if !holdSet[i] {
-			freeDice++
+// rollValue returns the value of reaching roll r.
+func (t *evTable) rollValue(r int) float64 {
+	if !t.scored[r] {
+		dice := multisets.rollDice[r]
+		if t.value == nil {
+			t.rolls[r] = float64(bestScoreForDice(dice, t.available, t.scorecard))
+		} else {
+			t.rolls[r] = bestValueForDice(dice, t.available, t.scorecard, t.value)
 		}
+		t.scored[r] = true
 	}
+	return t.rolls[r]
+}
 
-	if freeDice == 0 {
-		return float64(bestScoreForDice(dice, available))
+// expectation averages rollValue over all rerolls of the dice not in keep
+// k. Weighting by the number of ordered rerolls keeps integer values exact,
+// so the result is the same float as averaging every ordered reroll.
+func (t *evTable) expectation(k int) float64 {
+	total, outcomes := 0.0, 0
+	for _, o := range multisets.keepOutcomes[k] {
+		total += float64(o.ways) * t.rollValue(o.roll)
+		outcomes += o.ways
 	}
+	return total / float64(outcomes)
+}
 
-	totalOutcomes := pow6(freeDice)
-	totalScore := 0.0
-
-	// Enumerate all outcomes for free dice
-	for outcome := 0; outcome < totalOutcomes; outcome++ {
-		var newDice [5]int
-		copy(newDice[:], dice[:])
-		rem := outcome
-		for i := 0; i < 5; i++ {
-			if !holdSet[i] {
-				newDice[i] = (rem % 6) + 1
-				rem /= 6
-			}
-		}
-		best := bestScoreForDice(newDice, available)
-		totalScore += float64(best)
+// keepValue returns the expected value of holding keep k.
+func (t *evTable) keepValue(k int) float64 {
+	if !t.known[k] {
+		t.keepEV[k] = t.expectation(k)
+		t.known[k] = true
 	}
+	return t.keepEV[k]
+}
 
-	return totalScore / float64(totalOutcomes)
+// keepIndex returns the keep that hold holds of dice.
+func keepIndex(dice [5]int, hold []int) int {
+	return multisets.keepIndexByKey[keepKey(dice, hold)]
+}
+
+// expectedValue calculates the average best score across all possible reroll outcomes
+// for a given hold combination.
+func expectedValue(dice [5]int, hold []int, available []Category, scorecard Scorecard) float64 {
+	var t evTable
+	t.reset(available, scorecard, nil, 1)
+	return t.keepValue(keepIndex(dice, hold))
 }
 
 // expectedValueWithBonus adds upper bonus consideration to the expected value.
 func expectedValueWithBonus(dice [5]int, hold []int, available []Category, scorecard Scorecard) float64 {
-	base := expectedValue(dice, hold, available, scorecard)
+	return expectedValue(dice, hold, available, scorecard) + upperBonusIncentive(scorecard)
+}
 
-	// Bonus: if close to upper bonus, add incentive for upper section scores
+// upperBonusIncentive is the boost expectedValueWithBonus gives every hold
+// when the scorecard is close to the upper bonus.
+func upperBonusIncentive(scorecard Scorecard) float64 {
+	threshold, value := scorecard.Rules().UpperBonus()
 	upperTotal := scorecard.UpperTotal()
-	if upperTotal < UpperBonusThreshold && !scorecard.HasUpperBonus() {
-		remaining := UpperBonusThreshold - upperTotal
+	if value > 0 && upperTotal < threshold {
+		remaining := threshold - upperTotal
 		// Count remaining upper categories
 		upperRemaining := 0
-		for _, c := range UpperCategories {
+		for _, c := range scorecard.Rules().UpperCategories() {
 			if !scorecard.IsFilled(c) {
 				upperRemaining++
 			}
 		}
 		if upperRemaining > 0 && remaining <= upperRemaining*5 {
 			// Close to bonus — small boost
-			base += float64(UpperBonusValue) * 0.1
+			return float64(value) * 0.1
 		}
 	}
-
-	return base
+	return 0
 }
 
-func bestScoreForDice(dice [5]int, available []Category) int {
+func bestScoreForDice(dice [5]int, available []Category, sc Scorecard) int {
 	best := 0
 	for _, c := range available {
-		s := CalcScore(c, dice)
+		s := ScoreFor(c, dice, sc)
 		if s > best {
 			best = s
 		}
@@ -96,6 +146,19 @@ func bestScoreForDice(dice [5]int, available []Category) int {
 	return best
 }
 
+// bestValueForDice returns the highest value of scoring dice in an
+// available category, or zero if none is.
+func bestValueForDice(dice [5]int, available []Category, sc Scorecard, value CategoryValue) float64 {
+	if len(available) == 0 {
+		return 0
+	}
+	best := math.Inf(-1)
+	for _, c := range available {
+		best = max(best, value(c, dice, sc))
+	}
+	return best
+}
+
 func pow6(n int) int {
 	result := 1
 	for i := 0; i < n; i++ {
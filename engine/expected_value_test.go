@@ -0,0 +1,234 @@
+package engine
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// enumeratedExpectedValue is the reference evaluator: it averages the best
+// score over every ordered reroll of the dice not in hold.
+func enumeratedExpectedValue(dice [5]int, hold []int, available []Category, sc Scorecard) float64 {
+	held := [5]bool{}
+	for _, i := range hold {
+		held[i] = true
+	}
+	free := 0
+	for _, h := range held {
+		if !h {
+			free++
+		}
+	}
+	total := 0.0
+	for outcome := range pow6(free) {
+		d := dice
+		rem := outcome
+		for i := range d {
+			if !held[i] {
+				d[i] = rem%6 + 1
+				rem /= 6
+			}
+		}
+		total += float64(bestScoreForDice(d, available, sc))
+	}
+	return total / float64(pow6(free))
+}
+
+// enumeratedDecision is a one-roll StatisticalStrategy.DecideAction before
+// a roll, evaluated with enumeratedExpectedValue.
+func enumeratedDecision(dice [5]int, sc Scorecard, available []Category) TurnAction {
+	immediate := bestCategoryForDice(dice, available, sc)
+	bestEV := float64(ScoreFor(immediate, dice, sc))
+	var bestHold []int
+	for _, hold := range holdCombinations() {
+		if len(hold) == 5 {
+			continue
+		}
+		ev := enumeratedExpectedValue(dice, hold, available, sc) + upperBonusIncentive(sc)
+		if ev > bestEV {
+			bestEV, bestHold = ev, hold
+		}
+	}
+	if bestHold != nil {
+		return TurnAction{Type: "hold", Indices: bestHold}
+	}
+	return TurnAction{Type: "score", Category: immediate}
+}
+
+// randomScorecard fills a random subset of categories with scores that
+// dice could have made, leaving at least one open.
+func randomScorecard(rng *rand.Rand, rules RuleSet) Scorecard {
+	sc := NewScorecardWithRules(rules)
+	cats := rules.Categories()
+	for _, i := range rng.Perm(len(cats))[:rng.Intn(len(cats))] {
+		var dice [5]int
+		for j := range dice {
+			dice[j] = rng.Intn(6) + 1
+		}
+		if rng.Intn(4) == 0 {
+			dice = [5]int{dice[0], dice[0], dice[0], dice[0], dice[0]}
+		}
+		sc.Fill(cats[i], rules.Score(cats[i], dice, sc))
+	}
+	return sc
+}
+
+func randomDice(rng *rand.Rand) [5]int {
+	var dice [5]int
+	for i := range dice {
+		dice[i] = rng.Intn(6) + 1
+	}
+	return dice
+}
+
+func TestExpectedValue_MatchesEnumeration(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for _, name := range RuleSetNames() {
+		rules, err := RuleSetByName(name)
+		require.NoError(t, err)
+		for range 20 {
+			sc := randomScorecard(rng, rules)
+			dice := randomDice(rng)
+			avail := PlaceableCategories(dice, sc)
+			for _, hold := range holdCombinations() {
+				// Exact equality: decisions compare these values.
+				assert.Equal(t, enumeratedExpectedValue(dice, hold, avail, sc), expectedValue(dice, hold, avail, sc),
+					"%s: dice %v hold %v", name, dice, hold)
+			}
+		}
+	}
+}
+
+func TestStatisticalStrategy_MatchesEnumeration(t *testing.T) {
+	rng := rand.New(rand.NewSource(2))
+	s := &StatisticalStrategy{Depth: 1}
+	for _, name := range RuleSetNames() {
+		rules, err := RuleSetByName(name)
+		require.NoError(t, err)
+		for range 50 {
+			sc := randomScorecard(rng, rules)
+			dice := randomDice(rng)
+			avail := PlaceableCategories(dice, sc)
+			assert.Equal(t, enumeratedDecision(dice, sc, avail), s.DecideAction(dice, 1, sc, avail),
+				"%s: dice %v", name, dice)
+		}
+	}
+}
+
+func TestStatisticalStrategy_DoesNotAllocate(t *testing.T) {
+	s := &StatisticalStrategy{}
+	sc := NewScorecard()
+	avail := sc.AvailableCategories()
+	allocs := testing.AllocsPerRun(10, func() {
+		s.DecideAction([5]int{2, 3, 3, 5, 6}, 1, sc, avail)
+	})
+	assert.Zero(t, allocs)
+}
+
+func benchmarkDecisions(b *testing.B, decide func(dice [5]int, sc Scorecard, avail []Category) TurnAction) {
+	rng := rand.New(rand.NewSource(3))
+	type state struct {
+		dice  [5]int
+		sc    Scorecard
+		avail []Category
+	}
+	states := make([]state, 64)
+	for i := range states {
+		sc := randomScorecard(rng, DefaultRules())
+		dice := randomDice(rng)
+		states[i] = state{dice, sc, PlaceableCategories(dice, sc)}
+	}
+	b.ResetTimer()
+	for i := 0; b.Loop(); i++ {
+		st := states[i%len(states)]
+		decide(st.dice, st.sc, st.avail)
+	}
+}
+
+func BenchmarkStatisticalDecide(b *testing.B) {
+	s := &StatisticalStrategy{Depth: 1}
+	benchmarkDecisions(b, func(dice [5]int, sc Scorecard, avail []Category) TurnAction {
+		return s.DecideAction(dice, 1, sc, avail)
+	})
+}
+
+func BenchmarkStatisticalDecide_TwoRolls(b *testing.B) {
+	s := &StatisticalStrategy{}
+	benchmarkDecisions(b, func(dice [5]int, sc Scorecard, avail []Category) TurnAction {
+		return s.DecideAction(dice, 1, sc, avail)
+	})
+}
+
+// BenchmarkStatisticalDecide_Enumerated is the same decision made by
+// enumerating every ordered reroll, for comparison.
+func BenchmarkStatisticalDecide_Enumerated(b *testing.B) {
+	benchmarkDecisions(b, enumeratedDecision)
+}
+
+func BenchmarkExpectedValue(b *testing.B) {
+	sc := NewScorecard()
+	avail := sc.AvailableCategories()
+	for b.Loop() {
+		expectedValue([5]int{2, 3, 3, 5, 6}, []int{1, 2}, avail, sc)
+	}
+}
+
+func BenchmarkExpectedValue_Enumerated(b *testing.B) {
+	sc := NewScorecard()
+	avail := sc.AvailableCategories()
+	for b.Loop() {
+		enumeratedExpectedValue([5]int{2, 3, 3, 5, 6}, []int{1, 2}, avail, sc)
+	}
+}
+
+func TestStatisticalStrategy_LookaheadPlansBothRerolls(t *testing.T) {
+	sc := NewScorecard()
+	avail := sc.AvailableCategories()
+	dice := [5]int{1, 1, 2, 4, 6}
+
+	// Treating the next roll as the last, 4-6 is worth keeping for Chance;
+	// with two rerolls to go, 1-2-4 draws at the straights.
+	oneRoll := (&StatisticalStrategy{Depth: 1}).DecideAction(dice, 1, sc, avail)
+	assert.Equal(t, []int{3, 4}, oneRoll.Indices)
+	twoRolls := (&StatisticalStrategy{}).DecideAction(dice, 1, sc, avail)
+	assert.Equal(t, []int{2, 3}, twoRolls.Indices)
+
+	// Depth only limits the lookahead: on roll 2 one reroll is left.
+	assert.Equal(t, oneRoll, (&StatisticalStrategy{}).DecideAction(dice, 2, sc, avail))
+}
+
+func TestStatisticalStrategy_OptimalCategoryValue(t *testing.T) {
+	// Valuing categories with the optimal table, the full lookahead is the
+	// optimal strategy: its decisions are worth as much, up to ties. The
+	// strategy never rerolls all five dice, so states where that is the
+	// optimal choice are left out.
+	table := solveOptimalTable(numCategories - 1)
+	s := &StatisticalStrategy{Value: table.CategoryValue}
+	opt, err := NewOptimalStrategy(table)
+	require.NoError(t, err)
+	rng := rand.New(rand.NewSource(4))
+	for range 200 {
+		open := AllCategories[rng.Intn(numCategories)]
+		sc := scorecardWithOpen(open)
+		dice := randomDice(rng)
+		avail := PlaceableCategories(dice, sc)
+		for rollCount := 1; rollCount <= MaxRolls; rollCount++ {
+			var ev evTable
+			ev.reset(avail, sc, table.CategoryValue, MaxRolls-rollCount)
+			worth := func(a TurnAction) float64 {
+				if a.Type == "hold" {
+					return ev.keepValue(keepIndex(dice, a.Indices))
+				}
+				return table.CategoryValue(a.Category, dice, sc)
+			}
+			best := opt.DecideAction(dice, rollCount, sc, avail)
+			if best.Type == "hold" && len(best.Indices) == 0 {
+				continue
+			}
+			assert.InDelta(t, worth(best), worth(s.DecideAction(dice, rollCount, sc, avail)), 1e-9,
+				"%s open, dice %v, roll %d", open, dice, rollCount)
+		}
+	}
+}
@@ -17,6 +17,9 @@ const (
 )
 
 const MaxRolls = 3
+
+// MaxRounds is the number of rounds under the default Yahtzee rules.
+// Other rule sets play one round per category; see Game.MaxRounds.
 const MaxRounds = 13
 
 type Game struct {
@@ -27,6 +30,8 @@ type Game struct {
 	RollCount int
 	Phase     GamePhase
 	rng       rand.Source
+	rules     RuleSet
+	listeners []func(Event)
 }
 
 type Player struct {
@@ -44,6 +49,8 @@ type GameState struct {
 	RollCount           int
 	Phase               GamePhase
 	AvailableCategories []Category
+	Rules               string
+	MaxRounds           int
 }
 
 type PlayerState struct {
@@ -53,18 +60,27 @@ type PlayerState struct {
 }
 
 func NewGame(playerNames []string, src rand.Source) *Game {
+	return NewGameWithRules(playerNames, src, DefaultRules())
+}
+
+// NewGameWithRules creates a game played under the given rule set.
+// A nil rules uses DefaultRules.
+func NewGameWithRules(playerNames []string, src rand.Source, rules RuleSet) *Game {
 	if len(playerNames) == 0 {
 		panic("NewGame requires at least 1 player")
 	}
 	if src == nil {
-		src = rand.NewSource(time.Now().UnixNano())
+		src = NewSeededSource(time.Now().UnixNano())
+	}
+	if rules == nil {
+		rules = DefaultRules()
 	}
 	players := make([]Player, len(playerNames))
 	for i, name := range playerNames {
 		players[i] = Player{
 			ID:        fmt.Sprintf("player-%d", i),
 			Name:      name,
-			Scorecard: NewScorecard(),
+			Scorecard: NewScorecardWithRules(rules),
 		}
 	}
 	return &Game{
@@ -73,9 +89,20 @@ func NewGame(playerNames []string, src rand.Source) *Game {
 		Round:   1,
 		Phase:   PhaseRolling,
 		rng:     src,
+		rules:   rules,
 	}
 }
 
+// Rules returns the rule set the game is played under.
+func (g *Game) Rules() RuleSet {
+	return g.rules
+}
+
+// MaxRounds returns the number of rounds in the game, one per category.
+func (g *Game) MaxRounds() int {
+	return len(g.rules.Categories())
+}
+
 func (g *Game) Roll() error {
 	if g.Phase != PhaseRolling {
 		return errors.New("cannot roll: not in rolling phase")
@@ -83,11 +110,23 @@ func (g *Game) Roll() error {
 	if g.RollCount != 0 {
 		return errors.New("cannot roll: use Hold() for subsequent rolls")
 	}
-	g.Dice = RollAll(g.rng)
+	g.Dice = g.rollDice(nil)
 	g.RollCount++
+	g.emit(Event{Type: EventRoll, Player: g.Players[g.Current].ID, Round: g.Round, Dice: g.Dice})
 	return nil
 }
 
+// rollDice rolls the dice not in hold for the next roll of the turn.
+func (g *Game) rollDice(hold []int) [5]int {
+	if ds, ok := g.rng.(DiceSource); ok {
+		return keepHeld(g.Dice, ds.DiceFor(g.Round, g.Current, g.RollCount+1), hold)
+	}
+	if g.RollCount == 0 {
+		return RollAll(g.rng)
+	}
+	return Reroll(g.Dice, hold, g.rng)
+}
+
 func (g *Game) Hold(indices []int) error {
 	if g.Phase != PhaseRolling {
 		return errors.New("cannot hold: not in rolling phase")
@@ -103,11 +142,12 @@ func (g *Game) Hold(indices []int) error {
 			return fmt.Errorf("cannot hold: index %d out of range (0-4)", idx)
 		}
 	}
-	g.Dice = Reroll(g.Dice, indices, g.rng)
+	g.Dice = g.rollDice(indices)
 	g.RollCount++
 	if g.RollCount >= MaxRolls {
 		g.Phase = PhaseChoosing
 	}
+	g.emit(Event{Type: EventHold, Player: g.Players[g.Current].ID, Round: g.Round, Dice: g.Dice, Held: append([]int(nil), indices...)})
 	return nil
 }
 
@@ -118,15 +158,27 @@ func (g *Game) Score(category Category) error {
 	if g.RollCount == 0 {
 		return errors.New("cannot score: must roll first")
 	}
-	if !IsValidCategory(category) {
+	if !containsCategory(g.rules.Categories(), category) {
 		return fmt.Errorf("cannot score: invalid category %q", category)
 	}
 	player := &g.Players[g.Current]
 	if player.Scorecard.IsFilled(category) {
 		return fmt.Errorf("cannot score: category %s already filled", category)
 	}
-	score := CalcScore(category, g.Dice)
+	if !containsCategory(PlaceableCategories(g.Dice, player.Scorecard), category) {
+		return fmt.Errorf("cannot score: joker rules require %s", jokerRequirement(g.Dice, player.Scorecard))
+	}
+	score := ScoreFor(category, g.Dice, player.Scorecard)
+	bonus := 0
+	if EarnsYahtzeeBonus(g.Dice, player.Scorecard) {
+		player.Scorecard.AddYahtzeeBonus()
+		bonus = g.rules.YahtzeeBonus()
+	}
 	player.Scorecard.Fill(category, score)
+	g.emit(Event{
+		Type: EventScore, Player: player.ID, Round: g.Round, Dice: g.Dice,
+		Category: category, Score: score, YahtzeeBonus: bonus,
+	})
 	g.advanceTurn()
 	return nil
 }
@@ -137,13 +189,19 @@ func (g *Game) advanceTurn() {
 		g.Current = 0
 		g.Round++
 	}
-	if g.Round > MaxRounds {
+	if g.Round > g.MaxRounds() {
 		g.Phase = PhaseFinished
+		totals := make([]int, len(g.Players))
+		for i, p := range g.Players {
+			totals[i] = p.Scorecard.Total()
+		}
+		g.emit(Event{Type: EventGameOver, Dice: g.Dice, Totals: totals})
 		return
 	}
 	g.Phase = PhaseRolling
 	g.RollCount = 0
 	g.Dice = [5]int{}
+	g.emit(Event{Type: EventTurnAdvance, Player: g.Players[g.Current].ID, Round: g.Round})
 }
 
 func (g *Game) GetState() GameState {
@@ -152,7 +210,7 @@ func (g *Game) GetState() GameState {
 		players[i] = PlayerState{
 			ID:        p.ID,
 			Name:      p.Name,
-			Scorecard: p.Scorecard,
+			Scorecard: p.Scorecard.clone(),
 		}
 	}
 	return GameState{
@@ -164,11 +222,35 @@ func (g *Game) GetState() GameState {
 		RollCount:           g.RollCount,
 		Phase:               g.Phase,
 		AvailableCategories: g.GetAvailableCategories(),
+		Rules:               g.rules.Name(),
+		MaxRounds:           g.MaxRounds(),
 	}
 }
 
+// GetAvailableCategories returns the categories the current player may score
+// in with the current dice, honoring the Joker rules.
 func (g *Game) GetAvailableCategories() []Category {
-	return g.Players[g.Current].Scorecard.AvailableCategories()
+	return PlaceableCategories(g.Dice, g.Players[g.Current].Scorecard)
+}
+
+func containsCategory(cats []Category, c Category) bool {
+	for _, cat := range cats {
+		if cat == c {
+			return true
+		}
+	}
+	return false
+}
+
+func jokerRequirement(dice [5]int, sc Scorecard) string {
+	placeable := PlaceableCategories(dice, sc)
+	if len(placeable) == 1 {
+		return string(placeable[0])
+	}
+	if isUpperCategory(placeable[0]) {
+		return "an open upper category"
+	}
+	return "an open lower category"
 }
 
 func (g *Game) GetScorecard(playerID string) *Scorecard {
@@ -77,6 +77,39 @@ func TestGame_Hold(t *testing.T) {
 	}
 }
 
+// tableDice is a DiceSource whose dice spell out the roll's place in the
+// game.
+type tableDice struct{ rand.Source }
+
+func (tableDice) DiceFor(round, player, roll int) [5]int {
+	return [5]int{round%6 + 1, player + 1, roll, roll, roll}
+}
+
+func TestGame_DiceSource(t *testing.T) {
+	g := NewGame([]string{"Alice", "Bob"}, tableDice{rand.NewSource(1)})
+	if err := g.Roll(); err != nil {
+		t.Fatalf("Roll() failed: %v", err)
+	}
+	if want := [5]int{2, 1, 1, 1, 1}; g.Dice != want {
+		t.Errorf("roll 1: expected %v, got %v", want, g.Dice)
+	}
+	if err := g.Hold([]int{0, 2}); err != nil {
+		t.Fatalf("Hold() failed: %v", err)
+	}
+	if want := [5]int{2, 1, 1, 2, 2}; g.Dice != want {
+		t.Errorf("roll 2: expected %v, got %v", want, g.Dice)
+	}
+	if err := g.Score(g.GetAvailableCategories()[0]); err != nil {
+		t.Fatalf("Score() failed: %v", err)
+	}
+	if err := g.Roll(); err != nil {
+		t.Fatalf("Roll() failed: %v", err)
+	}
+	if want := [5]int{2, 2, 1, 1, 1}; g.Dice != want {
+		t.Errorf("Bob's roll 1: expected %v, got %v", want, g.Dice)
+	}
+}
+
 func TestGame_Hold_BeforeRoll(t *testing.T) {
 	g := newTestGame()
 	err := g.Hold([]int{0, 1})
@@ -221,3 +254,38 @@ func TestGame_GetAvailableCategories(t *testing.T) {
 		t.Errorf("expected 13 categories, got %d", len(cats))
 	}
 }
+
+func TestGame_Score_YahtzeeBonusAndJoker(t *testing.T) {
+	g := NewGame([]string{"Solo"}, rand.NewSource(1))
+	g.Players[0].Scorecard.Fill(Yahtzee, 50)
+	g.Dice = [5]int{6, 6, 6, 6, 6}
+	g.RollCount = 1
+
+	if err := g.Score(Chance); err == nil {
+		t.Fatal("expected Joker rules to force Sixes")
+	}
+	if err := g.Score(Sixes); err != nil {
+		t.Fatalf("Score(Sixes) failed: %v", err)
+	}
+	sc := g.Players[0].Scorecard
+	if got := sc.GetScore(Sixes); got != 30 {
+		t.Errorf("expected Sixes=30, got %d", got)
+	}
+	if got := sc.YahtzeeBonusCount(); got != 1 {
+		t.Errorf("expected 1 Yahtzee bonus, got %d", got)
+	}
+
+	// Sixes is now filled: a second extra Yahtzee may go in the lower section.
+	g.Dice = [5]int{6, 6, 6, 6, 6}
+	g.RollCount = 1
+	if err := g.Score(LargeStraight); err != nil {
+		t.Fatalf("Score(LargeStraight) failed: %v", err)
+	}
+	sc = g.Players[0].Scorecard
+	if got := sc.GetScore(LargeStraight); got != 40 {
+		t.Errorf("expected Joker LargeStraight=40, got %d", got)
+	}
+	if got := sc.Total(); got != 50+30+40+2*YahtzeeBonusValue {
+		t.Errorf("expected total %d, got %d", 50+30+40+2*YahtzeeBonusValue, got)
+	}
+}
@@ -0,0 +1,74 @@
+package engine
+
+// Joker rules (official Yahtzee):
+//
+// When a Yahtzee is rolled and the Yahtzee box is already filled, the dice
+// act as a "Joker". A bonus of YahtzeeBonusValue is awarded if the Yahtzee
+// box holds 50 (not if it was scratched with 0). The Yahtzee must then be
+// placed as follows:
+//  1. In the matching upper category, if it is open.
+//  2. Otherwise in any open lower category. Full House, Small Straight and
+//     Large Straight score their full fixed value.
+//  3. Otherwise as a zero in any open upper category.
+
+var jokerScores = map[Category]int{
+	FullHouse:     25,
+	SmallStraight: 30,
+	LargeStraight: 40,
+}
+
+// IsJoker reports whether dice form an extra Yahtzee for the given scorecard.
+func IsJoker(dice [5]int, sc Scorecard) bool {
+	return hasNOfAKind(dice, 5) && sc.IsFilled(Yahtzee)
+}
+
+// EarnsYahtzeeBonus reports whether scoring dice now awards a Yahtzee bonus
+// under the scorecard's rule set.
+func EarnsYahtzeeBonus(dice [5]int, sc Scorecard) bool {
+	return sc.Rules().YahtzeeBonus() > 0 && IsJoker(dice, sc) && sc.GetScore(Yahtzee) > 0
+}
+
+// PlaceableCategories returns the categories dice may legally be scored in
+// under the scorecard's rule set.
+func PlaceableCategories(dice [5]int, sc Scorecard) []Category {
+	return sc.Rules().Placeable(dice, sc)
+}
+
+// ScoreFor returns the points dice would record in category c on the given
+// scorecard, applying rule-set specific scoring such as Joker values.
+func ScoreFor(c Category, dice [5]int, sc Scorecard) int {
+	return sc.Rules().Score(c, dice, sc)
+}
+
+// jokerPlaceable applies the Joker placement order to the open categories.
+func jokerPlaceable(dice [5]int, sc Scorecard) []Category {
+	avail := sc.AvailableCategories()
+	if !IsJoker(dice, sc) {
+		return avail
+	}
+
+	upper := UpperCategories[dice[0]-1]
+	if !sc.IsFilled(upper) {
+		return []Category{upper}
+	}
+
+	var lower []Category
+	for _, c := range avail {
+		if !isUpperCategory(c) {
+			lower = append(lower, c)
+		}
+	}
+	if len(lower) > 0 {
+		return lower
+	}
+	return avail
+}
+
+func isUpperCategory(c Category) bool {
+	for _, u := range UpperCategories {
+		if u == c {
+			return true
+		}
+	}
+	return false
+}
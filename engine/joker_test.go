@@ -0,0 +1,75 @@
+package engine
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsJoker(t *testing.T) {
+	sc := NewScorecard()
+	yahtzee := [5]int{4, 4, 4, 4, 4}
+	assert.False(t, IsJoker(yahtzee, sc), "first Yahtzee is not a Joker")
+
+	sc.Fill(Yahtzee, 50)
+	assert.True(t, IsJoker(yahtzee, sc))
+	assert.False(t, IsJoker([5]int{4, 4, 4, 4, 3}, sc))
+}
+
+func TestEarnsYahtzeeBonus(t *testing.T) {
+	yahtzee := [5]int{2, 2, 2, 2, 2}
+
+	sc := NewScorecard()
+	sc.Fill(Yahtzee, 50)
+	assert.True(t, EarnsYahtzeeBonus(yahtzee, sc))
+
+	scratched := NewScorecard()
+	scratched.Fill(Yahtzee, 0)
+	assert.False(t, EarnsYahtzeeBonus(yahtzee, scratched), "no bonus after scratching Yahtzee")
+	assert.True(t, IsJoker(yahtzee, scratched), "Joker still applies after scratching Yahtzee")
+}
+
+func TestPlaceableCategories_ForcedUpper(t *testing.T) {
+	sc := NewScorecard()
+	sc.Fill(Yahtzee, 50)
+
+	got := PlaceableCategories([5]int{3, 3, 3, 3, 3}, sc)
+	assert.Equal(t, []Category{Threes}, got)
+}
+
+func TestPlaceableCategories_LowerWhenUpperFilled(t *testing.T) {
+	sc := NewScorecard()
+	sc.Fill(Yahtzee, 50)
+	sc.Fill(Threes, 9)
+
+	got := PlaceableCategories([5]int{3, 3, 3, 3, 3}, sc)
+	assert.Equal(t, []Category{ThreeOfAKind, FourOfAKind, FullHouse, SmallStraight, LargeStraight, Chance}, got)
+}
+
+func TestPlaceableCategories_ZeroUpperWhenLowerFilled(t *testing.T) {
+	sc := NewScorecard()
+	for _, c := range AllCategories {
+		if !isUpperCategory(c) {
+			sc.Fill(c, 0)
+		}
+	}
+	sc.Fill(Sixes, 18)
+
+	got := PlaceableCategories([5]int{6, 6, 6, 6, 6}, sc)
+	assert.Equal(t, []Category{Ones, Twos, Threes, Fours, Fives}, got)
+	assert.Equal(t, 0, ScoreFor(Ones, [5]int{6, 6, 6, 6, 6}, sc))
+}
+
+func TestScoreFor_Joker(t *testing.T) {
+	sc := NewScorecard()
+	sc.Fill(Yahtzee, 50)
+	dice := [5]int{5, 5, 5, 5, 5}
+
+	assert.Equal(t, 25, ScoreFor(FullHouse, dice, sc))
+	assert.Equal(t, 30, ScoreFor(SmallStraight, dice, sc))
+	assert.Equal(t, 40, ScoreFor(LargeStraight, dice, sc))
+	assert.Equal(t, 25, ScoreFor(Chance, dice, sc))
+
+	fresh := NewScorecard()
+	assert.Equal(t, 0, ScoreFor(LargeStraight, dice, fresh), "no Joker before Yahtzee is filled")
+}
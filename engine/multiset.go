@@ -0,0 +1,234 @@
+package engine
+
+import "sort"
+
+// Dice order never matters for scoring, so solvers work on multisets of dice
+// instead of ordered rolls. There are 252 distinct rolls of five dice and 462
+// distinct "keeps" (multisets of zero to five dice held between rolls).
+const (
+	numRolls = 252
+	numKeeps = 462
+)
+
+// faceCounts is a dice multiset: faceCounts[v-1] is the number of dice showing v.
+type faceCounts [6]int
+
+func (fc faceCounts) size() int {
+	n := 0
+	for _, c := range fc {
+		n += c
+	}
+	return n
+}
+
+// key packs the counts into a unique integer (each count is at most 5).
+func (fc faceCounts) key() int {
+	k := 0
+	for i := 5; i >= 0; i-- {
+		k = k*6 + fc[i]
+	}
+	return k
+}
+
+func countsOfDice(dice [5]int) faceCounts {
+	var fc faceCounts
+	for _, d := range dice {
+		if d >= 1 && d <= 6 {
+			fc[d-1]++
+		}
+	}
+	return fc
+}
+
+// rollOutcome is one possible result of rerolling from a keep.
+type rollOutcome struct {
+	roll int     // index into multisetTables.rolls
+	prob float64 // probability of reaching it
+	ways int     // ordered rerolls reaching it, out of 6^(dice rerolled)
+}
+
+// multisetTables holds the precomputed roll and keep enumerations.
+type multisetTables struct {
+	// rolls lists every five-dice multiset.
+	rolls []faceCounts
+	// rollDice holds each roll as sorted dice.
+	rollDice [][5]int
+	// rollProb is the probability of each roll from five fresh dice.
+	rollProb []float64
+	// keeps lists every multiset of zero to five dice.
+	keeps []faceCounts
+	// keepOutcomes lists the rolls reachable from each keep by rerolling
+	// the remaining dice, with their probabilities.
+	keepOutcomes [][]rollOutcome
+	// rollSubKeeps lists the distinct keeps contained in each roll.
+	rollSubKeeps [][]int
+
+	rollIndexByKey map[int]int
+	keepIndexByKey map[int]int
+}
+
+var multisets = buildMultisetTables()
+
+func buildMultisetTables() *multisetTables {
+	t := &multisetTables{
+		rollIndexByKey: make(map[int]int, 252),
+		keepIndexByKey: make(map[int]int, 462),
+	}
+
+	for n := 0; n <= 5; n++ {
+		for _, fc := range multisetsOfSize(n) {
+			t.keepIndexByKey[fc.key()] = len(t.keeps)
+			t.keeps = append(t.keeps, fc)
+		}
+	}
+
+	for _, fc := range multisetsOfSize(5) {
+		t.rollIndexByKey[fc.key()] = len(t.rolls)
+		t.rolls = append(t.rolls, fc)
+		t.rollDice = append(t.rollDice, diceOfCounts(fc))
+		t.rollProb = append(t.rollProb, multisetProb(fc))
+	}
+
+	t.keepOutcomes = make([][]rollOutcome, len(t.keeps))
+	for k, keep := range t.keeps {
+		for _, add := range multisetsOfSize(5 - keep.size()) {
+			var roll faceCounts
+			for i := range roll {
+				roll[i] = keep[i] + add[i]
+			}
+			t.keepOutcomes[k] = append(t.keepOutcomes[k], rollOutcome{
+				roll: t.rollIndexByKey[roll.key()],
+				prob: multisetProb(add),
+				ways: multisetWays(add),
+			})
+		}
+	}
+
+	t.rollSubKeeps = make([][]int, len(t.rolls))
+	for r, roll := range t.rolls {
+		seen := make(map[int]bool)
+		var sub faceCounts
+		var walk func(face int)
+		walk = func(face int) {
+			if face == 6 {
+				k := t.keepIndexByKey[sub.key()]
+				if !seen[k] {
+					seen[k] = true
+					t.rollSubKeeps[r] = append(t.rollSubKeeps[r], k)
+				}
+				return
+			}
+			for c := 0; c <= roll[face]; c++ {
+				sub[face] = c
+				walk(face + 1)
+			}
+			sub[face] = 0
+		}
+		walk(0)
+		sort.Ints(t.rollSubKeeps[r])
+	}
+	return t
+}
+
+// multisetsOfSize enumerates all multisets of n dice in a fixed order.
+func multisetsOfSize(n int) []faceCounts {
+	var out []faceCounts
+	var fc faceCounts
+	var walk func(face, left int)
+	walk = func(face, left int) {
+		if face == 5 {
+			fc[5] = left
+			out = append(out, fc)
+			return
+		}
+		for c := left; c >= 0; c-- {
+			fc[face] = c
+			walk(face+1, left-c)
+		}
+	}
+	walk(0, n)
+	return out
+}
+
+// multisetProb returns the probability that fc.size() fresh dice show fc.
+func multisetProb(fc faceCounts) float64 {
+	n := fc.size()
+	ways := float64(factorial(n))
+	for _, c := range fc {
+		ways /= float64(factorial(c))
+	}
+	return ways / float64(pow6(n))
+}
+
+// multisetWays returns the number of ordered rolls of fc.size() dice that
+// show fc.
+func multisetWays(fc faceCounts) int {
+	ways := factorial(fc.size())
+	for _, c := range fc {
+		ways /= factorial(c)
+	}
+	return ways
+}
+
+func factorial(n int) int {
+	f := 1
+	for i := 2; i <= n; i++ {
+		f *= i
+	}
+	return f
+}
+
+func diceOfCounts(fc faceCounts) [5]int {
+	var dice [5]int
+	i := 0
+	for face, c := range fc {
+		for j := 0; j < c; j++ {
+			dice[i] = face + 1
+			i++
+		}
+	}
+	return dice
+}
+
+// rollIndex returns the multiset roll index of five rolled dice.
+func rollIndex(dice [5]int) int {
+	return multisets.rollIndexByKey[countsOfDice(dice).key()]
+}
+
+// holdIndicesForKeep returns dice positions that hold exactly keep.
+func holdIndicesForKeep(dice [5]int, keep faceCounts) []int {
+	var hold []int
+	for i, d := range dice {
+		if keep[d-1] > 0 {
+			keep[d-1]--
+			hold = append(hold, i)
+		}
+	}
+	return hold
+}
+
+// keepExpectations sets out[k] to the expected value of values over the
+// rolls reachable from each keep k.
+func keepExpectations(values []float64, out []float64) {
+	for k, outcomes := range multisets.keepOutcomes {
+		ev := 0.0
+		for _, o := range outcomes {
+			ev += o.prob * values[o.roll]
+		}
+		out[k] = ev
+	}
+}
+
+// bestKeepValues sets out[r] to the best keep expectation available from
+// each roll r.
+func bestKeepValues(keepEV []float64, out []float64) {
+	for r, subs := range multisets.rollSubKeeps {
+		best := keepEV[subs[0]]
+		for _, k := range subs[1:] {
+			if keepEV[k] > best {
+				best = keepEV[k]
+			}
+		}
+		out[r] = best
+	}
+}
@@ -0,0 +1,350 @@
+package engine
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/bits"
+	"os"
+)
+
+// The optimal solitaire solver computes, for every game state between turns,
+// the expected final score still to be gained under perfect play with the
+// official Yahtzee rules. A state is:
+//   - the set of filled categories (13 bits),
+//   - the upper subtotal capped at UpperBonusThreshold (0-63),
+//   - whether the Yahtzee box holds 50 (extra Yahtzees then earn a bonus).
+//
+// The table is solved backwards from the full scorecard. Within a turn the
+// value of each roll is derived from the table, so a single table drives every
+// hold and score decision.
+
+const (
+	numCategories   = 13
+	allFilledMask   = 1<<numCategories - 1
+	upperStates     = UpperBonusThreshold + 1
+	optimalStates   = (allFilledMask + 1) * upperStates * 2
+	yahtzeeCatIndex = 11
+)
+
+// Category indexes follow AllCategories order.
+var categoryIndex = func() map[Category]int {
+	m := make(map[Category]int, len(AllCategories))
+	for i, c := range AllCategories {
+		m[c] = i
+	}
+	return m
+}()
+
+// rawCategoryScores[r][c] is CalcScore for roll r in category index c.
+var rawCategoryScores = func() [][numCategories]int {
+	out := make([][numCategories]int, len(multisets.rollDice))
+	for r, dice := range multisets.rollDice {
+		for c, cat := range AllCategories {
+			out[r][c] = CalcScore(cat, dice)
+		}
+	}
+	return out
+}()
+
+// reachableUpper[m] has bit u set when an upper subtotal of u (capped) can be
+// reached with exactly the upper categories in m filled.
+var reachableUpper = func() [64]uint64 {
+	var out [64]uint64
+	for m := 0; m < 64; m++ {
+		set := uint64(1)
+		for face := 1; face <= 6; face++ {
+			if m&(1<<(face-1)) == 0 {
+				continue
+			}
+			var next uint64
+			for u := 0; u < upperStates; u++ {
+				if set&(1<<u) == 0 {
+					continue
+				}
+				for n := 0; n <= 5; n++ {
+					next |= 1 << min(u+n*face, UpperBonusThreshold)
+				}
+			}
+			set = next
+		}
+		out[m] = set
+	}
+	return out
+}()
+
+func optimalIndex(mask, upper, yahtzee50 int) int {
+	return (mask*upperStates+upper)*2 + yahtzee50
+}
+
+// OptimalTable holds the expected remaining score of every solitaire state
+// under optimal play. Build it with SolveOptimalTable or load a precomputed
+// one with LoadOptimalTable / ReadOptimalTable (e.g. from a go:embed file).
+type OptimalTable struct {
+	values []float64
+}
+
+// ExpectedScore returns the expected final score of a new game under optimal
+// play (about 254.59).
+func (t *OptimalTable) ExpectedScore() float64 {
+	return t.values[optimalIndex(0, 0, 0)]
+}
+
+func (t *OptimalTable) value(mask, upper, yahtzee50 int) float64 {
+	return t.values[optimalIndex(mask, upper, yahtzee50)]
+}
+
+// SolveOptimalTable solves every state. It takes on the order of a minute
+// on a single core; callers normally precompute once and save the result.
+func SolveOptimalTable() *OptimalTable {
+	return solveOptimalTable(0)
+}
+
+// solveOptimalTable solves states with at least minFilled categories filled.
+// Smaller values leave the remaining states at zero.
+func solveOptimalTable(minFilled int) *OptimalTable {
+	t := &OptimalTable{values: make([]float64, optimalStates)}
+	ts := newTurnSolver(t)
+	for filled := numCategories - 1; filled >= minFilled; filled-- {
+		for mask := 0; mask < allFilledMask; mask++ {
+			if bits.OnesCount(uint(mask)) != filled {
+				continue
+			}
+			flags := 1
+			if mask&(1<<yahtzeeCatIndex) != 0 {
+				flags = 2
+			}
+			reachable := reachableUpper[mask&63]
+			for u := 0; u < upperStates; u++ {
+				if reachable&(1<<u) == 0 {
+					continue
+				}
+				for f := 0; f < flags; f++ {
+					t.values[optimalIndex(mask, u, f)] = ts.stateValue(mask, u, f)
+				}
+			}
+		}
+	}
+	return t
+}
+
+// turnSolver evaluates a single turn from the table. Its buffers make it
+// unsafe for concurrent use.
+type turnSolver struct {
+	table  *OptimalTable
+	final  []float64 // value of each roll when it must be scored
+	keepEV []float64
+	roll   []float64
+}
+
+func newTurnSolver(t *OptimalTable) *turnSolver {
+	return &turnSolver{
+		table:  t,
+		final:  make([]float64, len(multisets.rolls)),
+		keepEV: make([]float64, len(multisets.keeps)),
+		roll:   make([]float64, len(multisets.rolls)),
+	}
+}
+
+// stateValue returns the expected remaining score at the start of a turn.
+func (ts *turnSolver) stateValue(mask, upper, y50 int) float64 {
+	ts.fillFinal(mask, upper, y50)
+	keepExpectations(ts.final, ts.keepEV)
+	bestKeepValues(ts.keepEV, ts.roll)
+	keepExpectations(ts.roll, ts.keepEV)
+	bestKeepValues(ts.keepEV, ts.roll)
+	ev := 0.0
+	for r, p := range multisets.rollProb {
+		ev += p * ts.roll[r]
+	}
+	return ev
+}
+
+// fillFinal sets ts.final[r] to the best score-plus-future for each roll r.
+func (ts *turnSolver) fillFinal(mask, upper, y50 int) {
+	for r := range multisets.rolls {
+		_, ts.final[r] = ts.bestPlacement(mask, upper, y50, r, allFilledMask)
+	}
+}
+
+// bestPlacement returns the best category (as an index) for roll r among the
+// legal categories also present in allowed, and its value.
+func (ts *turnSolver) bestPlacement(mask, upper, y50, r, allowed int) (int, float64) {
+	legal := legalPlacements(mask, r) & allowed
+	bestCat, best := -1, math.Inf(-1)
+	for c := 0; c < numCategories; c++ {
+		if legal&(1<<c) == 0 {
+			continue
+		}
+		reward, nmask, nupper, ny50 := placement(mask, upper, y50, r, c)
+		v := float64(reward) + ts.table.value(nmask, nupper, ny50)
+		if v > best {
+			bestCat, best = c, v
+		}
+	}
+	return bestCat, best
+}
+
+func isYahtzeeRoll(r int) bool {
+	for _, c := range multisets.rolls[r] {
+		if c == 5 {
+			return true
+		}
+	}
+	return false
+}
+
+// legalPlacements returns the categories roll r may be scored in, applying
+// the Joker rules for extra Yahtzees.
+func legalPlacements(mask, r int) int {
+	open := allFilledMask &^ mask
+	if mask&(1<<yahtzeeCatIndex) == 0 || !isYahtzeeRoll(r) {
+		return open
+	}
+	face := multisets.rollDice[r][0]
+	if upper := 1 << (face - 1); open&upper != 0 {
+		return upper
+	}
+	if lower := open &^ 63; lower != 0 {
+		return lower
+	}
+	return open
+}
+
+// placement returns the points earned (including bonuses) by scoring roll r
+// in category c, and the resulting state.
+func placement(mask, upper, y50, r, c int) (reward, nmask, nupper, ny50 int) {
+	score := rawCategoryScores[r][c]
+	joker := mask&(1<<yahtzeeCatIndex) != 0 && isYahtzeeRoll(r)
+	if joker {
+		if s, ok := jokerScores[AllCategories[c]]; ok {
+			score = s
+		}
+		if y50 == 1 {
+			reward += YahtzeeBonusValue
+		}
+	}
+	reward += score
+
+	nmask, nupper, ny50 = mask|1<<c, upper, y50
+	if c < 6 {
+		nupper = min(upper+score, UpperBonusThreshold)
+		if upper < UpperBonusThreshold && nupper >= UpperBonusThreshold {
+			reward += UpperBonusValue
+		}
+	}
+	if c == yahtzeeCatIndex && score == 50 {
+		ny50 = 1
+	}
+	return reward, nmask, nupper, ny50
+}
+
+// Table file format: gzip-compressed, a fixed header followed by one
+// little-endian float32 per state. Values are solved in float64 and only
+// rounded when written.
+const (
+	optimalTableMagic   = "YATZOPT"
+	optimalTableVersion = 1
+)
+
+// WriteTo writes the table in the compact file format.
+func (t *OptimalTable) WriteTo(w io.Writer) (int64, error) {
+	cw := &countingWriter{w: w}
+	zw := gzip.NewWriter(cw)
+	bw := bufio.NewWriter(zw)
+	if _, err := bw.WriteString(optimalTableMagic); err != nil {
+		return cw.n, err
+	}
+	header := []uint32{optimalTableVersion, uint32(len(t.values))}
+	if err := binary.Write(bw, binary.LittleEndian, header); err != nil {
+		return cw.n, err
+	}
+	values := make([]float32, len(t.values))
+	for i, v := range t.values {
+		values[i] = float32(v)
+	}
+	if err := binary.Write(bw, binary.LittleEndian, values); err != nil {
+		return cw.n, err
+	}
+	if err := bw.Flush(); err != nil {
+		return cw.n, err
+	}
+	if err := zw.Close(); err != nil {
+		return cw.n, err
+	}
+	return cw.n, nil
+}
+
+// ReadOptimalTable reads a table written by WriteTo.
+func ReadOptimalTable(r io.Reader) (*OptimalTable, error) {
+	zr, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("open optimal table: %w", err)
+	}
+	defer zr.Close()
+
+	magic := make([]byte, len(optimalTableMagic))
+	if _, err := io.ReadFull(zr, magic); err != nil {
+		return nil, fmt.Errorf("read optimal table header: %w", err)
+	}
+	if string(magic) != optimalTableMagic {
+		return nil, errors.New("not an optimal strategy table")
+	}
+	var header [2]uint32
+	if err := binary.Read(zr, binary.LittleEndian, &header); err != nil {
+		return nil, fmt.Errorf("read optimal table header: %w", err)
+	}
+	if header[0] != optimalTableVersion {
+		return nil, fmt.Errorf("unsupported optimal table version %d (want %d)", header[0], optimalTableVersion)
+	}
+	if header[1] != optimalStates {
+		return nil, fmt.Errorf("optimal table has %d states, want %d", header[1], optimalStates)
+	}
+	values := make([]float32, optimalStates)
+	if err := binary.Read(zr, binary.LittleEndian, values); err != nil {
+		return nil, fmt.Errorf("read optimal table values: %w", err)
+	}
+	t := &OptimalTable{values: make([]float64, optimalStates)}
+	for i, v := range values {
+		t.values[i] = float64(v)
+	}
+	return t, nil
+}
+
+// SaveOptimalTable writes the table to path.
+func SaveOptimalTable(t *OptimalTable, path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if _, err := t.WriteTo(f); err != nil {
+		f.Close()
+		return fmt.Errorf("write optimal table: %w", err)
+	}
+	return f.Close()
+}
+
+// LoadOptimalTable reads a table saved with SaveOptimalTable.
+func LoadOptimalTable(path string) (*OptimalTable, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ReadOptimalTable(bufio.NewReader(f))
+}
+
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (cw *countingWriter) Write(p []byte) (int, error) {
+	n, err := cw.w.Write(p)
+	cw.n += int64(n)
+	return n, err
+}
@@ -0,0 +1,155 @@
+package engine
+
+import (
+	"bytes"
+	"math"
+	"math/rand"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMultisetTables(t *testing.T) {
+	assert.Len(t, multisets.rolls, 252)
+	assert.Len(t, multisets.keeps, 462)
+
+	total := 0.0
+	for _, p := range multisets.rollProb {
+		total += p
+	}
+	assert.InDelta(t, 1.0, total, 1e-12)
+
+	for k, outcomes := range multisets.keepOutcomes {
+		sum := 0.0
+		for _, o := range outcomes {
+			sum += o.prob
+		}
+		assert.InDelta(t, 1.0, sum, 1e-12, "keep %v", multisets.keeps[k])
+	}
+
+	// A roll with five distinct faces contains 2^5 keeps; a Yahtzee only 6.
+	assert.Len(t, multisets.rollSubKeeps[rollIndex([5]int{1, 2, 3, 4, 5})], 32)
+	assert.Len(t, multisets.rollSubKeeps[rollIndex([5]int{4, 4, 4, 4, 4})], 6)
+}
+
+func TestHoldIndicesForKeep(t *testing.T) {
+	keep := countsOfDice([5]int{6, 6, 0, 0, 0})
+	assert.Equal(t, []int{1, 3}, holdIndicesForKeep([5]int{2, 6, 3, 6, 6}, keep))
+}
+
+// scorecardWithOpen returns a scorecard where every category except open is
+// filled with zero.
+func scorecardWithOpen(open ...Category) Scorecard {
+	sc := NewScorecard()
+	for _, c := range AllCategories {
+		if !containsCategory(open, c) {
+			sc.Fill(c, 0)
+		}
+	}
+	return sc
+}
+
+func TestOptimalTable_LastTurn(t *testing.T) {
+	table := solveOptimalTable(numCategories - 1)
+
+	// Chance alone: each die is rerolled independently, worth 14/3 per die.
+	mask, upper, y50 := optimalState(scorecardWithOpen(Chance))
+	assert.InDelta(t, 70.0/3, table.value(mask, upper, y50), 1e-9)
+
+	// Yahtzee alone: the chance of a Yahtzee within three rolls is ~4.603%.
+	mask, upper, y50 = optimalState(scorecardWithOpen(Yahtzee))
+	assert.InDelta(t, 50*0.04603, table.value(mask, upper, y50), 0.01)
+}
+
+func TestOptimalTable_RoundTrip(t *testing.T) {
+	table := solveOptimalTable(numCategories - 1)
+
+	var buf bytes.Buffer
+	n, err := table.WriteTo(&buf)
+	require.NoError(t, err)
+	assert.Equal(t, int64(buf.Len()), n)
+
+	loaded, err := ReadOptimalTable(&buf)
+	require.NoError(t, err)
+	for i, v := range table.values {
+		require.InDelta(t, v, loaded.values[i], 1e-4)
+	}
+}
+
+func TestReadOptimalTable_Invalid(t *testing.T) {
+	_, err := ReadOptimalTable(bytes.NewReader([]byte("not gzip")))
+	assert.Error(t, err)
+}
+
+func TestOptimalStrategy_Decisions(t *testing.T) {
+	s, err := NewOptimalStrategy(solveOptimalTable(numCategories - 1))
+	require.NoError(t, err)
+	assert.Equal(t, "optimal", s.Name())
+
+	sc := scorecardWithOpen(Yahtzee)
+	action := s.DecideAction([5]int{6, 2, 6, 6, 6}, 1, sc, sc.AvailableCategories())
+	assert.Equal(t, "hold", action.Type)
+	assert.Equal(t, []int{0, 2, 3, 4}, action.Indices)
+
+	// With two rerolls left only fives and sixes are worth keeping for Chance.
+	sc = scorecardWithOpen(Chance)
+	action = s.DecideAction([5]int{6, 4, 5, 1, 3}, 1, sc, sc.AvailableCategories())
+	assert.Equal(t, "hold", action.Type)
+	assert.Equal(t, []int{0, 2}, action.Indices)
+
+	// With one reroll left fours are kept as well.
+	action = s.DecideAction([5]int{6, 4, 5, 1, 3}, 2, sc, sc.AvailableCategories())
+	assert.Equal(t, "hold", action.Type)
+	assert.Equal(t, []int{0, 1, 2}, action.Indices)
+
+	action = s.DecideAction([5]int{6, 6, 5, 5, 6}, 1, sc, sc.AvailableCategories())
+	assert.Equal(t, TurnAction{Type: "score", Category: Chance}, action)
+
+	action = s.DecideAction([5]int{1, 1, 1, 1, 2}, 3, sc, sc.AvailableCategories())
+	assert.Equal(t, TurnAction{Type: "score", Category: Chance}, action)
+}
+
+func TestOptimalStrategy_FallsBackForOtherRules(t *testing.T) {
+	s := &OptimalStrategy{Table: &OptimalTable{}}
+	sc := NewScorecardWithRules(&YachtRules{})
+	action := s.DecideAction([5]int{1, 2, 3, 4, 6}, 3, sc, sc.AvailableCategories())
+	assert.Equal(t, "score", action.Type)
+}
+
+func TestOptimalStrategy_RequiresTable(t *testing.T) {
+	_, err := NewOptimalStrategy(nil)
+	assert.ErrorIs(t, err, ErrNoOptimalTable)
+
+	// The zero value still plays, as StatisticalStrategy.
+	sc := NewScorecard()
+	dice := [5]int{1, 2, 3, 4, 6}
+	assert.Equal(t, (&StatisticalStrategy{}).DecideAction(dice, 1, sc, sc.AvailableCategories()),
+		(&OptimalStrategy{}).DecideAction(dice, 1, sc, sc.AvailableCategories()))
+}
+
+func TestOptimalTable_FullSolve(t *testing.T) {
+	if os.Getenv("YATZ_FULL_SOLVE") == "" {
+		t.Skip("full solve takes about a minute; set YATZ_FULL_SOLVE=1 to run it")
+	}
+	table := SolveOptimalTable()
+	assert.InDelta(t, 254.59, table.ExpectedScore(), 0.01)
+
+	s, err := NewOptimalStrategy(table)
+	require.NoError(t, err)
+	const games = 300
+	total := 0
+	for i := 0; i < games; i++ {
+		g := NewGame([]string{"Solo"}, rand.NewSource(int64(i)))
+		ai := NewAIPlayerWithStrategy(g, "player-0", s)
+		for g.Phase != PhaseFinished {
+			_, err := ai.PlayTurn()
+			require.NoError(t, err)
+		}
+		total += g.Players[0].Scorecard.Total()
+	}
+	avg := float64(total) / games
+	t.Logf("optimal strategy average over %d games: %.1f", games, avg)
+	assert.Less(t, math.Abs(avg-table.ExpectedScore()), 12.0)
+}
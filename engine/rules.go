@@ -0,0 +1,72 @@
+package engine
+
+import (
+	"fmt"
+	"sort"
+)
+
+// RuleSet defines the categories, scoring and bonuses of a dice game variant.
+// A Game and all of its scorecards share a single RuleSet; the number of
+// rounds equals the number of categories.
+type RuleSet interface {
+	// Name identifies the rule set (e.g. "yahtzee"). It is used in
+	// serialized scorecards and game states.
+	Name() string
+	// Categories lists every scoring category in scorecard order.
+	Categories() []Category
+	// UpperCategories lists the categories counted toward the upper bonus.
+	UpperCategories() []Category
+	// UpperBonus returns the upper subtotal needed for the bonus and its
+	// value. A value of 0 disables the bonus.
+	UpperBonus() (threshold, value int)
+	// YahtzeeBonus returns the points awarded for each extra Yahtzee.
+	// 0 disables the bonus.
+	YahtzeeBonus() int
+	// Score returns the points dice would record in c on scorecard sc.
+	Score(c Category, dice [5]int, sc Scorecard) int
+	// Placeable returns the open categories dice may legally be scored in.
+	Placeable(dice [5]int, sc Scorecard) []Category
+}
+
+var ruleSets = map[string]RuleSet{}
+
+// RegisterRuleSet makes a rule set available to RuleSetByName.
+// Registering the same name twice replaces the earlier entry.
+func RegisterRuleSet(rs RuleSet) {
+	ruleSets[rs.Name()] = rs
+}
+
+func init() {
+	RegisterRuleSet(&YahtzeeRules{})
+	RegisterRuleSet(&YachtRules{})
+	RegisterRuleSet(&GeneralaRules{})
+	RegisterRuleSet(&KniffelRules{})
+}
+
+// DefaultRules returns the rule set used when none is specified.
+func DefaultRules() RuleSet {
+	return &YahtzeeRules{}
+}
+
+// RuleSetByName returns the registered rule set with the given name.
+// An empty name selects DefaultRules.
+func RuleSetByName(name string) (RuleSet, error) {
+	if name == "" {
+		return DefaultRules(), nil
+	}
+	rs, ok := ruleSets[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown rule set %q (available: %v)", name, RuleSetNames())
+	}
+	return rs, nil
+}
+
+// RuleSetNames returns the names of all registered rule sets, sorted.
+func RuleSetNames() []string {
+	names := make([]string, 0, len(ruleSets))
+	for name := range ruleSets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,65 @@
+package engine
+
+// Categories used only by the Generala rules.
+const (
+	Escalera       Category = "escalera"
+	Full           Category = "full"
+	Poker          Category = "poker"
+	Generala       Category = "generala"
+	DoubleGenerala Category = "double_generala"
+)
+
+var generalaCategories = []Category{
+	Ones, Twos, Threes, Fours, Fives, Sixes,
+	Escalera, Full, Poker, Generala, DoubleGenerala,
+}
+
+// GeneralaRules implements the Latin American Generala rules with 11
+// categories and no upper bonus. Double Generala only scores after a 50 in
+// Generala. The "served" (first roll) bonuses are not applied.
+type GeneralaRules struct{}
+
+func (r *GeneralaRules) Name() string { return "generala" }
+
+func (r *GeneralaRules) Categories() []Category { return generalaCategories }
+
+func (r *GeneralaRules) UpperCategories() []Category { return UpperCategories }
+
+func (r *GeneralaRules) UpperBonus() (int, int) { return 0, 0 }
+
+func (r *GeneralaRules) YahtzeeBonus() int { return 0 }
+
+func (r *GeneralaRules) Score(c Category, dice [5]int, sc Scorecard) int {
+	switch c {
+	case Escalera:
+		if hasStraight(dice, 5) {
+			return 20
+		}
+		return 0
+	case Full:
+		if isFullHouse(dice) {
+			return 30
+		}
+		return 0
+	case Poker:
+		if hasNOfAKind(dice, 4) {
+			return 40
+		}
+		return 0
+	case Generala:
+		if hasNOfAKind(dice, 5) {
+			return 50
+		}
+		return 0
+	case DoubleGenerala:
+		if hasNOfAKind(dice, 5) && sc.GetScore(Generala) > 0 {
+			return 100
+		}
+		return 0
+	}
+	return CalcScore(c, dice)
+}
+
+func (r *GeneralaRules) Placeable(dice [5]int, sc Scorecard) []Category {
+	return sc.AvailableCategories()
+}
@@ -0,0 +1,26 @@
+package engine
+
+// KniffelRules implements the German Kniffel rules. The categories and
+// upper bonus match Yahtzee, but extra Kniffels earn no bonus and may be
+// scored in any open category.
+type KniffelRules struct{}
+
+func (r *KniffelRules) Name() string { return "kniffel" }
+
+func (r *KniffelRules) Categories() []Category { return AllCategories }
+
+func (r *KniffelRules) UpperCategories() []Category { return UpperCategories }
+
+func (r *KniffelRules) UpperBonus() (int, int) {
+	return UpperBonusThreshold, UpperBonusValue
+}
+
+func (r *KniffelRules) YahtzeeBonus() int { return 0 }
+
+func (r *KniffelRules) Score(c Category, dice [5]int, sc Scorecard) int {
+	return CalcScore(c, dice)
+}
+
+func (r *KniffelRules) Placeable(dice [5]int, sc Scorecard) []Category {
+	return sc.AvailableCategories()
+}
@@ -0,0 +1,141 @@
+package engine
+
+import (
+	"encoding/json"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRuleSetByName(t *testing.T) {
+	for _, name := range []string{"yahtzee", "yacht", "generala", "kniffel"} {
+		rs, err := RuleSetByName(name)
+		require.NoError(t, err)
+		assert.Equal(t, name, rs.Name())
+	}
+
+	rs, err := RuleSetByName("")
+	require.NoError(t, err)
+	assert.Equal(t, "yahtzee", rs.Name())
+
+	_, err = RuleSetByName("farkle")
+	assert.Error(t, err)
+}
+
+func TestYachtRules_Score(t *testing.T) {
+	r := &YachtRules{}
+	sc := NewScorecardWithRules(r)
+
+	tests := []struct {
+		name     string
+		category Category
+		dice     [5]int
+		want     int
+	}{
+		{"full house is dice total", FullHouse, [5]int{3, 3, 3, 5, 5}, 19},
+		{"yacht is not a full house", FullHouse, [5]int{4, 4, 4, 4, 4}, 0},
+		{"four of a kind counts four dice", FourOfAKind, [5]int{6, 6, 6, 6, 2}, 24},
+		{"little straight", LittleStraight, [5]int{5, 4, 3, 2, 1}, 30},
+		{"little straight rejects 2-6", LittleStraight, [5]int{2, 3, 4, 5, 6}, 0},
+		{"big straight", BigStraight, [5]int{2, 3, 4, 5, 6}, 30},
+		{"choice", Choice, [5]int{1, 2, 3, 4, 6}, 16},
+		{"yacht", Yacht, [5]int{2, 2, 2, 2, 2}, 50},
+		{"upper", Fives, [5]int{5, 5, 1, 2, 5}, 15},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, r.Score(tt.category, tt.dice, sc))
+		})
+	}
+}
+
+func TestYachtRules_NoBonus(t *testing.T) {
+	sc := NewScorecardWithRules(&YachtRules{})
+	for _, c := range UpperCategories {
+		sc.Fill(c, 30)
+	}
+	assert.False(t, sc.HasUpperBonus())
+	assert.Equal(t, 180, sc.Total())
+	assert.Len(t, sc.AvailableCategories(), 6)
+}
+
+func TestGeneralaRules_DoubleGenerala(t *testing.T) {
+	r := &GeneralaRules{}
+	sc := NewScorecardWithRules(r)
+	dice := [5]int{3, 3, 3, 3, 3}
+
+	assert.Equal(t, 0, r.Score(DoubleGenerala, dice, sc), "double generala requires a scored generala")
+	sc.Fill(Generala, 50)
+	assert.Equal(t, 100, r.Score(DoubleGenerala, dice, sc))
+
+	assert.Equal(t, 20, r.Score(Escalera, [5]int{2, 3, 4, 5, 6}, sc))
+	assert.Equal(t, 30, r.Score(Full, [5]int{1, 1, 6, 6, 6}, sc))
+	assert.Equal(t, 40, r.Score(Poker, [5]int{4, 4, 4, 4, 1}, sc))
+}
+
+func TestKniffelRules_NoJoker(t *testing.T) {
+	sc := NewScorecardWithRules(&KniffelRules{})
+	sc.Fill(Yahtzee, 50)
+	dice := [5]int{6, 6, 6, 6, 6}
+
+	assert.False(t, EarnsYahtzeeBonus(dice, sc))
+	assert.Equal(t, 0, ScoreFor(LargeStraight, dice, sc))
+	assert.Len(t, PlaceableCategories(dice, sc), 12)
+}
+
+func TestScorecard_RulesJSONRoundTrip(t *testing.T) {
+	sc := NewScorecardWithRules(&YachtRules{})
+	sc.Fill(Yacht, 50)
+
+	data, err := json.Marshal(sc)
+	require.NoError(t, err)
+
+	var got Scorecard
+	require.NoError(t, json.Unmarshal(data, &got))
+	assert.Equal(t, "yacht", got.Rules().Name())
+	assert.Equal(t, 50, got.GetScore(Yacht))
+	assert.Len(t, got.AvailableCategories(), 11)
+
+	// Default rules are not written, keeping classic scorecards unchanged.
+	data, err = json.Marshal(NewScorecard())
+	require.NoError(t, err)
+	assert.Equal(t, "{}", string(data))
+}
+
+func TestNewGameWithRules_RoundsFollowCategories(t *testing.T) {
+	for _, rs := range []RuleSet{&YachtRules{}, &GeneralaRules{}} {
+		t.Run(rs.Name(), func(t *testing.T) {
+			g := NewGameWithRules([]string{"Solo"}, rand.NewSource(7), rs)
+			for _, cat := range rs.Categories() {
+				require.NotEqual(t, PhaseFinished, g.Phase)
+				require.NoError(t, g.Roll())
+				require.NoError(t, g.Score(cat))
+			}
+			assert.Equal(t, PhaseFinished, g.Phase)
+			assert.Equal(t, len(rs.Categories()), g.GetState().MaxRounds)
+		})
+	}
+}
+
+func TestGame_Score_RejectsCategoryOutsideRules(t *testing.T) {
+	g := NewGameWithRules([]string{"Solo"}, rand.NewSource(7), &YachtRules{})
+	require.NoError(t, g.Roll())
+	assert.Error(t, g.Score(SmallStraight))
+}
+
+func TestRunBattle_WithRules(t *testing.T) {
+	state, err := RunBattle(BattleConfig{
+		Players: []BattlePlayer{
+			{Name: "A", Strategy: &GreedyStrategy{}},
+			{Name: "B", Strategy: &StatisticalStrategy{}},
+		},
+		Seed:  42,
+		Rules: &YachtRules{},
+	})
+	require.NoError(t, err)
+	for _, p := range state.Players {
+		assert.Empty(t, p.Scorecard.AvailableCategories(), "player %s should fill all categories", p.Name)
+	}
+}
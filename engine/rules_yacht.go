@@ -0,0 +1,68 @@
+package engine
+
+// Categories used only by the classic Yacht rules.
+const (
+	LittleStraight Category = "little_straight"
+	BigStraight    Category = "big_straight"
+	Choice         Category = "choice"
+	Yacht          Category = "yacht"
+)
+
+var yachtCategories = []Category{
+	Ones, Twos, Threes, Fours, Fives, Sixes,
+	FullHouse, FourOfAKind, LittleStraight, BigStraight, Choice, Yacht,
+}
+
+// YachtRules implements classic Yacht: 12 categories and no bonuses.
+// Full House scores the dice total, Four of a Kind scores the four matching
+// dice, and the straights are fixed at 1-2-3-4-5 and 2-3-4-5-6.
+type YachtRules struct{}
+
+func (r *YachtRules) Name() string { return "yacht" }
+
+func (r *YachtRules) Categories() []Category { return yachtCategories }
+
+func (r *YachtRules) UpperCategories() []Category { return UpperCategories }
+
+func (r *YachtRules) UpperBonus() (int, int) { return 0, 0 }
+
+func (r *YachtRules) YahtzeeBonus() int { return 0 }
+
+func (r *YachtRules) Score(c Category, dice [5]int, sc Scorecard) int {
+	switch c {
+	case FullHouse:
+		if isFullHouse(dice) {
+			return sum(dice)
+		}
+		return 0
+	case FourOfAKind:
+		for face := 1; face <= 6; face++ {
+			if countValue(dice, face) >= 4 {
+				return face * 4
+			}
+		}
+		return 0
+	case LittleStraight:
+		if hasStraight(dice, 5) && countValue(dice, 1) == 1 {
+			return 30
+		}
+		return 0
+	case BigStraight:
+		if hasStraight(dice, 5) && countValue(dice, 6) == 1 {
+			return 30
+		}
+		return 0
+	case Choice:
+		return sum(dice)
+	case Yacht:
+		if hasNOfAKind(dice, 5) {
+			return 50
+		}
+		return 0
+	}
+	return CalcScore(c, dice)
+}
+
+func (r *YachtRules) Placeable(dice [5]int, sc Scorecard) []Category {
+	return sc.AvailableCategories()
+}
@@ -0,0 +1,32 @@
+package engine
+
+// YahtzeeRules implements the official Yahtzee rules: 13 categories, a 35
+// point upper bonus at 63, a 100 point bonus for each extra Yahtzee and the
+// forced Joker rule for placing extra Yahtzees.
+type YahtzeeRules struct{}
+
+func (r *YahtzeeRules) Name() string { return "yahtzee" }
+
+func (r *YahtzeeRules) Categories() []Category { return AllCategories }
+
+func (r *YahtzeeRules) UpperCategories() []Category { return UpperCategories }
+
+func (r *YahtzeeRules) UpperBonus() (int, int) {
+	return UpperBonusThreshold, UpperBonusValue
+}
+
+func (r *YahtzeeRules) YahtzeeBonus() int { return YahtzeeBonusValue }
+
+func (r *YahtzeeRules) Score(c Category, dice [5]int, sc Scorecard) int {
+	switch c {
+	case FullHouse, SmallStraight, LargeStraight:
+		if IsJoker(dice, sc) {
+			return jokerScores[c]
+		}
+	}
+	return CalcScore(c, dice)
+}
+
+func (r *YahtzeeRules) Placeable(dice [5]int, sc Scorecard) []Category {
+	return jokerPlaceable(dice, sc)
+}
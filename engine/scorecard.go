@@ -1,27 +1,82 @@
 package engine
 
-import "encoding/json"
+import (
+	"encoding/json"
+	"fmt"
+)
 
 type Scorecard struct {
-	scores map[Category]*int
+	scores       map[Category]*int
+	yahtzeeBonus int     // number of extra Yahtzees scored after a 50 in the Yahtzee box
+	rules        RuleSet // nil means DefaultRules
 }
 
+// JSON keys that are not categories. Both are omitted at their defaults so
+// classic Yahtzee scorecards serialize as a plain category map.
+const (
+	yahtzeeBonusKey = "yahtzee_bonus"
+	rulesKey        = "rules"
+)
+
 func (sc Scorecard) MarshalJSON() ([]byte, error) {
-	m := make(map[string]*int, len(sc.scores))
+	m := make(map[string]any, len(sc.scores)+2)
 	for k, v := range sc.scores {
 		m[string(k)] = v
 	}
+	if sc.yahtzeeBonus > 0 {
+		m[yahtzeeBonusKey] = sc.yahtzeeBonus
+	}
+	if sc.rules != nil && sc.rules.Name() != DefaultRules().Name() {
+		m[rulesKey] = sc.rules.Name()
+	}
 	return json.Marshal(m)
 }
 
+// clone returns a copy that shares no score storage with sc.
+func (sc Scorecard) clone() Scorecard {
+	c := sc
+	c.scores = make(map[Category]*int, len(sc.scores))
+	for k, v := range sc.scores {
+		if v == nil {
+			continue
+		}
+		score := *v
+		c.scores[k] = &score
+	}
+	return c
+}
+
 func (sc *Scorecard) UnmarshalJSON(data []byte) error {
-	var m map[string]*int
+	var m map[string]json.RawMessage
 	if err := json.Unmarshal(data, &m); err != nil {
 		return err
 	}
 	sc.scores = make(map[Category]*int, len(m))
-	for k, v := range m {
-		sc.scores[Category(k)] = v
+	sc.yahtzeeBonus = 0
+	sc.rules = nil
+	for k, raw := range m {
+		switch k {
+		case yahtzeeBonusKey:
+			if err := json.Unmarshal(raw, &sc.yahtzeeBonus); err != nil {
+				return fmt.Errorf("decode %s: %w", yahtzeeBonusKey, err)
+			}
+		case rulesKey:
+			var name string
+			if err := json.Unmarshal(raw, &name); err != nil {
+				return fmt.Errorf("decode %s: %w", rulesKey, err)
+			}
+			rs, err := RuleSetByName(name)
+			if err != nil {
+				return err
+			}
+			sc.rules = rs
+		default:
+			var v *int
+			if err := json.Unmarshal(raw, &v); err != nil {
+				return fmt.Errorf("decode %s: %w", k, err)
+			}
+			sc.scores[Category(k)] = v
+		}
 	}
 	return nil
 }
@@ -30,6 +85,19 @@ func NewScorecard() Scorecard {
 	return Scorecard{scores: make(map[Category]*int)}
 }
 
+// NewScorecardWithRules returns an empty scorecard for the given rule set.
+func NewScorecardWithRules(rules RuleSet) Scorecard {
+	return Scorecard{scores: make(map[Category]*int), rules: rules}
+}
+
+// Rules returns the rule set this scorecard is scored under.
+func (sc *Scorecard) Rules() RuleSet {
+	if sc.rules == nil {
+		return DefaultRules()
+	}
+	return sc.rules
+}
+
 func (sc *Scorecard) Fill(c Category, score int) {
 	s := score
 	sc.scores[c] = &s
@@ -48,7 +116,7 @@ func (sc *Scorecard) GetScore(c Category) int {
 
 func (sc *Scorecard) AvailableCategories() []Category {
 	var avail []Category
-	for _, c := range AllCategories {
+	for _, c := range sc.Rules().Categories() {
 		if !sc.IsFilled(c) {
 			avail = append(avail, c)
 		}
@@ -58,23 +126,47 @@ func (sc *Scorecard) AvailableCategories() []Category {
 
 func (sc *Scorecard) UpperTotal() int {
 	total := 0
-	for _, c := range UpperCategories {
+	for _, c := range sc.Rules().UpperCategories() {
 		total += sc.GetScore(c)
 	}
 	return total
 }
 
 func (sc *Scorecard) HasUpperBonus() bool {
-	return sc.UpperTotal() >= UpperBonusThreshold
+	threshold, value := sc.Rules().UpperBonus()
+	return value > 0 && sc.UpperTotal() >= threshold
+}
+
+// UpperBonus returns the upper bonus points earned so far.
+func (sc *Scorecard) UpperBonus() int {
+	if !sc.HasUpperBonus() {
+		return 0
+	}
+	_, value := sc.Rules().UpperBonus()
+	return value
 }
 
 func (sc *Scorecard) Total() int {
 	total := 0
-	for _, c := range AllCategories {
+	for _, c := range sc.Rules().Categories() {
 		total += sc.GetScore(c)
 	}
-	if sc.HasUpperBonus() {
-		total += UpperBonusValue
-	}
+	total += sc.UpperBonus()
+	total += sc.YahtzeeBonus()
 	return total
 }
+
+// AddYahtzeeBonus records one extra Yahtzee bonus.
+func (sc *Scorecard) AddYahtzeeBonus() {
+	sc.yahtzeeBonus++
+}
+
+// YahtzeeBonusCount returns the number of extra Yahtzees that earned a bonus.
+func (sc *Scorecard) YahtzeeBonusCount() int {
+	return sc.yahtzeeBonus
+}
+
+// YahtzeeBonus returns the total Yahtzee bonus points.
+func (sc *Scorecard) YahtzeeBonus() int {
+	return sc.yahtzeeBonus * sc.Rules().YahtzeeBonus()
+}
@@ -135,3 +135,44 @@ func TestScorecardJSON(t *testing.T) {
 		t.Error("expected Twos to be unfilled after round-trip")
 	}
 }
+
+func TestYahtzeeBonus(t *testing.T) {
+	sc := NewScorecard()
+	sc.Fill(Yahtzee, 50)
+	sc.AddYahtzeeBonus()
+	sc.AddYahtzeeBonus()
+
+	if got := sc.YahtzeeBonusCount(); got != 2 {
+		t.Errorf("expected bonus count 2, got %d", got)
+	}
+	if got := sc.YahtzeeBonus(); got != 2*YahtzeeBonusValue {
+		t.Errorf("expected bonus %d, got %d", 2*YahtzeeBonusValue, got)
+	}
+	if got := sc.Total(); got != 50+2*YahtzeeBonusValue {
+		t.Errorf("expected total %d, got %d", 50+2*YahtzeeBonusValue, got)
+	}
+}
+
+func TestYahtzeeBonus_JSONRoundTrip(t *testing.T) {
+	sc := NewScorecard()
+	sc.Fill(Yahtzee, 50)
+	sc.AddYahtzeeBonus()
+
+	data, err := json.Marshal(sc)
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	var got Scorecard
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	if got.YahtzeeBonusCount() != 1 {
+		t.Errorf("expected bonus count 1 after round trip, got %d", got.YahtzeeBonusCount())
+	}
+	if got.IsFilled(Category(yahtzeeBonusKey)) {
+		t.Error("bonus key must not be treated as a category")
+	}
+	if got.Total() != sc.Total() {
+		t.Errorf("expected total %d, got %d", sc.Total(), got.Total())
+	}
+}
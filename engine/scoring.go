@@ -1,7 +1,5 @@
 package engine
 
-import "sort"
-
 func CalcScore(c Category, dice [5]int) int {
 	switch c {
 	case Ones:
@@ -70,18 +68,8 @@ func sum(dice [5]int) int {
 	return total
 }
 
-func counts(dice [5]int) map[int]int {
-	m := make(map[int]int)
-	for _, d := range dice {
-		if d > 0 {
-			m[d]++
-		}
-	}
-	return m
-}
-
 func hasNOfAKind(dice [5]int, n int) bool {
-	for _, cnt := range counts(dice) {
+	for _, cnt := range countsOfDice(dice) {
 		if cnt >= n {
 			return true
 		}
@@ -90,44 +78,36 @@ func hasNOfAKind(dice [5]int, n int) bool {
 }
 
 func isFullHouse(dice [5]int) bool {
-	c := counts(dice)
-	if len(c) != 2 {
-		return false
-	}
-	for _, cnt := range c {
+	faces := 0
+	pair := false
+	for _, cnt := range countsOfDice(dice) {
+		if cnt > 0 {
+			faces++
+		}
 		if cnt == 2 || cnt == 3 {
-			return true
+			pair = true
 		}
 	}
-	return false
+	return faces == 2 && pair
 }
 
 func hasStraight(dice [5]int, length int) bool {
-	sorted := make([]int, len(dice))
-	copy(sorted, dice[:])
-	sort.Ints(sorted)
-
-	// deduplicate
-	uniq := []int{sorted[0]}
-	for i := 1; i < len(sorted); i++ {
-		if sorted[i] != sorted[i-1] {
-			uniq = append(uniq, sorted[i])
+	var present [7]bool
+	for _, d := range dice {
+		if d >= 0 && d <= 6 {
+			present[d] = true
 		}
 	}
 
-	if len(uniq) < length {
-		return false
-	}
-
-	run := 1
-	for i := 1; i < len(uniq); i++ {
-		if uniq[i] == uniq[i-1]+1 {
-			run++
-			if run >= length {
-				return true
-			}
-		} else {
-			run = 1
+	run := 0
+	for _, p := range present {
+		if !p {
+			run = 0
+			continue
+		}
+		run++
+		if run >= length {
+			return true
 		}
 	}
 	return false
@@ -0,0 +1,85 @@
+package engine
+
+import (
+	"context"
+	"runtime"
+	"sync"
+	"time"
+)
+
+// SimulateConfig describes a batch of battles between the same players.
+type SimulateConfig struct {
+	// Players play every game. Their strategies play many games at once,
+	// so they must be safe for concurrent use.
+	Players []BattlePlayer
+	Games   int
+	// Seed is the seed of the first game; game i is played with Seed+i,
+	// whichever worker plays it. Zero picks a random first seed.
+	Seed    int64
+	Rules   RuleSet // nil uses DefaultRules
+	Workers int     // games played at once; GOMAXPROCS if zero
+	// OnEvent, if set, receives the events of every game, tagged with the
+	// game's index. It is called from the goroutine playing the game.
+	OnEvent func(game int, e Event)
+}
+
+// SimulateResult is one finished game of a simulation.
+type SimulateResult struct {
+	Game  int   // index, from 0 to Games-1
+	Seed  int64 // the seed the game was played with
+	State *GameState
+	Err   error
+}
+
+// Simulate plays cfg.Games battles on a pool of cfg.Workers goroutines and
+// streams each result as its game finishes, so results arrive in no
+// particular order; each game's outcome depends only on its seed. The
+// channel is closed once every game has been sent, or soon after ctx is
+// done: games already being played are finished but not sent.
+func Simulate(ctx context.Context, cfg SimulateConfig) <-chan SimulateResult {
+	workers := cfg.Workers
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	seed := cfg.Seed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+
+	out := make(chan SimulateResult, workers)
+	games := make(chan int)
+	var wg sync.WaitGroup
+	for range min(workers, max(cfg.Games, 0)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range games {
+				res := SimulateResult{Game: i, Seed: seed + int64(i)}
+				bc := BattleConfig{Players: cfg.Players, Seed: res.Seed, Rules: cfg.Rules}
+				if cfg.OnEvent != nil {
+					bc.OnEvent = func(e Event) { cfg.OnEvent(i, e) }
+				}
+				res.State, res.Err = RunBattle(bc)
+				select {
+				case out <- res:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+	}
+
+	go func() {
+		defer close(out)
+		defer wg.Wait()
+		defer close(games)
+		for i := range cfg.Games {
+			select {
+			case games <- i:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return out
+}
@@ -0,0 +1,100 @@
+package engine
+
+import (
+	"context"
+	"testing"
+)
+
+func simulatePlayers() []BattlePlayer {
+	return []BattlePlayer{
+		{Name: "A", Strategy: &GreedyStrategy{}},
+		{Name: "B", Strategy: &GreedyStrategy{}},
+	}
+}
+
+// collect drains results into a slice indexed by game.
+func collect(t *testing.T, ch <-chan SimulateResult, games int) []SimulateResult {
+	t.Helper()
+	results := make([]SimulateResult, games)
+	n := 0
+	for res := range ch {
+		if res.Err != nil {
+			t.Fatalf("game %d: %v", res.Game, res.Err)
+		}
+		results[res.Game] = res
+		n++
+	}
+	if n != games {
+		t.Fatalf("got %d results, want %d", n, games)
+	}
+	return results
+}
+
+func TestSimulate_Deterministic(t *testing.T) {
+	const games = 20
+	serial := collect(t, Simulate(context.Background(), SimulateConfig{Players: simulatePlayers(), Games: games, Seed: 42, Workers: 1}), games)
+	parallel := collect(t, Simulate(context.Background(), SimulateConfig{Players: simulatePlayers(), Games: games, Seed: 42, Workers: 8}), games)
+
+	for i := range games {
+		if serial[i].Seed != 42+int64(i) || parallel[i].Seed != serial[i].Seed {
+			t.Fatalf("game %d seeds: %d and %d", i, serial[i].Seed, parallel[i].Seed)
+		}
+		want, err := RunBattle(BattleConfig{Players: simulatePlayers(), Seed: serial[i].Seed})
+		if err != nil {
+			t.Fatal(err)
+		}
+		for p := range want.Players {
+			w := want.Players[p].Scorecard.Total()
+			if s, par := serial[i].State.Players[p].Scorecard.Total(), parallel[i].State.Players[p].Scorecard.Total(); s != w || par != w {
+				t.Errorf("game %d player %d: scores %d (serial), %d (parallel), want %d", i, p, s, par, w)
+			}
+		}
+	}
+}
+
+func TestSimulate_Cancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ch := Simulate(ctx, SimulateConfig{Players: simulatePlayers(), Games: 10000, Seed: 1, Workers: 2})
+	n := 0
+	for range ch {
+		n++
+		if n == 5 {
+			cancel()
+		}
+	}
+	if n >= 10000 {
+		t.Errorf("all %d games were played after cancel", n)
+	}
+}
+
+func TestSimulate_Errors(t *testing.T) {
+	ch := Simulate(context.Background(), SimulateConfig{Players: simulatePlayers()[:1], Games: 3, Seed: 1})
+	n := 0
+	for res := range ch {
+		n++
+		if res.Err == nil {
+			t.Errorf("game %d: expected an error with one player", res.Game)
+		}
+	}
+	if n != 3 {
+		t.Errorf("got %d results, want 3", n)
+	}
+
+	for range Simulate(context.Background(), SimulateConfig{Players: simulatePlayers()}) {
+		t.Error("got a result for zero games")
+	}
+}
+
+func TestSimulate_OnEvent(t *testing.T) {
+	gameOver := make(map[int]bool)
+	cfg := SimulateConfig{Players: simulatePlayers(), Games: 3, Seed: 7, Workers: 1, OnEvent: func(game int, e Event) {
+		if e.Type == EventGameOver {
+			gameOver[game] = true
+		}
+	}}
+	collect(t, Simulate(context.Background(), cfg), 3)
+	if len(gameOver) != 3 {
+		t.Errorf("game_over events for games %v, want 0, 1 and 2", gameOver)
+	}
+}
@@ -0,0 +1,152 @@
+package engine
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+)
+
+// SnapshotVersion is the current snapshot format version. Restore accepts
+// snapshots up to this version.
+const SnapshotVersion = 1
+
+// Snapshot is the complete, JSON-serializable state of a Game.
+type Snapshot struct {
+	Version   int              `json:"version"`
+	Rules     string           `json:"rules"`
+	Players   []PlayerSnapshot `json:"players"`
+	Current   int              `json:"current"`
+	Round     int              `json:"round"`
+	Dice      [5]int           `json:"dice"`
+	RollCount int              `json:"roll_count"`
+	Phase     GamePhase        `json:"phase"`
+	// RNG is nil when the game's source is not a SeededSource; a restored
+	// game then continues with a fresh random source.
+	RNG *SourceState `json:"rng,omitempty"`
+}
+
+type PlayerSnapshot struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	Scorecard Scorecard `json:"scorecard"`
+}
+
+// Snapshot captures the game's current state.
+func (g *Game) Snapshot() Snapshot {
+	players := make([]PlayerSnapshot, len(g.Players))
+	for i, p := range g.Players {
+		players[i] = PlayerSnapshot{ID: p.ID, Name: p.Name, Scorecard: p.Scorecard.clone()}
+	}
+	s := Snapshot{
+		Version:   SnapshotVersion,
+		Rules:     g.rules.Name(),
+		Players:   players,
+		Current:   g.Current,
+		Round:     g.Round,
+		Dice:      g.Dice,
+		RollCount: g.RollCount,
+		Phase:     g.Phase,
+	}
+	if src, ok := g.rng.(*SeededSource); ok {
+		state := src.State()
+		s.RNG = &state
+	}
+	return s
+}
+
+// Restore rebuilds a game from a snapshot.
+func Restore(s Snapshot) (*Game, error) {
+	if s.Version < 1 || s.Version > SnapshotVersion {
+		return nil, fmt.Errorf("unsupported snapshot version %d (want 1-%d)", s.Version, SnapshotVersion)
+	}
+	rules, err := RuleSetByName(s.Rules)
+	if err != nil {
+		return nil, err
+	}
+	if len(s.Players) == 0 {
+		return nil, errors.New("invalid snapshot: no players")
+	}
+	if s.Current < 0 || s.Current >= len(s.Players) {
+		return nil, fmt.Errorf("invalid snapshot: current player %d out of range", s.Current)
+	}
+	if s.Phase < PhaseRolling || s.Phase > PhaseFinished {
+		return nil, fmt.Errorf("invalid snapshot: unknown phase %d", s.Phase)
+	}
+	if s.RollCount < 0 || s.RollCount > MaxRolls {
+		return nil, fmt.Errorf("invalid snapshot: roll count %d out of range", s.RollCount)
+	}
+	if (s.Phase == PhaseRolling && s.RollCount == MaxRolls) || (s.Phase == PhaseChoosing && s.RollCount != MaxRolls) {
+		return nil, fmt.Errorf("invalid snapshot: phase %d after %d rolls", s.Phase, s.RollCount)
+	}
+	if s.RollCount > 0 {
+		for _, d := range s.Dice {
+			if d < 1 || d > 6 {
+				return nil, fmt.Errorf("invalid snapshot: die value %d", d)
+			}
+		}
+	}
+
+	players := make([]Player, len(s.Players))
+	for i, p := range s.Players {
+		if p.Scorecard.Rules().Name() != rules.Name() {
+			return nil, fmt.Errorf("invalid snapshot: player %s uses %s rules, game uses %s",
+				p.ID, p.Scorecard.Rules().Name(), rules.Name())
+		}
+		sc := p.Scorecard.clone()
+		sc.rules = rules
+		players[i] = Player{ID: p.ID, Name: p.Name, Scorecard: sc}
+	}
+
+	g := &Game{
+		Players:   players,
+		Current:   s.Current,
+		Round:     s.Round,
+		Dice:      s.Dice,
+		RollCount: s.RollCount,
+		Phase:     s.Phase,
+		rules:     rules,
+	}
+	if s.Round < 1 || s.Round > g.MaxRounds()+1 {
+		return nil, fmt.Errorf("invalid snapshot: round %d out of range", s.Round)
+	}
+	if (s.Phase == PhaseFinished) != (s.Round > g.MaxRounds()) {
+		return nil, fmt.Errorf("invalid snapshot: phase %d in round %d", s.Phase, s.Round)
+	}
+	if s.RNG != nil {
+		// Each die rolled draws about one value; allow twice that for
+		// resampling. A longer stream can't come from this game, and
+		// replaying it would take too long.
+		limit := uint64(2 * len(players) * g.MaxRounds() * MaxRolls * 5)
+		if s.RNG.Position > limit {
+			return nil, fmt.Errorf("invalid snapshot: random source position %d beyond the %d a game draws", s.RNG.Position, limit)
+		}
+		g.rng = RestoreSource(*s.RNG)
+	} else {
+		g.rng = NewSeededSource(time.Now().UnixNano())
+	}
+	return g, nil
+}
+
+// SaveGame writes a snapshot of g to path as JSON.
+func SaveGame(g *Game, path string) error {
+	data, err := json.MarshalIndent(g.Snapshot(), "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode snapshot: %w", err)
+	}
+	return os.WriteFile(path, data, 0o644)
+}
+
+// LoadGame restores a game saved with SaveGame.
+func LoadGame(path string) (*Game, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var s Snapshot
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("decode snapshot: %w", err)
+	}
+	return Restore(s)
+}
@@ -0,0 +1,101 @@
+package engine
+
+import (
+	"encoding/json"
+	"math/rand"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSnapshot_RestoreContinuesIdentically(t *testing.T) {
+	g := NewGame([]string{"Alice", "Bob"}, NewSeededSource(42))
+	require.NoError(t, g.Roll())
+	require.NoError(t, g.Score(Chance))
+	require.NoError(t, g.Roll())
+	require.NoError(t, g.Hold([]int{0, 1}))
+
+	data, err := json.Marshal(g.Snapshot())
+	require.NoError(t, err)
+	var s Snapshot
+	require.NoError(t, json.Unmarshal(data, &s))
+	restored, err := Restore(s)
+	require.NoError(t, err)
+
+	assert.Equal(t, g.GetState(), restored.GetState())
+
+	for _, game := range []*Game{g, restored} {
+		require.NoError(t, game.Hold([]int{2}))
+		require.NoError(t, game.Score(Chance))
+		require.NoError(t, game.Roll())
+	}
+	assert.Equal(t, g.GetState(), restored.GetState())
+}
+
+func TestSnapshot_PreservesRulesAndBonuses(t *testing.T) {
+	g := NewGameWithRules([]string{"Solo"}, NewSeededSource(1), &GeneralaRules{})
+	g.Players[0].Scorecard.Fill(Generala, 50)
+
+	restored, err := Restore(g.Snapshot())
+	require.NoError(t, err)
+	assert.Equal(t, "generala", restored.Rules().Name())
+	assert.Equal(t, 50, restored.Players[0].Scorecard.GetScore(Generala))
+	assert.Equal(t, 11, restored.MaxRounds())
+}
+
+func TestSnapshot_WithoutSeededSource(t *testing.T) {
+	g := NewGame([]string{"Solo"}, rand.NewSource(3))
+	s := g.Snapshot()
+	assert.Nil(t, s.RNG)
+
+	restored, err := Restore(s)
+	require.NoError(t, err)
+	assert.NoError(t, restored.Roll())
+}
+
+func TestRestore_Invalid(t *testing.T) {
+	valid := NewGame([]string{"Solo"}, NewSeededSource(1)).Snapshot()
+
+	tests := []struct {
+		name   string
+		modify func(s *Snapshot)
+	}{
+		{"future version", func(s *Snapshot) { s.Version = SnapshotVersion + 1 }},
+		{"missing version", func(s *Snapshot) { s.Version = 0 }},
+		{"unknown rules", func(s *Snapshot) { s.Rules = "farkle" }},
+		{"no players", func(s *Snapshot) { s.Players = nil }},
+		{"current out of range", func(s *Snapshot) { s.Current = 3 }},
+		{"round out of range", func(s *Snapshot) { s.Round = 20 }},
+		{"bad phase", func(s *Snapshot) { s.Phase = PhaseWaiting }},
+		{"bad dice", func(s *Snapshot) { s.RollCount = 1; s.Dice = [5]int{0, 1, 2, 3, 4} }},
+		{"rolling after last roll", func(s *Snapshot) { s.RollCount = MaxRolls; s.Dice = [5]int{1, 2, 3, 4, 5} }},
+		{"choosing before last roll", func(s *Snapshot) { s.Phase = PhaseChoosing; s.RollCount = 1; s.Dice = [5]int{1, 2, 3, 4, 5} }},
+		{"finished too early", func(s *Snapshot) { s.Phase = PhaseFinished }},
+		{"unfinished after last round", func(s *Snapshot) { s.Round = 14 }},
+		{"rules mismatch", func(s *Snapshot) { s.Rules = "yacht" }},
+		{"source position too far", func(s *Snapshot) { s.RNG = &SourceState{Seed: 1, Position: 1 << 40} }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := valid
+			s.Players = append([]PlayerSnapshot(nil), valid.Players...)
+			tt.modify(&s)
+			_, err := Restore(s)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestSaveLoadGame(t *testing.T) {
+	g := NewGame([]string{"Alice"}, NewSeededSource(5))
+	require.NoError(t, g.Roll())
+
+	path := filepath.Join(t.TempDir(), "game.json")
+	require.NoError(t, SaveGame(g, path))
+
+	loaded, err := LoadGame(path)
+	require.NoError(t, err)
+	assert.Equal(t, g.GetState(), loaded.GetState())
+}
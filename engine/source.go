@@ -0,0 +1,55 @@
+package engine
+
+import "math/rand"
+
+// SeededSource is a rand.Source whose position can be saved and restored.
+// It wraps the standard seeded source and counts the values drawn, so the
+// pair (seed, position) reproduces its exact state.
+type SeededSource struct {
+	seed     int64
+	position uint64
+	src      rand.Source64
+}
+
+// SourceState is the serializable state of a SeededSource.
+type SourceState struct {
+	Seed     int64  `json:"seed"`
+	Position uint64 `json:"position"`
+}
+
+// NewSeededSource returns a SeededSource producing the same sequence as
+// rand.NewSource(seed).
+func NewSeededSource(seed int64) *SeededSource {
+	return &SeededSource{seed: seed, src: rand.NewSource(seed).(rand.Source64)}
+}
+
+// RestoreSource returns a SeededSource positioned at state.
+func RestoreSource(state SourceState) *SeededSource {
+	s := NewSeededSource(state.Seed)
+	for s.position < state.Position {
+		s.Int63()
+	}
+	return s
+}
+
+func (s *SeededSource) Int63() int64 {
+	s.position++
+	return s.src.Int63()
+}
+
+func (s *SeededSource) Uint64() uint64 {
+	s.position++
+	return s.src.Uint64()
+}
+
+// Seed reseeds the source and resets its position.
+func (s *SeededSource) Seed(seed int64) {
+	s.seed = seed
+	s.position = 0
+	s.src.Seed(seed)
+}
+
+// State returns the seed and the number of values drawn so far.
+func (s *SeededSource) State() SourceState {
+	return SourceState{Seed: s.seed, Position: s.position}
+}
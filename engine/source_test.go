@@ -0,0 +1,27 @@
+package engine
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSeededSource_MatchesStandardSource(t *testing.T) {
+	want := rand.NewSource(7)
+	got := NewSeededSource(7)
+	for i := 0; i < 100; i++ {
+		assert.Equal(t, want.Int63(), got.Int63())
+	}
+	assert.Equal(t, SourceState{Seed: 7, Position: 100}, got.State())
+}
+
+func TestRestoreSource(t *testing.T) {
+	src := NewSeededSource(99)
+	RollAll(src)
+	Reroll([5]int{1, 2, 3, 4, 5}, []int{0}, src)
+
+	restored := RestoreSource(src.State())
+	assert.Equal(t, src.State(), restored.State())
+	assert.Equal(t, RollAll(src), RollAll(restored))
+}
@@ -12,3 +12,34 @@ type Strategy interface {
 	Name() string
 	DecideAction(dice [5]int, rollCount int, scorecard Scorecard, available []Category) TurnAction
 }
+
+// DecisionContext is the state of the whole game at a decision: the
+// deciding player's dice and scorecard, as DecideAction receives them, plus
+// every opponent's scorecard.
+type DecisionContext struct {
+	Dice      [5]int
+	RollCount int
+	Scorecard Scorecard
+	Available []Category
+	// TurnsLeft is the number of turns the deciding player has left,
+	// counting this one.
+	TurnsLeft int
+	// Opponents are the other players, in turn order from the deciding
+	// player.
+	Opponents []Opponent
+}
+
+// Opponent is another player of the game, as seen by a deciding player.
+type Opponent struct {
+	Name      string
+	Scorecard Scorecard
+	TurnsLeft int
+}
+
+// ContextStrategy is a Strategy that can take the whole game into account.
+// AIPlayer.PlayTurn calls DecideWithContext instead of DecideAction on
+// strategies that implement it.
+type ContextStrategy interface {
+	Strategy
+	DecideWithContext(ctx DecisionContext) TurnAction
+}
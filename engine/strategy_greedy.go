@@ -7,5 +7,5 @@ type GreedyStrategy struct{}
 func (s *GreedyStrategy) Name() string { return "greedy" }
 
 func (s *GreedyStrategy) DecideAction(dice [5]int, rollCount int, scorecard Scorecard, available []Category) TurnAction {
-	return TurnAction{Type: "score", Category: bestCategoryForDice(dice, available)}
+	return TurnAction{Type: "score", Category: bestCategoryForDice(dice, available, scorecard)}
 }
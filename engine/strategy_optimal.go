@@ -0,0 +1,109 @@
+package engine
+
+import "errors"
+
+// ErrNoOptimalTable is the error for an OptimalStrategy without a table.
+var ErrNoOptimalTable = errors.New("optimal strategy has no table: solve or load one first")
+
+// OptimalStrategy plays solitaire Yahtzee to maximize expected final score,
+// using a value table for every game state (see SolveOptimalTable). Each
+// decision looks up the table for the states reachable by scoring, so hold
+// choices account for both remaining rerolls and all future turns.
+//
+// It ignores opponents. Without a Table, or under rule sets other than
+// YahtzeeRules, it falls back to StatisticalStrategy; NewOptimalStrategy
+// refuses a nil table so that a missing one is caught up front.
+type OptimalStrategy struct {
+	Table *OptimalTable
+}
+
+// NewOptimalStrategy returns an OptimalStrategy backed by table, or
+// ErrNoOptimalTable if table is nil.
+func NewOptimalStrategy(table *OptimalTable) (*OptimalStrategy, error) {
+	if table == nil {
+		return nil, ErrNoOptimalTable
+	}
+	return &OptimalStrategy{Table: table}, nil
+}
+
+func (s *OptimalStrategy) Name() string { return "optimal" }
+
+func (s *OptimalStrategy) DecideAction(dice [5]int, rollCount int, scorecard Scorecard, available []Category) TurnAction {
+	if s.Table == nil || scorecard.Rules().Name() != DefaultRules().Name() {
+		return (&StatisticalStrategy{}).DecideAction(dice, rollCount, scorecard, available)
+	}
+
+	mask, upper, y50 := optimalState(scorecard)
+	ts := newTurnSolver(s.Table)
+	r := rollIndex(dice)
+
+	if rollCount < MaxRolls {
+		ts.fillFinal(mask, upper, y50)
+		keepExpectations(ts.final, ts.keepEV)
+		if MaxRolls-rollCount > 1 {
+			bestKeepValues(ts.keepEV, ts.roll)
+			keepExpectations(ts.roll, ts.keepEV)
+		}
+
+		// Prefer a reroll on ties: holding everything is only chosen when
+		// scoring now is strictly better.
+		full := multisets.keepIndexByKey[multisets.rolls[r].key()]
+		bestKeep := -1
+		for _, k := range multisets.rollSubKeeps[r] {
+			if k == full {
+				continue
+			}
+			if bestKeep < 0 || ts.keepEV[k] > ts.keepEV[bestKeep] {
+				bestKeep = k
+			}
+		}
+		if bestKeep >= 0 && ts.keepEV[bestKeep] >= ts.keepEV[full] {
+			return TurnAction{Type: "hold", Indices: holdIndicesForKeep(dice, multisets.keeps[bestKeep])}
+		}
+	}
+
+	c, _ := ts.bestPlacement(mask, upper, y50, r, categoryMask(available))
+	if c < 0 {
+		return TurnAction{Type: "score", Category: bestCategoryForDice(dice, available, scorecard)}
+	}
+	return TurnAction{Type: "score", Category: AllCategories[c]}
+}
+
+// CategoryValue returns the points scoring dice in c on sc earns, bonuses
+// included, plus the expected score of the rest of the game under optimal
+// play. It is a CategoryValue for StatisticalStrategy under the official
+// Yahtzee rules.
+func (t *OptimalTable) CategoryValue(c Category, dice [5]int, sc Scorecard) float64 {
+	mask, upper, y50 := optimalState(sc)
+	reward, nmask, nupper, ny50 := placement(mask, upper, y50, rollIndex(dice), categoryIndex[c])
+	return float64(reward) + t.value(nmask, nupper, ny50)
+}
+
+// optimalState converts a Yahtzee scorecard into solver state coordinates.
+func optimalState(sc Scorecard) (mask, upper, y50 int) {
+	for i, c := range AllCategories {
+		if sc.IsFilled(c) {
+			mask |= 1 << i
+		}
+	}
+	upper = min(sc.UpperTotal(), UpperBonusThreshold)
+	if sc.GetScore(Yahtzee) > 0 {
+		y50 = 1
+	}
+	return mask, upper, y50
+}
+
+// categoryMask converts categories into a solver bitmask. An empty list
+// allows every category.
+func categoryMask(cats []Category) int {
+	if len(cats) == 0 {
+		return allFilledMask
+	}
+	m := 0
+	for _, c := range cats {
+		if i, ok := categoryIndex[c]; ok {
+			m |= 1 << i
+		}
+	}
+	return m
+}
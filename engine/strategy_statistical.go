@@ -1,32 +1,55 @@
 package engine
 
+// CategoryValue values scoring dice in category c on a scorecard. It lets
+// StatisticalStrategy weigh what a choice does to later turns instead of
+// taking the points alone; see OptimalTable.CategoryValue.
+type CategoryValue func(c Category, dice [5]int, sc Scorecard) float64
+
 // StatisticalStrategy uses expected value calculation to decide whether to hold or score.
 // On the 3rd roll it always scores the best category.
-// Otherwise it compares immediate scoring vs expected value of each hold combination.
-type StatisticalStrategy struct{}
+// Otherwise it compares immediate scoring vs expected value of each hold combination,
+// planning the rerolls that would follow the hold.
+type StatisticalStrategy struct {
+	// Depth is the number of rerolls a hold is evaluated over. Zero looks
+	// ahead over every reroll left in the turn; 1 treats the next roll as
+	// the last.
+	Depth int
+	// Value values scoring a category. If nil, categories are worth their
+	// points, and holds get a small boost when the upper bonus is close.
+	Value CategoryValue
+}
 
 func (s *StatisticalStrategy) Name() string { return "statistical" }
 
 func (s *StatisticalStrategy) DecideAction(dice [5]int, rollCount int, scorecard Scorecard, available []Category) TurnAction {
+	// Immediate best score
+	immediateBest, immediateScore := s.bestCategory(dice, available, scorecard)
+
 	// 3rd roll: must score
 	if rollCount >= MaxRolls {
-		return TurnAction{Type: "score", Category: bestCategoryForDice(dice, available)}
+		return TurnAction{Type: "score", Category: immediateBest}
 	}
 
-	// Immediate best score
-	immediateBest := bestCategoryForDice(dice, available)
-	immediateScore := float64(CalcScore(immediateBest, dice))
+	rerolls := MaxRolls - rollCount
+	if s.Depth > 0 {
+		rerolls = min(rerolls, s.Depth)
+	}
+	var t evTable
+	t.reset(available, scorecard, s.Value, rerolls)
+	bonus := 0.0
+	if s.Value == nil {
+		bonus = upperBonusIncentive(scorecard)
+	}
 
 	// Find the best hold combination by expected value
 	bestEV := immediateScore
 	var bestHold []int
-
 	for _, hold := range holdCombinations() {
 		if len(hold) == 5 {
 			// Holding all dice is the same as immediate scoring
 			continue
 		}
-		ev := expectedValueWithBonus(dice, hold, available, scorecard)
+		ev := t.keepValue(keepIndex(dice, hold)) + bonus
 		if ev > bestEV {
 			bestEV = ev
 			bestHold = hold
@@ -40,16 +63,32 @@ func (s *StatisticalStrategy) DecideAction(dice [5]int, rollCount int, scorecard
 	return TurnAction{Type: "score", Category: immediateBest}
 }
 
-// bestCategoryForDice returns the highest-scoring available category.
-// Panics if available is empty; callers must ensure at least one category.
-func bestCategoryForDice(dice [5]int, available []Category) Category {
+// bestCategory returns the available category worth the most for dice, and
+// its worth.
+func (s *StatisticalStrategy) bestCategory(dice [5]int, available []Category, sc Scorecard) (Category, float64) {
+	if s.Value == nil || len(available) == 0 {
+		c := bestCategoryForDice(dice, available, sc)
+		return c, float64(ScoreFor(c, dice, sc))
+	}
+	bestCat, best := available[0], s.Value(available[0], dice, sc)
+	for _, c := range available[1:] {
+		if v := s.Value(c, dice, sc); v > best {
+			bestCat, best = c, v
+		}
+	}
+	return bestCat, best
+}
+
+// bestCategoryForDice returns the highest-scoring available category under
+// the scorecard's rule set.
+func bestCategoryForDice(dice [5]int, available []Category, sc Scorecard) Category {
 	if len(available) == 0 {
 		return Chance
 	}
 	bestCat := available[0]
-	bestScore := CalcScore(bestCat, dice)
+	bestScore := ScoreFor(bestCat, dice, sc)
 	for _, c := range available[1:] {
-		s := CalcScore(c, dice)
+		s := ScoreFor(c, dice, sc)
 		if s > bestScore {
 			bestScore = s
 			bestCat = c
@@ -73,7 +73,7 @@ func TestExpectedValue_AllHeld(t *testing.T) {
 	dice := [5]int{1, 2, 3, 4, 5}
 
 	ev := expectedValue(dice, []int{0, 1, 2, 3, 4}, avail, sc)
-	best := float64(bestScoreForDice(dice, avail))
+	best := float64(bestScoreForDice(dice, avail, sc))
 	assert.Equal(t, best, ev, "holding all dice should give same as best immediate score")
 }
 
@@ -93,7 +93,7 @@ func TestHoldCombinations_Count(t *testing.T) {
 
 func TestBestScoreForDice(t *testing.T) {
 	avail := []Category{Ones, Yahtzee, Chance}
-	best := bestScoreForDice([5]int{6, 6, 6, 6, 6}, avail)
+	best := bestScoreForDice([5]int{6, 6, 6, 6, 6}, avail, NewScorecard())
 	assert.Equal(t, 50, best, "Yahtzee should be the best for five 6s")
 }
 
@@ -108,3 +108,76 @@ func TestStatisticalStrategy_FullGame(t *testing.T) {
 	action := s.DecideAction(dice, 1, sc, avail)
 	require.Contains(t, []string{"hold", "score"}, action.Type)
 }
+
+func TestWinProbability(t *testing.T) {
+	ahead := []projection{{mean: 200}}
+	assert.Equal(t, 1.0, winProbability(projection{mean: 201}, ahead))
+	assert.Equal(t, 0.5, winProbability(projection{mean: 200}, ahead))
+	assert.Equal(t, 0.0, winProbability(projection{mean: 199}, ahead))
+
+	even := projection{mean: 200, variance: 900}
+	assert.InDelta(t, 0.5, winProbability(even, []projection{even}), 0.01)
+	assert.InDelta(t, 1.0/3, winProbability(even, []projection{even, even}), 0.01)
+}
+
+func TestWinProbabilityStrategy_PlaysSafeToWin(t *testing.T) {
+	// Last turn, Chance open: 27 wins outright, and rerolling the 3 only
+	// gains 0.5 points on average but loses a third of the time.
+	me := scorecardWithOpen(Chance)
+	opp := scorecardWithOpen()
+	opp.Fill(Chance, 26)
+	ctx := DecisionContext{
+		Dice:      [5]int{6, 6, 6, 6, 3},
+		RollCount: 2,
+		Scorecard: me,
+		Available: me.AvailableCategories(),
+		TurnsLeft: 1,
+		Opponents: []Opponent{{Name: "opp", Scorecard: opp}},
+	}
+	s := &WinProbabilityStrategy{}
+	assert.Equal(t, "win", s.Name())
+	assert.Equal(t, TurnAction{Type: "score", Category: Chance}, s.DecideWithContext(ctx))
+
+	action := (&StatisticalStrategy{}).DecideAction(ctx.Dice, ctx.RollCount, me, ctx.Available)
+	assert.Equal(t, TurnAction{Type: "hold", Indices: []int{0, 1, 2, 3}}, action)
+
+	// Two points further behind, it has to take the chance.
+	opp.Fill(Chance, 28)
+	assert.Equal(t, TurnAction{Type: "hold", Indices: []int{0, 1, 2, 3}}, s.DecideWithContext(ctx))
+}
+
+func TestWinProbabilityStrategy_Solitaire(t *testing.T) {
+	sc := NewScorecard()
+	dice := [5]int{1, 1, 2, 4, 6}
+	ctx := DecisionContext{Dice: dice, RollCount: 1, Scorecard: sc, Available: sc.AvailableCategories(), TurnsLeft: 13}
+	assert.Equal(t, (&StatisticalStrategy{}).DecideAction(dice, 1, sc, ctx.Available), (&WinProbabilityStrategy{}).DecideWithContext(ctx))
+}
+
+// BenchmarkWinProbabilityStrategy_Battle plays WinProbabilityStrategy
+// against StatisticalStrategy with paired dice, each seed from both seats,
+// and reports its share of the wins.
+func BenchmarkWinProbabilityStrategy_Battle(b *testing.B) {
+	win := BattlePlayer{Name: "win", Strategy: &WinProbabilityStrategy{}}
+	stat := BattlePlayer{Name: "statistical", Strategy: &StatisticalStrategy{}}
+	parsFor(DefaultRules())
+	points := 0.0
+	for i := 0; b.Loop(); i++ {
+		players := []BattlePlayer{win, stat}
+		if i%2 == 1 {
+			players[0], players[1] = stat, win
+		}
+		state, err := RunBattle(BattleConfig{Players: players, Dice: NewPairedDice(int64(i / 2))})
+		require.NoError(b, err)
+		scores := map[string]int{}
+		for j, p := range state.Players {
+			scores[players[j].Name] = p.Scorecard.Total()
+		}
+		switch {
+		case scores["win"] > scores["statistical"]:
+			points++
+		case scores["win"] == scores["statistical"]:
+			points += 0.5
+		}
+	}
+	b.ReportMetric(points/float64(b.N), "wins/game")
+}
@@ -0,0 +1,220 @@
+package engine
+
+import (
+	"math"
+	"sync"
+)
+
+// WinProbabilityStrategy plays to win multiplayer games rather than to
+// maximize its own score. It projects every player's final score as a
+// normal distribution over the categories they have left, and values
+// scoring a category by the resulting chance of finishing first; holds are
+// planned over the rest of the turn as StatisticalStrategy plans them. So
+// it chases long shots when behind and takes the safe points when ahead.
+//
+// Without opponents, or called through DecideAction alone, it plays like
+// StatisticalStrategy.
+type WinProbabilityStrategy struct{}
+
+func (s *WinProbabilityStrategy) Name() string { return "win" }
+
+func (s *WinProbabilityStrategy) DecideAction(dice [5]int, rollCount int, scorecard Scorecard, available []Category) TurnAction {
+	return (&StatisticalStrategy{}).DecideAction(dice, rollCount, scorecard, available)
+}
+
+func (s *WinProbabilityStrategy) DecideWithContext(ctx DecisionContext) TurnAction {
+	if len(ctx.Opponents) == 0 {
+		return s.DecideAction(ctx.Dice, ctx.RollCount, ctx.Scorecard, ctx.Available)
+	}
+	pars := parsFor(ctx.Scorecard.Rules())
+	opponents := make([]projection, len(ctx.Opponents))
+	for i, o := range ctx.Opponents {
+		opponents[i] = pars.project(pars.open(o.Scorecard), o.Scorecard.Total(), o.Scorecard.UpperTotal())
+	}
+	sc := ctx.Scorecard
+	total, upper, open := sc.Total(), sc.UpperTotal(), pars.open(sc)
+	value := func(c Category, dice [5]int, sc Scorecard) float64 {
+		t, u := pars.afterScoring(c, dice, sc, total, upper)
+		me := pars.project(open.add(pars, c, -1), t, u)
+		// Once the game is decided either way, prefer more points.
+		return winProbability(me, opponents) + winTieBreak*me.mean
+	}
+	return (&StatisticalStrategy{Value: value}).DecideAction(ctx.Dice, ctx.RollCount, sc, ctx.Available)
+}
+
+// winTieBreak is the value of a point of expected score next to a chance
+// of winning of 1.
+const winTieBreak = 1e-7
+
+// projection is a normal approximation of a player's final score.
+type projection struct {
+	mean, variance float64
+}
+
+// parTable holds, for each category of a rule set, the mean and variance
+// of its final score in StatisticalStrategy's solitaire games.
+type parTable struct {
+	rules    RuleSet
+	mean     map[Category]float64
+	variance map[Category]float64
+	upper    map[Category]bool
+}
+
+// parGames is the number of solitaire games a parTable is measured over.
+const parGames = 200
+
+var parTables sync.Map // rule set name -> *parTable
+
+func parsFor(rules RuleSet) *parTable {
+	if p, ok := parTables.Load(rules.Name()); ok {
+		return p.(*parTable)
+	}
+	p, _ := parTables.LoadOrStore(rules.Name(), newParTable(rules))
+	return p.(*parTable)
+}
+
+func newParTable(rules RuleSet) *parTable {
+	p := &parTable{
+		rules:    rules,
+		mean:     make(map[Category]float64),
+		variance: make(map[Category]float64),
+		upper:    make(map[Category]bool),
+	}
+	for _, c := range rules.UpperCategories() {
+		p.upper[c] = true
+	}
+
+	second := make(map[Category]float64)
+	for seed := range int64(parGames) {
+		game := NewGameWithRules([]string{"par"}, NewSeededSource(seed+1), rules)
+		ai := NewAIPlayerWithStrategy(game, game.Players[0].ID, &StatisticalStrategy{})
+		for game.Phase != PhaseFinished {
+			if _, err := ai.PlayTurn(); err != nil {
+				panic("engine: par game: " + err.Error())
+			}
+		}
+		sc := game.Players[0].Scorecard
+		for _, c := range rules.Categories() {
+			score := float64(sc.GetScore(c))
+			p.mean[c] += score / parGames
+			second[c] += score * score / parGames
+		}
+	}
+	for c, mean := range p.mean {
+		p.variance[c] = second[c] - mean*mean
+	}
+	return p
+}
+
+// afterScoring returns the total and upper total of a scorecard at total
+// and upper after scoring dice in c, bonuses included.
+func (p *parTable) afterScoring(c Category, dice [5]int, sc Scorecard, total, upper int) (int, int) {
+	score := ScoreFor(c, dice, sc)
+	total += score
+	if EarnsYahtzeeBonus(dice, sc) {
+		total += p.rules.YahtzeeBonus()
+	}
+	if p.upper[c] {
+		threshold, value := p.rules.UpperBonus()
+		if upper < threshold && upper+score >= threshold {
+			total += value
+		}
+		upper += score
+	}
+	return total, upper
+}
+
+// openPars sums the pars of the categories open on a scorecard, in total
+// and for the upper section.
+type openPars struct {
+	mean, variance           float64
+	upperMean, upperVariance float64
+}
+
+func (p *parTable) open(sc Scorecard) openPars {
+	var o openPars
+	for _, c := range p.rules.Categories() {
+		if !sc.IsFilled(c) {
+			o = o.add(p, c, 1)
+		}
+	}
+	return o
+}
+
+// add returns o with the pars of c added sign times.
+func (o openPars) add(p *parTable, c Category, sign float64) openPars {
+	o.mean += sign * p.mean[c]
+	o.variance += sign * p.variance[c]
+	if p.upper[c] {
+		o.upperMean += sign * p.mean[c]
+		o.upperVariance += sign * p.variance[c]
+	}
+	return o
+}
+
+// project returns the final score of a player at total and upper total
+// with the categories of o left to fill.
+func (p *parTable) project(o openPars, total, upper int) projection {
+	proj := projection{mean: float64(total) + o.mean, variance: o.variance}
+	threshold, value := p.rules.UpperBonus()
+	if value > 0 && upper < threshold {
+		chance := 0.0
+		if o.upperVariance > 0 {
+			upperMean := float64(upper) + o.upperMean
+			chance = 1 - normalCDF((float64(threshold)-0.5-upperMean)/math.Sqrt(o.upperVariance))
+		}
+		proj.mean += float64(value) * chance
+		proj.variance += float64(value*value) * chance * (1 - chance)
+	}
+	return proj
+}
+
+// winNodes and winWeights integrate over a standard normal distribution.
+var winNodes, winWeights = func() ([]float64, []float64) {
+	const n, width = 41, 4.0
+	nodes, weights := make([]float64, n), make([]float64, n)
+	sum := 0.0
+	for i := range nodes {
+		nodes[i] = -width + 2*width*float64(i)/(n-1)
+		weights[i] = math.Exp(-nodes[i] * nodes[i] / 2)
+		sum += weights[i]
+	}
+	for i := range weights {
+		weights[i] /= sum
+	}
+	return nodes, weights
+}()
+
+// winProbability returns the chance that a final score distributed as me
+// beats every opponent's, counting a tie as half a win.
+func winProbability(me projection, opponents []projection) float64 {
+	if me.variance == 0 {
+		return beatAll(me.mean, opponents)
+	}
+	sd := math.Sqrt(me.variance)
+	p := 0.0
+	for i, z := range winNodes {
+		p += winWeights[i] * beatAll(me.mean+z*sd, opponents)
+	}
+	return p
+}
+
+// beatAll returns the chance that a final score of x beats every opponent's.
+func beatAll(x float64, opponents []projection) float64 {
+	p := 1.0
+	for _, o := range opponents {
+		switch {
+		case o.variance > 0:
+			p *= normalCDF((x - o.mean) / math.Sqrt(o.variance))
+		case x < o.mean:
+			return 0
+		case x == o.mean:
+			p *= 0.5
+		}
+	}
+	return p
+}
+
+func normalCDF(z float64) float64 {
+	return math.Erfc(-z/math.Sqrt2) / 2
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"math"
 	"os"
 	"strconv"
 	"time"
@@ -19,10 +20,27 @@ import (
 const defaultTableName = "YatzcliWaitingPlayers"
 const ttlDuration = 5 * time.Minute
 
+// A rated player is paired with the closest-rated waiting player whose
+// rating is within ratingWindow of theirs. The window widens by
+// ratingWindowGrowth for each minute that player has waited, so nobody
+// waits for a close match until their entry expires.
+//
+// Ratings are advisory: they are whatever each client reports about
+// itself. Accounts and their ratings live on the yatz servers that keep
+// them, which this handler can't see, so nothing stops a player from
+// claiming any rating. Pairing on it is a courtesy, not a guarantee.
+const (
+	ratingWindow       = 200
+	ratingWindowGrowth = 100
+)
+
 // ClientMessage is what the client sends after connecting.
 type ClientMessage struct {
 	Name string `json:"name"`
 	Port int    `json:"port"`
+	// Rating is the rating the player claims, if any; unrated players are
+	// paired with anyone. It isn't checked against any account.
+	Rating float64 `json:"rating,omitempty"`
 }
 
 // MatchResult is sent to both matched players.
@@ -49,6 +67,7 @@ type Handler struct {
 	db    DynamoDBClient
 	apiGW APIGatewayClient
 	table string
+	now   func() time.Time
 }
 
 // NewHandler creates a new Handler with the given clients and table name.
@@ -57,6 +76,7 @@ func NewHandler(db DynamoDBClient, apiGW APIGatewayClient, table string) *Handle
 		db:    db,
 		apiGW: apiGW,
 		table: table,
+		now:   time.Now,
 	}
 }
 
@@ -143,8 +163,7 @@ func (h *Handler) handleMessage(ctx context.Context, event events.APIGatewayWebs
 		return fmt.Errorf("scanning waiting players: %w", err)
 	}
 
-	if len(scanOut.Items) > 0 {
-		opponent := scanOut.Items[0]
+	if opponent := h.pickOpponent(scanOut.Items, msg.Rating); opponent != nil {
 		opponentID := opponent["PlayerID"].(*types.AttributeValueMemberS).Value
 		opponentName := opponent["Name"].(*types.AttributeValueMemberS).Value
 		opponentEndpoint := opponent["Endpoint"].(*types.AttributeValueMemberS).Value
@@ -184,17 +203,21 @@ func (h *Handler) handleMessage(ctx context.Context, event events.APIGatewayWebs
 	}
 
 	// No waiting player found; register self
-	now := time.Now()
+	now := h.now()
 	ttl := now.Add(ttlDuration).Unix()
+	item := map[string]types.AttributeValue{
+		"PlayerID":  &types.AttributeValueMemberS{Value: connectionID},
+		"Name":      &types.AttributeValueMemberS{Value: msg.Name},
+		"Endpoint":  &types.AttributeValueMemberS{Value: endpoint},
+		"CreatedAt": &types.AttributeValueMemberS{Value: now.Format(time.RFC3339)},
+		"TTL":       &types.AttributeValueMemberN{Value: strconv.FormatInt(ttl, 10)},
+	}
+	if msg.Rating > 0 {
+		item["Rating"] = &types.AttributeValueMemberN{Value: strconv.FormatFloat(msg.Rating, 'f', -1, 64)}
+	}
 	_, err = h.db.PutItem(ctx, &dynamodb.PutItemInput{
 		TableName: aws.String(h.table),
-		Item: map[string]types.AttributeValue{
-			"PlayerID":  &types.AttributeValueMemberS{Value: connectionID},
-			"Name":      &types.AttributeValueMemberS{Value: msg.Name},
-			"Endpoint":  &types.AttributeValueMemberS{Value: endpoint},
-			"CreatedAt": &types.AttributeValueMemberS{Value: now.Format(time.RFC3339)},
-			"TTL":       &types.AttributeValueMemberN{Value: strconv.FormatInt(ttl, 10)},
-		},
+		Item:      item,
 	})
 	if err != nil {
 		return fmt.Errorf("registering player: %w", err)
@@ -203,6 +226,53 @@ func (h *Handler) handleMessage(ctx context.Context, event events.APIGatewayWebs
 	return nil
 }
 
+// pickOpponent returns the waiting player to pair a player rated rating
+// with, or nil if none is close enough. If either player is unrated any
+// pairing will do, but the closest-rated player within the window is
+// preferred; ties go to the player found first.
+func (h *Handler) pickOpponent(items []map[string]types.AttributeValue, rating float64) map[string]types.AttributeValue {
+	var best map[string]types.AttributeValue
+	bestGap := math.Inf(1)
+	for _, item := range items {
+		theirs := itemRating(item)
+		gap := math.Inf(1)
+		if rating > 0 && theirs > 0 {
+			gap = math.Abs(rating - theirs)
+			if gap > h.window(item) {
+				continue
+			}
+		}
+		if best == nil || gap < bestGap {
+			best, bestGap = item, gap
+		}
+	}
+	return best
+}
+
+// window is how far from a waiting player's rating an opponent may be.
+func (h *Handler) window(item map[string]types.AttributeValue) float64 {
+	w := float64(ratingWindow)
+	if v, ok := item["CreatedAt"].(*types.AttributeValueMemberS); ok {
+		if created, err := time.Parse(time.RFC3339, v.Value); err == nil {
+			w += ratingWindowGrowth * max(h.now().Sub(created).Minutes(), 0)
+		}
+	}
+	return w
+}
+
+// itemRating is a waiting player's rating, or 0 if they are unrated.
+func itemRating(item map[string]types.AttributeValue) float64 {
+	v, ok := item["Rating"].(*types.AttributeValueMemberN)
+	if !ok {
+		return 0
+	}
+	r, err := strconv.ParseFloat(v.Value, 64)
+	if err != nil {
+		return 0
+	}
+	return r
+}
+
 func (h *Handler) notifyPlayer(ctx context.Context, connectionID string, result MatchResult) error {
 	data, err := json.Marshal(result)
 	if err != nil {
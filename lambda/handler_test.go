@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"sync"
 	"testing"
+	"time"
 
 	"github.com/aws/aws-lambda-go/events"
 	"github.com/aws/aws-sdk-go-v2/service/apigatewaymanagementapi"
@@ -195,3 +196,47 @@ func TestHandler_Disconnect(t *testing.T) {
 	assert.Equal(t, 200, resp.StatusCode)
 	assert.Empty(t, db.items)
 }
+
+func TestHandler_Message_PairsSimilarRatings(t *testing.T) {
+	db := &mockDynamoDB{}
+	apiGW := newMockAPIGateway()
+	h := NewHandler(db, apiGW, "test-table")
+	start := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	h.now = func() time.Time { return start }
+
+	register := func(conn, body string) {
+		t.Helper()
+		_, err := h.HandleRequest(context.Background(), makeEvent("$default", conn, "1.2.3.4", body))
+		require.NoError(t, err)
+	}
+	register("conn-strong", `{"name":"Strong","port":1,"rating":1900}`)
+	register("conn-mid", `{"name":"Mid","port":2,"rating":1550}`)
+	require.Len(t, db.items, 2, "players 350 apart should not be paired")
+	assert.Equal(t, "1550", db.items[1]["Rating"].(*types.AttributeValueMemberN).Value)
+
+	// A 1600 player is paired with Mid, the closer of the two.
+	register("conn-new", `{"name":"New","port":3,"rating":1600}`)
+	var result MatchResult
+	require.NoError(t, json.Unmarshal(apiGW.sentMessages["conn-new"], &result))
+	assert.Equal(t, "Mid", result.OpponentName)
+	require.Len(t, db.items, 1)
+
+	// A 1400 player is too far from Strong, until Strong has waited long
+	// enough for the window to reach them.
+	register("conn-weak", `{"name":"Weak","port":4,"rating":1400}`)
+	require.Len(t, db.items, 2)
+	h.now = func() time.Time { return start.Add(3 * time.Minute) }
+	register("conn-weak2", `{"name":"Weak2","port":5,"rating":1400}`)
+	require.NoError(t, json.Unmarshal(apiGW.sentMessages["conn-weak2"], &result))
+	assert.Equal(t, "Weak", result.OpponentName, "the equally rated player comes first")
+	register("conn-weak3", `{"name":"Weak3","port":6,"rating":1400}`)
+	require.NoError(t, json.Unmarshal(apiGW.sentMessages["conn-weak3"], &result))
+	assert.Equal(t, "Strong", result.OpponentName, "a 500 gap is allowed after 3 minutes")
+
+	// Unrated players are paired with anyone.
+	register("conn-rated", `{"name":"Rated","port":7,"rating":2400}`)
+	register("conn-unrated", `{"name":"Unrated","port":8}`)
+	require.NoError(t, json.Unmarshal(apiGW.sentMessages["conn-unrated"], &result))
+	assert.Equal(t, "Rated", result.OpponentName)
+	assert.Empty(t, db.items)
+}
@@ -16,20 +16,23 @@ type MatchResult struct {
 
 // ClientMessage is sent to the matchmaking server
 type ClientMessage struct {
-	Name string `json:"name"`
-	Port int    `json:"port"`
+	Name   string  `json:"name"`
+	Port   int     `json:"port"`
+	Rating float64 `json:"rating,omitempty"`
 }
 
 // FindMatch connects to the matchmaking WebSocket API and waits for a match.
-// Returns the match result with opponent info and host/guest role.
-func FindMatch(wsURL string, name string, port int) (*MatchResult, error) {
+// Returns the match result with opponent info and host/guest role. A
+// positive rating asks for an opponent of similar rating; the server takes
+// it on trust.
+func FindMatch(wsURL string, name string, port int, rating float64) (*MatchResult, error) {
 	conn, _, err := websocket.DefaultDialer.Dial(wsURL, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to matchmaking: %w", err)
 	}
 	defer conn.Close()
 
-	msg := ClientMessage{Name: name, Port: port}
+	msg := ClientMessage{Name: name, Port: port, Rating: rating}
 	if err := conn.WriteJSON(msg); err != nil {
 		return nil, fmt.Errorf("failed to send registration: %w", err)
 	}
@@ -40,7 +40,7 @@ func TestFindMatch_ReceivesMatchResult(t *testing.T) {
 	defer server.Close()
 
 	wsURL := "ws" + strings.TrimPrefix(server.URL, "http")
-	result, err := FindMatch(wsURL, "Alice", 9876)
+	result, err := FindMatch(wsURL, "Alice", 9876, 0)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -56,7 +56,7 @@ func TestFindMatch_ReceivesMatchResult(t *testing.T) {
 }
 
 func TestFindMatch_ConnectionError(t *testing.T) {
-	_, err := FindMatch("ws://localhost:1", "Alice", 9876)
+	_, err := FindMatch("ws://localhost:1", "Alice", 9876, 0)
 	if err == nil {
 		t.Error("expected error for invalid address")
 	}
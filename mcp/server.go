@@ -8,6 +8,7 @@ import (
 
 	"github.com/edge2992/yatzcli/engine"
 	"github.com/edge2992/yatzcli/p2p"
+	"github.com/edge2992/yatzcli/stats"
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
 )
@@ -17,15 +18,21 @@ type gameServer struct {
 	client     engine.GameClient
 	ais        []*engine.AIPlayer
 	onlineName string
+
+	// record, if set, is handed each finished game once.
+	record   func(stats.Game) error
+	recorded bool
 }
 
 func Serve() error {
-	s := newServer()
+	s := newServer(stats.Record)
 	return server.ServeStdio(s)
 }
 
-func newServer() *server.MCPServer {
-	gs := &gameServer{}
+// newServer returns the MCP server, recording finished games with record
+// (nil records nothing).
+func newServer(record func(stats.Game) error) *server.MCPServer {
+	gs := &gameServer{record: record}
 
 	s := server.NewMCPServer(
 		"yatzcli",
@@ -36,6 +43,7 @@ func newServer() *server.MCPServer {
 	newGameTool := mcp.NewTool("new_game",
 		mcp.WithDescription("Start a new Yahtzee game with AI opponents"),
 		mcp.WithNumber("opponents", mcp.Description("Number of AI opponents (1-3, default 1)")),
+		mcp.WithString("rules", mcp.Description("Rule set: "+strings.Join(engine.RuleSetNames(), ", ")+" (default yahtzee)")),
 	)
 	s.AddTool(newGameTool, gs.handleNewGame)
 
@@ -73,7 +81,7 @@ func newServer() *server.MCPServer {
 
 	joinGameTool := mcp.NewTool("join_game",
 		mcp.WithDescription("Join a game server for online play"),
-		mcp.WithString("addr", mcp.Required(), mcp.Description("Server address (e.g. localhost:9876)")),
+		mcp.WithString("addr", mcp.Required(), mcp.Description("Server address: host:port (e.g. localhost:9876) or a ws:// or wss:// URL")),
 		mcp.WithString("name", mcp.Description("Your player name (default: Claude)")),
 	)
 	s.AddTool(joinGameTool, gs.handleJoinGame)
@@ -93,6 +101,11 @@ func newServer() *server.MCPServer {
 }
 
 func (gs *gameServer) handleNewGame(_ context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	rules, err := engine.RuleSetByName(req.GetString("rules", ""))
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
 	// Cleanup existing online connection
 	if rc, ok := gs.client.(*p2p.RemoteClient); ok {
 		rc.Close()
@@ -112,17 +125,18 @@ func (gs *gameServer) handleNewGame(_ context.Context, req mcp.CallToolRequest)
 		names = append(names, fmt.Sprintf("AI-%d", i+1))
 	}
 
-	gs.game = engine.NewGame(names, nil)
+	gs.game = engine.NewGameWithRules(names, nil, rules)
 	gs.ais = make([]*engine.AIPlayer, opponents)
 	for i := 0; i < opponents; i++ {
 		gs.ais[i] = engine.NewAIPlayer(gs.game, fmt.Sprintf("player-%d", i+1))
 	}
 	gs.client = engine.NewLocalClient(gs.game, "player-0", gs.ais)
+	gs.recorded = false
 
 	state, _ := gs.client.GetState()
 	return mcp.NewToolResultText(fmt.Sprintf(
-		"New game started with %d AI opponent(s)!\n\n%s",
-		opponents, formatState(state),
+		"New game started with %d AI opponent(s) (%s rules)!\n\n%s",
+		opponents, rules.Name(), formatState(state),
 	)), nil
 }
 
@@ -171,7 +185,8 @@ func (gs *gameServer) handleScore(_ context.Context, req mcp.CallToolRequest) (*
 
 	// Get dice BEFORE Score() because Score() advances turn and clears dice
 	currentState, _ := gs.client.GetState()
-	score := engine.CalcScore(cat, currentState.Dice)
+	sc := currentState.Players[currentState.CurrentPlayerIndex].Scorecard
+	score := engine.ScoreFor(cat, currentState.Dice, sc)
 	log.Printf("[bot] score %s → %d pts (waiting for opponent...)", category, score)
 
 	state, scoreErr := gs.client.Score(cat)
@@ -184,6 +199,7 @@ func (gs *gameServer) handleScore(_ context.Context, req mcp.CallToolRequest) (*
 	var sb strings.Builder
 	fmt.Fprintf(&sb, "Scored %d points in %s.\n\n", score, category)
 	if state.Phase == engine.PhaseFinished {
+		gs.recordGame(state)
 		sb.WriteString("Game Over!\n\n")
 		sb.WriteString(formatFinalScores(state))
 	} else {
@@ -247,11 +263,12 @@ func (gs *gameServer) handleJoinGame(_ context.Context, req mcp.CallToolRequest)
 
 	gs.client = rc
 	gs.onlineName = name
+	gs.recorded = false
 
 	state, _ := gs.client.GetState()
 	log.Printf("[bot] Joined game at %s as %s (current: %s)", addr, name, state.CurrentPlayer)
 	return mcp.NewToolResultText(fmt.Sprintf(
-		"Joined game as %s!\n\n%s", name, formatState(state),
+		"Joined game as %s!\nConnected with %s\n\n%s", name, rc.Features(), formatState(state),
 	)), nil
 }
 
@@ -260,6 +277,9 @@ func (gs *gameServer) handleSendChat(_ context.Context, req mcp.CallToolRequest)
 	if !ok {
 		return mcp.NewToolResultError("Not connected to a game server. Use join_game first."), nil
 	}
+	if !rc.Features().Has(p2p.CapChat) {
+		return mcp.NewToolResultError("This server does not relay chat."), nil
+	}
 	text, err := req.RequireString("text")
 	if err != nil {
 		return mcp.NewToolResultError(err.Error()), nil
@@ -286,6 +306,7 @@ func (gs *gameServer) handleWaitForTurn(_ context.Context, _ mcp.CallToolRequest
 
 	if isGameOver {
 		log.Printf("[bot] game over")
+		gs.recordGame(state)
 		var sb strings.Builder
 		sb.WriteString("Game Over!\n\n")
 		sb.WriteString(formatFinalScores(state))
@@ -296,6 +317,25 @@ func (gs *gameServer) handleWaitForTurn(_ context.Context, _ mcp.CallToolRequest
 	return mcp.NewToolResultText(fmt.Sprintf("Your turn!\n\n%s", formatState(state))), nil
 }
 
+// recordGame records the finished game in state, once.
+func (gs *gameServer) recordGame(state *engine.GameState) {
+	if gs.record == nil || gs.recorded {
+		return
+	}
+	gs.recorded = true
+	you := "player-0"
+	strategies := make(map[string]string)
+	if rc, ok := gs.client.(*p2p.RemoteClient); ok {
+		you = rc.PlayerID()
+	}
+	for i, ai := range gs.ais {
+		strategies[fmt.Sprintf("player-%d", i+1)] = ai.Strategy().Name()
+	}
+	if err := gs.record(stats.NewGame(stats.ModeMCP, *state, you, strategies)); err != nil {
+		log.Printf("[bot] record game: %v", err)
+	}
+}
+
 func formatDice(dice [5]int) string {
 	parts := make([]string, 5)
 	for i, d := range dice {
@@ -306,7 +346,11 @@ func formatDice(dice [5]int) string {
 
 func formatState(state *engine.GameState) string {
 	var sb strings.Builder
-	fmt.Fprintf(&sb, "Round: %d/13\n", state.Round)
+	maxRounds := state.MaxRounds
+	if maxRounds == 0 {
+		maxRounds = engine.MaxRounds
+	}
+	fmt.Fprintf(&sb, "Round: %d/%d\n", state.Round, maxRounds)
 	fmt.Fprintf(&sb, "Current Player: %s\n", state.CurrentPlayer)
 	fmt.Fprintf(&sb, "Phase: %s\n", phaseName(state.Phase))
 	fmt.Fprintf(&sb, "Roll Count: %d/3\n", state.RollCount)
@@ -343,7 +387,8 @@ func formatPlayerScorecard(p engine.PlayerState) string {
 	fmt.Fprintf(&sb, "=== %s (%s) ===\n", p.Name, p.ID)
 	fmt.Fprintf(&sb, "%-18s %s\n", "Category", "Score")
 	fmt.Fprintf(&sb, "%-18s %s\n", strings.Repeat("-", 18), strings.Repeat("-", 5))
-	for _, c := range engine.AllCategories {
+	rules := p.Scorecard.Rules()
+	for _, c := range rules.Categories() {
 		if p.Scorecard.IsFilled(c) {
 			fmt.Fprintf(&sb, "%-18s %5d\n", c, p.Scorecard.GetScore(c))
 		} else {
@@ -353,7 +398,10 @@ func formatPlayerScorecard(p engine.PlayerState) string {
 	sb.WriteString(strings.Repeat("-", 24) + "\n")
 	fmt.Fprintf(&sb, "%-18s %5d\n", "Upper Total", p.Scorecard.UpperTotal())
 	if p.Scorecard.HasUpperBonus() {
-		fmt.Fprintf(&sb, "%-18s %5d\n", "Upper Bonus", engine.UpperBonusValue)
+		fmt.Fprintf(&sb, "%-18s %5d\n", "Upper Bonus", p.Scorecard.UpperBonus())
+	}
+	if n := p.Scorecard.YahtzeeBonusCount(); n > 0 {
+		fmt.Fprintf(&sb, "%-18s %5d (x%d)\n", "Yahtzee Bonus", p.Scorecard.YahtzeeBonus(), n)
 	}
 	fmt.Fprintf(&sb, "%-18s %5d\n", "Total", p.Scorecard.Total())
 	return sb.String()
@@ -11,7 +11,7 @@ import (
 
 func setupClient(t *testing.T) *client.Client {
 	t.Helper()
-	s := newServer()
+	s := newServer(nil)
 	c, err := client.NewInProcessClient(s)
 	if err != nil {
 		t.Fatalf("failed to create in-process client: %v", err)
@@ -334,3 +334,23 @@ func TestWaitForTurnRejectsLocalGame(t *testing.T) {
 	assert.True(t, result.IsError)
 	assert.Contains(t, text, "Not connected")
 }
+
+func TestNewGameWithRules(t *testing.T) {
+	c := setupClient(t)
+
+	result := callTool(t, c, "new_game", map[string]interface{}{"rules": "yacht"})
+	text := getText(t, result)
+	if result.IsError {
+		t.Fatalf("unexpected error: %s", text)
+	}
+	for _, want := range []string{"yacht rules", "Round: 1/12", "little_straight"} {
+		if !contains(text, want) {
+			t.Errorf("expected %q in output, got:\n%s", want, text)
+		}
+	}
+
+	result = callTool(t, c, "new_game", map[string]interface{}{"rules": "farkle"})
+	if !result.IsError {
+		t.Error("expected error for unknown rule set")
+	}
+}
@@ -0,0 +1,98 @@
+package p2p
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/edge2992/yatzcli/engine"
+)
+
+const (
+	// afkTimeouts is the number of turns in a row a player may let run out
+	// before they are marked AFK.
+	afkTimeouts = 2
+	// afkTurnLimit caps the clock of a player marked AFK, so their turns are
+	// played quickly until they act again.
+	afkTurnLimit = 10 * time.Second
+)
+
+// turnClock bounds how long a player may sit on a turn. When the clock runs
+// out the server finishes the turn with strategy. A nil *turnClock means
+// turns are untimed.
+type turnClock struct {
+	limit    time.Duration
+	strategy engine.Strategy
+
+	mu       sync.Mutex
+	timeouts map[string]int // consecutive timeouts by player ID
+}
+
+func newTurnClock(limit time.Duration, strategy engine.Strategy) *turnClock {
+	if limit <= 0 {
+		return nil
+	}
+	if strategy == nil {
+		strategy = &engine.StatisticalStrategy{}
+	}
+	return &turnClock{limit: limit, strategy: strategy, timeouts: make(map[string]int)}
+}
+
+// limitFor returns the time playerID has for their next turn, or zero when
+// turns are untimed.
+func (c *turnClock) limitFor(playerID string) time.Duration {
+	if c == nil {
+		return 0
+	}
+	if c.isAFK(playerID) {
+		return min(c.limit, afkTurnLimit)
+	}
+	return c.limit
+}
+
+func (c *turnClock) isAFK(playerID string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.timeouts[playerID] >= afkTimeouts
+}
+
+// acted records that playerID acted in time. It reports whether they were
+// AFK until now.
+func (c *turnClock) acted(playerID string) bool {
+	if c == nil {
+		return false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	wasAFK := c.timeouts[playerID] >= afkTimeouts
+	c.timeouts[playerID] = 0
+	return wasAFK
+}
+
+// timedOut records a timeout for playerID. It reports whether this timeout
+// made them AFK.
+func (c *turnClock) timedOut(playerID string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.timeouts[playerID]++
+	return c.timeouts[playerID] == afkTimeouts
+}
+
+// turnTimer returns a channel that fires after limit, and a function to stop
+// it. A zero limit never fires.
+func turnTimer(limit time.Duration) (<-chan time.Time, func()) {
+	if limit <= 0 {
+		return nil, func() {}
+	}
+	t := time.NewTimer(limit)
+	return t.C, func() { t.Stop() }
+}
+
+// autoPlay finishes the current turn of playerID with the clock's strategy.
+func (c *turnClock) autoPlay(game *engine.Game, playerID string) error {
+	ai := engine.NewAIPlayerWithStrategy(game, playerID, c.strategy)
+	if _, err := ai.PlayTurn(); err != nil {
+		return fmt.Errorf("auto-play turn for %s: %w", playerID, err)
+	}
+	return nil
+}
@@ -23,12 +23,7 @@ func TestE2E_FullGameP2P(t *testing.T) {
 
 	game := engine.NewGame([]string{"Alice", "Bob"}, rand.NewSource(42))
 
-	host := &Host{
-		game:      game,
-		hostName:  "Alice",
-		guestName: "Bob",
-		conn:      hostConn,
-	}
+	host := newHost(game, "Alice", []*clientConn{newClientConn(hostConn, "Bob", "player-1")}, nil)
 
 	type guestResult struct {
 		finalState *engine.GameState
@@ -148,7 +143,7 @@ func TestE2E_FullGameP2P(t *testing.T) {
 		}
 
 		gs := game.GetState()
-		if err := host.sendStateUpdate(gs); err != nil {
+		if err := host.sendStateUpdate(gs, nil); err != nil {
 			t.Fatalf("round %d: send state_update: %v", round, err)
 		}
 
@@ -208,12 +203,7 @@ func TestE2E_FullGameP2PWithHold(t *testing.T) {
 
 	game := engine.NewGame([]string{"Host", "Guest"}, rand.NewSource(99))
 
-	host := &Host{
-		game:      game,
-		hostName:  "Host",
-		guestName: "Guest",
-		conn:      hostConn,
-	}
+	host := newHost(game, "Host", []*clientConn{newClientConn(hostConn, "Guest", "player-1")}, nil)
 
 	type guestResult struct {
 		err error
@@ -307,7 +297,7 @@ func TestE2E_FullGameP2PWithHold(t *testing.T) {
 		}
 
 		gs := game.GetState()
-		if err := host.sendStateUpdate(gs); err != nil {
+		if err := host.sendStateUpdate(gs, nil); err != nil {
 			t.Fatalf("round %d: send state_update: %v", round, err)
 		}
 
@@ -0,0 +1,339 @@
+package p2p
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/edge2992/yatzcli/engine"
+)
+
+// Verifiable dice (see WithVerifiableDice). Every seat commits to a random
+// seed: remote players send the SHA-256 of theirs in the handshake, and the
+// server picks the seeds of the seats it plays itself. game_start lists the
+// commitments, then each remote player reveals their seed to the server.
+// The dice of every roll are derived from the hash of all the seeds and the
+// roll's place in the game (deriveDice). Each reroll's state_update says
+// which dice were kept, and game_over reveals the seeds, so each client can
+// check every roll it saw, and that only the dice said to be kept were.
+//
+// Because every seed is fixed before any is revealed, nobody chooses the
+// dice. They are not secret, though: the server has every seed once play
+// starts, so it knows every roll to come. A host who also plays can use that
+// to choose holds and categories, and nothing here detects it. The proof is
+// that the dice followed the seeds and the announced holds, not that the
+// server played blind.
+
+// seedSize is the length in bytes of a dice seed.
+const seedSize = 32
+
+// seedTimeout is how long the server waits for players to reveal their
+// seeds after game_start.
+const seedTimeout = 30 * time.Second
+
+// ErrDiceTampered reports rolls that don't follow from the revealed seeds.
+var ErrDiceTampered = errors.New("dice tampering detected")
+
+// ErrDiceUnverified is RemoteClient.DiceCheck's answer before game_over,
+// or for a game without verifiable dice.
+var ErrDiceUnverified = errors.New("dice not verified")
+
+// newDiceSeed returns a random seed and its commitment.
+func newDiceSeed() (seed []byte, commitment string) {
+	seed = make([]byte, seedSize)
+	if _, err := rand.Read(seed); err != nil {
+		panic(fmt.Sprintf("read random seed: %v", err))
+	}
+	return seed, commitTo(seed)
+}
+
+// commitTo returns the commitment to seed: its SHA-256, hex-encoded.
+func commitTo(seed []byte) string {
+	sum := sha256.Sum256(seed)
+	return hex.EncodeToString(sum[:])
+}
+
+// decodeSeed decodes a hex seed and checks it against commitment.
+func decodeSeed(seedHex, commitment string) ([]byte, error) {
+	seed, err := hex.DecodeString(seedHex)
+	if err != nil || len(seed) != seedSize {
+		return nil, errors.New("malformed seed")
+	}
+	if commitTo(seed) != commitment {
+		return nil, errors.New("seed does not match its commitment")
+	}
+	return seed, nil
+}
+
+// checkCommitment admits a player to a game with verifiable dice: they must
+// understand them and have committed to a seed.
+func checkCommitment(hs *HandshakePayload) error {
+	if !slices.Contains(hs.Capabilities, CapFairDice) {
+		return errors.New("this game uses verifiable dice, which your client doesn't support; upgrade yatz")
+	}
+	if b, err := hex.DecodeString(hs.Commitment); err != nil || len(b) != sha256.Size {
+		return errors.New("this game uses verifiable dice; the handshake needs a seed commitment")
+	}
+	return nil
+}
+
+// diceKey combines the seeds, in seat order, into the key dice are derived
+// from.
+func diceKey(seeds [][]byte) [sha256.Size]byte {
+	h := sha256.New()
+	for _, s := range seeds {
+		h.Write(s)
+	}
+	var key [sha256.Size]byte
+	h.Sum(key[:0])
+	return key
+}
+
+// deriveDice returns the five dice of roll (1 to engine.MaxRolls) of the
+// turn of the player at index player in round. Each die is a byte of
+// SHA-256(key, round, player, roll, counter) below 252, mod 6; the counter
+// moves on if a hash runs out of usable bytes.
+func deriveDice(key [sha256.Size]byte, round, player, roll int) [5]int {
+	var dice [5]int
+	n := 0
+	for counter := uint32(0); n < len(dice); counter++ {
+		var buf [sha256.Size + 16]byte
+		copy(buf[:], key[:])
+		binary.BigEndian.PutUint32(buf[sha256.Size:], uint32(round))
+		binary.BigEndian.PutUint32(buf[sha256.Size+4:], uint32(player))
+		binary.BigEndian.PutUint32(buf[sha256.Size+8:], uint32(roll))
+		binary.BigEndian.PutUint32(buf[sha256.Size+12:], counter)
+		for _, b := range sha256.Sum256(buf[:]) {
+			if b >= 252 { // 252 = 42*6; higher bytes would favor low faces
+				continue
+			}
+			dice[n] = int(b%6) + 1
+			n++
+			if n == len(dice) {
+				break
+			}
+		}
+	}
+	return dice
+}
+
+// fairDealer rolls a server game's dice from the seats' committed seeds. It
+// is the game's engine.DiceSource; Int63 isn't used for dice.
+type fairDealer struct {
+	commitments []string
+
+	mu    sync.Mutex
+	seeds [][]byte
+	left  int           // seeds not yet revealed
+	ready chan struct{} // closed once every seed is in
+	key   [sha256.Size]byte
+}
+
+func newFairDealer(seats int) *fairDealer {
+	return &fairDealer{
+		commitments: make([]string, seats),
+		seeds:       make([][]byte, seats),
+		left:        seats,
+		ready:       make(chan struct{}),
+	}
+}
+
+// pick chooses the seed of a seat the server plays, such as an AI seat or
+// the host's own.
+func (d *fairDealer) pick(seat int) {
+	seed, commitment := newDiceSeed()
+	d.commitments[seat] = commitment
+	if err := d.reveal(seat, hex.EncodeToString(seed)); err != nil {
+		panic(err)
+	}
+}
+
+// commit records a remote player's commitment, from their handshake.
+func (d *fairDealer) commit(seat int, commitment string) {
+	d.commitments[seat] = commitment
+}
+
+// reveal records a seat's seed. The dice key is set once every seat's seed
+// is in.
+func (d *fairDealer) reveal(seat int, seedHex string) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if seat < 0 || seat >= len(d.seeds) {
+		return fmt.Errorf("no seat %d", seat)
+	}
+	if d.seeds[seat] != nil {
+		return errors.New("seed already revealed")
+	}
+	seed, err := decodeSeed(seedHex, d.commitments[seat])
+	if err != nil {
+		return err
+	}
+	d.seeds[seat] = seed
+	d.left--
+	if d.left == 0 {
+		d.key = diceKey(d.seeds)
+		close(d.ready)
+	}
+	return nil
+}
+
+// wait blocks until every seed is revealed, or timeout passes.
+func (d *fairDealer) wait(timeout time.Duration) error {
+	select {
+	case <-d.ready:
+		return nil
+	case <-time.After(timeout):
+		return errors.New("not every player revealed their dice seed")
+	}
+}
+
+// payload describes the dice for game_start, or with reveal for game_over.
+// It is nil for a nil dealer, so games without verifiable dice carry none.
+func (d *fairDealer) payload(reveal bool) *FairDicePayload {
+	if d == nil {
+		return nil
+	}
+	p := &FairDicePayload{Commitments: d.commitments}
+	if reveal {
+		d.mu.Lock()
+		defer d.mu.Unlock()
+		for _, s := range d.seeds {
+			p.Seeds = append(p.Seeds, hex.EncodeToString(s))
+		}
+	}
+	return p
+}
+
+func (d *fairDealer) DiceFor(round, player, roll int) [5]int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return deriveDice(d.key, round, player, roll)
+}
+
+func (d *fairDealer) Int63() int64 {
+	var b [8]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		panic(fmt.Sprintf("read random: %v", err))
+	}
+	return int64(binary.BigEndian.Uint64(b[:]) >> 1)
+}
+
+func (d *fairDealer) Seed(int64) {}
+
+// rollKey identifies a roll: roll (1 to engine.MaxRolls) of the turn of the
+// player at index player in round.
+type rollKey struct{ round, player, roll int }
+
+// seenRoll is a roll a client saw, and which dice were kept into it if the
+// state_update showing it said so.
+type seenRoll struct {
+	dice      [5]int
+	held      int // bit i set if die i was kept from the previous roll
+	holdKnown bool
+}
+
+// diceWitness records every roll a client sees, to check them against the
+// seeds revealed at game_over.
+type diceWitness struct {
+	commitments []string
+	rolls       map[rollKey]seenRoll
+}
+
+func newDiceWitness(commitments []string) *diceWitness {
+	return &diceWitness{commitments: commitments, rolls: make(map[rollKey]seenRoll)}
+}
+
+// observe records the dice of gs if it shows a roll. For a state_update,
+// update is set and held lists the dice kept from the previous roll; other
+// messages, such as the state sent on a resume, don't tell, and don't
+// replace a roll already seen.
+func (w *diceWitness) observe(gs *engine.GameState, held []int, update bool) {
+	if gs.RollCount == 0 || gs.Phase == engine.PhaseFinished {
+		return
+	}
+	k := rollKey{gs.Round, gs.CurrentPlayerIndex, gs.RollCount}
+	if _, seen := w.rolls[k]; seen && !update {
+		return
+	}
+	r := seenRoll{dice: gs.Dice, holdKnown: update}
+	for _, i := range held {
+		if i >= 0 && i < len(gs.Dice) {
+			r.held |= 1 << i
+		}
+	}
+	w.rolls[k] = r
+}
+
+// check verifies the rolls seen against the seeds fd reveals at game_over.
+func (w *diceWitness) check(fd *FairDicePayload) error {
+	if fd == nil {
+		return fmt.Errorf("%w: the seeds were not revealed", ErrDiceTampered)
+	}
+	return w.verify(fd.Seeds)
+}
+
+// report describes the outcome err of check for the chat.
+func (w *diceWitness) report(err error) string {
+	if err != nil {
+		return "WARNING: " + err.Error()
+	}
+	return fmt.Sprintf("Dice verified: all %d rolls seen match the revealed seeds and holds", len(w.rolls))
+}
+
+// verify checks the revealed seeds against the commitments, and every
+// roll seen against the dice they derive. A die that was rerolled must be
+// the derived die of its roll; one that was held must be what it was
+// before, or, when the previous roll wasn't seen, the derived die of an
+// earlier roll of the turn. For a roll whose holds aren't known, each die
+// may be either.
+func (w *diceWitness) verify(seedsHex []string) error {
+	if len(seedsHex) != len(w.commitments) {
+		return fmt.Errorf("%w: %d seeds revealed for %d players", ErrDiceTampered, len(seedsHex), len(w.commitments))
+	}
+	seeds := make([][]byte, len(seedsHex))
+	for i, s := range seedsHex {
+		seed, err := decodeSeed(s, w.commitments[i])
+		if err != nil {
+			return fmt.Errorf("%w: seat %d: %v", ErrDiceTampered, i+1, err)
+		}
+		seeds[i] = seed
+	}
+	key := diceKey(seeds)
+
+	for k, r := range w.rolls {
+		prev, seenPrev := w.rolls[rollKey{k.round, k.player, k.roll - 1}]
+		derived := deriveDice(key, k.round, k.player, k.roll)
+		kept := func(i, d int) bool {
+			if seenPrev {
+				return d == prev.dice[i]
+			}
+			for roll := 1; roll < k.roll; roll++ {
+				if d == deriveDice(key, k.round, k.player, roll)[i] {
+					return true
+				}
+			}
+			return false
+		}
+		for i, d := range r.dice {
+			var ok bool
+			switch {
+			case !r.holdKnown:
+				ok = d == derived[i] || kept(i, d)
+			case r.held&(1<<i) != 0:
+				ok = kept(i, d)
+			default:
+				ok = d == derived[i]
+			}
+			if !ok {
+				return fmt.Errorf("%w: round %d, player %d, roll %d: dice %v", ErrDiceTampered, k.round, k.player+1, k.roll, r.dice)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,281 @@
+package p2p
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"math/rand"
+	"net"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/edge2992/yatzcli/engine"
+)
+
+func TestDeriveDice(t *testing.T) {
+	key := diceKey([][]byte{make([]byte, seedSize)})
+	counts := make(map[int]int)
+	for round := 1; round <= 13; round++ {
+		for roll := 1; roll <= engine.MaxRolls; roll++ {
+			dice := deriveDice(key, round, 0, roll)
+			if again := deriveDice(key, round, 0, roll); again != dice {
+				t.Fatalf("round %d roll %d: %v then %v", round, roll, dice, again)
+			}
+			for _, d := range dice {
+				if d < 1 || d > 6 {
+					t.Fatalf("round %d roll %d: die %d out of range", round, roll, d)
+				}
+				counts[d]++
+			}
+		}
+	}
+	if len(counts) != 6 {
+		t.Errorf("faces seen = %v, want all six", counts)
+	}
+	if deriveDice(key, 1, 0, 1) == deriveDice(key, 1, 1, 1) && deriveDice(key, 2, 0, 1) == deriveDice(key, 2, 1, 1) {
+		t.Error("both players got the same dice twice")
+	}
+}
+
+func TestFairDealer_Reveal(t *testing.T) {
+	d := newFairDealer(2)
+	d.pick(0)
+	seed, commitment := newDiceSeed()
+	d.commit(1, commitment)
+
+	other, _ := newDiceSeed()
+	if err := d.reveal(1, hex.EncodeToString(other)); err == nil {
+		t.Fatal("revealing a seed that wasn't committed to should fail")
+	}
+	if err := d.wait(10 * time.Millisecond); err == nil {
+		t.Fatal("dealer ready before every seed was revealed")
+	}
+	if err := d.reveal(1, hex.EncodeToString(seed)); err != nil {
+		t.Fatalf("reveal: %v", err)
+	}
+	if err := d.wait(time.Second); err != nil {
+		t.Fatalf("wait: %v", err)
+	}
+	if err := d.reveal(1, hex.EncodeToString(seed)); err == nil {
+		t.Error("revealing a seed twice should fail")
+	}
+
+	p := d.payload(true)
+	if len(p.Seeds) != 2 || p.Seeds[1] != hex.EncodeToString(seed) {
+		t.Errorf("revealed seeds = %v", p.Seeds)
+	}
+}
+
+func TestServer_VerifiableDice(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping server test in short mode")
+	}
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	defer ln.Close()
+	addr := ln.Addr().String()
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- RunServer(ln, 2, rand.NewSource(42),
+			WithVerifiableDice(),
+			WithAISeats(AISeat{Name: "Bot", Strategy: &engine.GreedyStrategy{}}),
+			WithAIDelay(0))
+	}()
+
+	// A client without a commitment is turned away.
+	old, _ := net.Dial("tcp", addr)
+	defer old.Close()
+	if err := WriteMessage(old, NewHandshakeMsg("Old")); err != nil {
+		t.Fatalf("send handshake: %v", err)
+	}
+	readExpectType(t, old, MsgError)
+
+	rc, err := NewRemoteClient(addr, "Alice")
+	if err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	defer rc.Close()
+	if !rc.Features().Has(CapFairDice) {
+		t.Fatalf("features = %s, want fair_dice", rc.Features())
+	}
+
+	gs, over, err := rc.WaitForTurn()
+
+	// A spectator who joins after the start can check the dice too.
+	w, werr := Watch(addr, "Eve")
+	if werr != nil {
+		t.Fatalf("watch: %v", werr)
+	}
+	defer w.Close()
+	go func() {
+		for range w.States() {
+		}
+	}()
+
+	for err == nil && !over {
+		if _, err = rc.Roll(); err != nil {
+			break
+		}
+		if _, err = rc.Hold([]int{0, 1}); err != nil {
+			break
+		}
+		// Alice plays first, so the game ends on the Bot's turn and Score
+		// returns the game_over state.
+		gs, err = rc.Score(gs.AvailableCategories[0])
+		over = err == nil && gs.Phase == engine.PhaseFinished
+	}
+	if err != nil {
+		t.Fatalf("play: %v", err)
+	}
+	if err := <-errCh; err != nil {
+		t.Fatalf("server error: %v", err)
+	}
+	if err := rc.DiceCheck(); err != nil {
+		t.Errorf("DiceCheck() = %v, want nil", err)
+	}
+	if err := <-w.Done(); err != nil {
+		t.Fatalf("watch: %v", err)
+	}
+	if err := w.DiceCheck(); err != nil {
+		t.Errorf("spectator DiceCheck() = %v, want nil", err)
+	}
+}
+
+func TestRemoteClient_DetectsTampering(t *testing.T) {
+	// Each case shows two rolls of the host's turn, the second keeping the
+	// first two dice, given the derived dice of both.
+	tests := []struct {
+		name   string
+		rolls  func(first, second [5]int) (roll1, roll2 [5]int, held []int)
+		rigged bool
+	}{
+		{"honest", func(first, second [5]int) ([5]int, [5]int, []int) {
+			return first, [5]int{first[0], first[1], second[2], second[3], second[4]}, []int{0, 1}
+		}, false},
+		{"rigged roll", func(first, second [5]int) ([5]int, [5]int, []int) {
+			first[0] = first[0]%6 + 1
+			return first, [5]int{first[0], first[1], second[2], second[3], second[4]}, []int{0, 1}
+		}, true},
+		{"held die changed", func(first, second [5]int) ([5]int, [5]int, []int) {
+			return first, [5]int{first[0], first[1]%6 + 1, second[2], second[3], second[4]}, []int{0, 1}
+		}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ln, err := net.Listen("tcp", "127.0.0.1:0")
+			if err != nil {
+				t.Fatalf("listen: %v", err)
+			}
+			defer ln.Close()
+
+			// The host plays seat 1 and shows rolls of its own, then
+			// reveals the seeds.
+			go func() {
+				conn, err := ln.Accept()
+				if err != nil {
+					return
+				}
+				defer conn.Close()
+				msg, _ := ReadMessage(conn)
+				hs, _ := DecodeHandshake(msg)
+				hostSeed, hostCommitment := newDiceSeed()
+				f := Features{Version: ProtocolVersion, Capabilities: []string{CapFairDice}}
+				_ = WriteMessage(conn, handshakeReply(HandshakePayload{Name: "host", PlayerID: "player-0"}, f))
+				fd := &FairDicePayload{Commitments: []string{hs.Commitment, hostCommitment}}
+				_ = WriteMessage(conn, newMessage(MsgGameStart, StatePayload{FairDice: fd}))
+
+				msg, _ = ReadMessage(conn)
+				sp, _ := DecodeSeed(msg)
+				guestSeed, _ := hex.DecodeString(sp.Seed)
+				key := diceKey([][]byte{guestSeed, hostSeed})
+				roll1, roll2, held := tt.rolls(deriveDice(key, 1, 1, 1), deriveDice(key, 1, 1, 2))
+				_ = WriteMessage(conn, NewStateUpdateMsg(engine.GameState{Round: 1, CurrentPlayerIndex: 1, RollCount: 1, Dice: roll1}))
+				_ = WriteMessage(conn, newStateUpdateMsgWithHeld(engine.GameState{Round: 1, CurrentPlayerIndex: 1, RollCount: 2, Dice: roll2}, held))
+				fd.Seeds = []string{sp.Seed, hex.EncodeToString(hostSeed)}
+				_ = WriteMessage(conn, newMessage(MsgGameOver, StatePayload{State: engine.GameState{Phase: engine.PhaseFinished}, FairDice: fd}))
+			}()
+
+			rc, err := NewRemoteClient(ln.Addr().String(), "Alice")
+			if err != nil {
+				t.Fatalf("connect: %v", err)
+			}
+			defer rc.Close()
+			if err := rc.DiceCheck(); !errors.Is(err, ErrDiceUnverified) {
+				t.Errorf("DiceCheck() before game_over = %v, want ErrDiceUnverified", err)
+			}
+			if _, over, err := rc.WaitForTurn(); err != nil || !over {
+				t.Fatalf("WaitForTurn() = %v, %v; want game over", over, err)
+			}
+			err = rc.DiceCheck()
+			if tt.rigged && !errors.Is(err, ErrDiceTampered) {
+				t.Errorf("DiceCheck() = %v, want ErrDiceTampered", err)
+			}
+			if !tt.rigged && err != nil {
+				t.Errorf("DiceCheck() = %v, want nil", err)
+			}
+		})
+	}
+}
+
+func TestDiceWitness_UnannouncedKeep(t *testing.T) {
+	seed := make([]byte, seedSize)
+	key := diceKey([][]byte{seed})
+	first, second := deriveDice(key, 1, 0, 1), deriveDice(key, 1, 0, 2)
+	i := slices.IndexFunc([]int{0, 1, 2, 3, 4}, func(i int) bool { return first[i] != second[i] })
+	if i < 0 {
+		t.Fatal("rolls 1 and 2 are the same")
+	}
+
+	// Die i keeps its value after the reroll, though the hold didn't.
+	rigged := second
+	rigged[i] = first[i]
+	w := newDiceWitness([]string{commitTo(seed)})
+	w.observe(&engine.GameState{Round: 1, RollCount: 1, Dice: first}, nil, true)
+	w.observe(&engine.GameState{Round: 1, RollCount: 2, Dice: rigged}, nil, true)
+	if err := w.verify([]string{hex.EncodeToString(seed)}); !errors.Is(err, ErrDiceTampered) {
+		t.Errorf("verify() = %v, want ErrDiceTampered", err)
+	}
+
+	// Announced, the same keep is fine.
+	w.observe(&engine.GameState{Round: 1, RollCount: 2, Dice: rigged}, []int{i}, true)
+	if err := w.verify([]string{hex.EncodeToString(seed)}); err != nil {
+		t.Errorf("verify() = %v, want nil", err)
+	}
+}
+
+func TestDiceWitness_ResumedMidTurn(t *testing.T) {
+	seed, commitment := newDiceSeed()
+	key := diceKey([][]byte{seed})
+	first, second := deriveDice(key, 1, 0, 1), deriveDice(key, 1, 0, 2)
+
+	// Joining at roll 2, without its hold: each die is from roll 1 or 2.
+	w := newDiceWitness([]string{commitment})
+	w.observe(&engine.GameState{Round: 1, RollCount: 2, Dice: [5]int{first[0], second[1], second[2], second[3], second[4]}}, nil, false)
+	if err := w.verify([]string{hex.EncodeToString(seed)}); err != nil {
+		t.Errorf("verify() = %v, want nil", err)
+	}
+
+	w.observe(&engine.GameState{Round: 1, RollCount: 3, Dice: [5]int{7, 7, 7, 7, 7}}, nil, false)
+	if err := w.verify([]string{hex.EncodeToString(seed)}); !errors.Is(err, ErrDiceTampered) {
+		t.Errorf("verify() = %v, want ErrDiceTampered", err)
+	}
+}
+
+func TestCheckCommitment(t *testing.T) {
+	sum := sha256.Sum256([]byte("seed"))
+	ok := &HandshakePayload{Capabilities: clientCapabilities, Commitment: hex.EncodeToString(sum[:])}
+	if err := checkCommitment(ok); err != nil {
+		t.Errorf("checkCommitment(valid) = %v", err)
+	}
+	if err := checkCommitment(&HandshakePayload{Capabilities: clientCapabilities}); err == nil {
+		t.Error("a handshake without a commitment should be refused")
+	}
+	if err := checkCommitment(&HandshakePayload{Commitment: ok.Commitment}); err == nil {
+		t.Error("a client without the fair_dice capability should be refused")
+	}
+}
@@ -1,25 +1,39 @@
 package p2p
 
 import (
+	"crypto/tls"
+	"encoding/hex"
+	"errors"
 	"fmt"
 	"net"
+	"slices"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/edge2992/yatzcli/cli"
 	"github.com/edge2992/yatzcli/engine"
+	"github.com/edge2992/yatzcli/stats"
 )
 
 // RemoteClient implements engine.GameClient by sending actions to the host
 // over TCP and receiving state updates back. All reads from the connection
 // happen in a single background goroutine (listen), and action responses
-// are delivered via responseCh.
+// are delivered via responseCh. When the connection drops, listen redials
+// and resumes the seat with the session token from the handshake.
 type RemoteClient struct {
-	conn       net.Conn
-	writeMu    sync.Mutex
+	conn    net.Conn // guarded by writeMu; replaced on resume
+	writeMu sync.Mutex
+	// actionConn is the connection the pending action was sent on.
+	actionConn net.Conn
 	stateMu    sync.Mutex
 	lastState  *engine.GameState
-	playerID   string
-	playerName string
+	// turnDeadline is when the server plays our current turn for us.
+	turnDeadline time.Time
+	playerID     string
+	playerName   string
+	// coach is true when every player agreed to in-game hints.
+	coach bool
 
 	// responseCh delivers state_update/error responses to sendAction calls.
 	responseCh chan responseResult
@@ -33,13 +47,29 @@ type RemoteClient struct {
 	turnCh chan *engine.GameState
 	// gameOverCh delivers game_over state.
 	gameOverCh chan *engine.GameState
-	// chatCh delivers chat messages from the host.
+	// chatCh delivers chat messages and announcements from the host.
 	chatCh chan *ChatPayload
 	// stateUpdateCh delivers broadcast state updates (opponent actions) to the TUI.
 	stateUpdateCh chan *engine.GameState
 	// listenErr holds any fatal error from the listener.
 	listenErr error
 	listenMu  sync.Mutex
+
+	// addr and token are used to resume after a dropped connection; addr
+	// is empty when the client cannot redial.
+	addr   string
+	token  string
+	grace  time.Duration
+	closed atomic.Bool
+	// features were negotiated in the handshake.
+	features Features
+	// tlsConfig, if set, secures the redial on resume.
+	tlsConfig *tls.Config
+	// witness records the rolls seen in a game with verifiable dice, and
+	// diceErr is the outcome of checking them at game_over. Both are
+	// guarded by stateMu.
+	witness *diceWitness
+	diceErr error
 }
 
 type responseResult struct {
@@ -48,24 +78,30 @@ type responseResult struct {
 }
 
 // NewRemoteClient connects to the host and performs the handshake.
-func NewRemoteClient(addr string, name string) (*RemoteClient, error) {
-	conn, err := net.Dial("tcp", addr)
+func NewRemoteClient(addr string, name string, opts ...Option) (*RemoteClient, error) {
+	conn, err := dial(addr, 0, newOptions(opts).tlsConfig)
 	if err != nil {
 		return nil, fmt.Errorf("connect: %w", err)
 	}
 
-	rc, err := newRemoteClientFromConn(conn, name)
+	rc, err := newRemoteClientFromConn(conn, name, opts...)
 	if err != nil {
 		conn.Close()
 		return nil, err
 	}
+	rc.addr = addr
 	return rc, nil
 }
 
 // newRemoteClientFromConn creates a RemoteClient from an existing connection.
-func newRemoteClientFromConn(conn net.Conn, name string) (*RemoteClient, error) {
-	// Send handshake
-	if err := WriteMessage(conn, NewHandshakeMsg(name)); err != nil {
+func newRemoteClientFromConn(conn net.Conn, name string, opts ...Option) (*RemoteClient, error) {
+	o := newOptions(opts)
+
+	// Send handshake, committing to a dice seed in case the game uses
+	// verifiable dice.
+	seed, commitment := newDiceSeed()
+	hello := newHello(HandshakePayload{Name: name, Coach: o.coach != nil, Password: o.password, Commitment: commitment, AccountKey: o.accountKey})
+	if err := WriteMessage(conn, hello); err != nil {
 		return nil, fmt.Errorf("send handshake: %w", err)
 	}
 
@@ -74,6 +110,12 @@ func newRemoteClientFromConn(conn net.Conn, name string) (*RemoteClient, error)
 	if err != nil {
 		return nil, fmt.Errorf("read handshake: %w", err)
 	}
+	if msg.Type == MsgError {
+		if ep, err := DecodeError(msg); err == nil {
+			return nil, fmt.Errorf("host refused: %s", ep.Message)
+		}
+		return nil, errors.New("host refused")
+	}
 	if msg.Type != MsgHandshake {
 		return nil, fmt.Errorf("expected handshake, got %s", msg.Type)
 	}
@@ -81,9 +123,9 @@ func newRemoteClientFromConn(conn net.Conn, name string) (*RemoteClient, error)
 	if err != nil {
 		return nil, fmt.Errorf("decode handshake: %w", err)
 	}
-	playerID := hs.PlayerID
-	if playerID == "" {
-		playerID = "player-1" // backward compat with old host
+	f, err := acceptedFeatures(hs)
+	if err != nil {
+		return nil, err
 	}
 
 	// Wait for game_start
@@ -98,29 +140,56 @@ func newRemoteClientFromConn(conn net.Conn, name string) (*RemoteClient, error)
 	if err != nil {
 		return nil, fmt.Errorf("decode game_start: %w", err)
 	}
+	if f.Has(CapFairDice) {
+		if sp.FairDice == nil || !slices.Contains(sp.FairDice.Commitments, commitment) {
+			return nil, fmt.Errorf("%w: game_start doesn't list our seed commitment", ErrDiceTampered)
+		}
+		if err := WriteMessage(conn, NewSeedMsg(hex.EncodeToString(seed))); err != nil {
+			return nil, fmt.Errorf("send seed: %w", err)
+		}
+	}
 
+	return newRemoteClient(conn, name, hs.PlayerID, hs.Token, f, sp, o), nil
+}
+
+// newRemoteClient starts a RemoteClient on conn once game_start (sp) has
+// been received.
+func newRemoteClient(conn net.Conn, name, playerID, token string, f Features, sp *StatePayload, o options) *RemoteClient {
 	rc := &RemoteClient{
 		conn:          conn,
 		lastState:     &sp.State,
 		playerID:      playerID,
 		playerName:    name,
+		coach:         sp.Coach,
 		responseCh:    make(chan responseResult, 1),
 		turnCh:        make(chan *engine.GameState, 1),
 		gameOverCh:    make(chan *engine.GameState, 1),
 		chatCh:        make(chan *ChatPayload, 16),
 		stateUpdateCh: make(chan *engine.GameState, 16),
+		token:         token,
+		features:      f,
+		grace:         o.grace,
+		tlsConfig:     o.tlsConfig,
+		diceErr:       ErrDiceUnverified,
+	}
+	if sp.FairDice != nil {
+		rc.witness = newDiceWitness(sp.FairDice.Commitments)
 	}
 
 	go rc.listen()
 
-	return rc, nil
+	return rc
 }
 
 // listen reads all messages from the host and dispatches them.
 func (rc *RemoteClient) listen() {
+	lastTurnRound := 0
 	for {
-		msg, err := ReadMessage(rc.conn)
+		msg, err := ReadMessage(rc.currentConn())
 		if err != nil {
+			if rc.reconnect() {
+				continue
+			}
 			connErr := fmt.Errorf("connection lost: %w", err)
 			rc.listenMu.Lock()
 			rc.listenErr = connErr
@@ -141,21 +210,15 @@ func (rc *RemoteClient) listen() {
 		case MsgStateUpdate:
 			sp, err := DecodeState(msg)
 			if err != nil {
-				rc.expectMu.Lock()
-				expecting := rc.expectResponse
-				rc.expectMu.Unlock()
-				if expecting {
+				if rc.takeResponse() {
 					rc.responseCh <- responseResult{err: fmt.Errorf("decode state_update: %w", err)}
 				}
 				continue
 			}
-			rc.setLastState(&sp.State)
+			rc.setLastUpdate(sp)
 			// Only deliver to responseCh if sendAction is waiting.
 			// Otherwise this is a broadcast from the host's own turn.
-			rc.expectMu.Lock()
-			expecting := rc.expectResponse
-			rc.expectMu.Unlock()
-			if expecting {
+			if rc.takeResponse() {
 				rc.responseCh <- responseResult{state: &sp.State}
 			} else {
 				// Broadcast update (opponent action) — notify TUI
@@ -166,9 +229,7 @@ func (rc *RemoteClient) listen() {
 			}
 
 		case MsgError:
-			rc.expectMu.Lock()
-			expecting := rc.expectResponse
-			rc.expectMu.Unlock()
+			expecting := rc.takeResponse()
 			ep, err := DecodeError(msg)
 			if err != nil {
 				if expecting {
@@ -178,6 +239,12 @@ func (rc *RemoteClient) listen() {
 			}
 			if expecting {
 				rc.responseCh <- responseResult{err: fmt.Errorf("%s", ep.Message)}
+			} else {
+				// Unsolicited errors are announcements (e.g. a disconnect).
+				select {
+				case rc.chatCh <- &ChatPayload{Name: "*", Text: ep.Message}:
+				default:
+				}
 			}
 
 		case MsgTurnStart:
@@ -186,7 +253,18 @@ func (rc *RemoteClient) listen() {
 				continue
 			}
 			rc.setLastState(&sp.State)
-			rc.turnCh <- &sp.State
+			rc.setTurnDeadline(sp.TimeLeftMs)
+			// A resume re-sends turn_start; only the first one for a
+			// round starts the turn. A turn the server played for us
+			// leaves its turn_start unread, so replace it.
+			if sp.State.Round != lastTurnRound {
+				lastTurnRound = sp.State.Round
+				select {
+				case <-rc.turnCh:
+				default:
+				}
+				rc.turnCh <- &sp.State
+			}
 			// Also notify TUI for state refresh
 			select {
 			case rc.stateUpdateCh <- &sp.State:
@@ -209,16 +287,181 @@ func (rc *RemoteClient) listen() {
 				continue
 			}
 			rc.setLastState(&sp.State)
+			rc.checkDice(sp.FairDice)
 			rc.gameOverCh <- &sp.State
 			return
 		}
 	}
 }
 
+// takeResponse reports whether sendAction is waiting for a response and
+// claims it, so only the first state_update or error after an action
+// answers it. Updates that follow, such as an AI seat's moves, are
+// broadcasts.
+func (rc *RemoteClient) takeResponse() bool {
+	rc.expectMu.Lock()
+	defer rc.expectMu.Unlock()
+	expecting := rc.expectResponse
+	rc.expectResponse = false
+	return expecting
+}
+
+func (rc *RemoteClient) currentConn() net.Conn {
+	rc.writeMu.Lock()
+	defer rc.writeMu.Unlock()
+	return rc.conn
+}
+
+// reconnect redials the host and resumes the seat, retrying for the grace
+// period. It reports whether the client is connected again.
+func (rc *RemoteClient) reconnect() bool {
+	if rc.addr == "" || rc.token == "" || rc.closed.Load() {
+		return false
+	}
+	rc.notice("connection lost, reconnecting...")
+
+	deadline := time.Now().Add(rc.grace)
+	backoff := 250 * time.Millisecond
+	for time.Now().Before(deadline) && !rc.closed.Load() {
+		sp, conn, err := rc.resume()
+		if err == nil {
+			rc.writeMu.Lock()
+			old := rc.conn
+			rc.conn = conn
+			rc.expectMu.Lock()
+			// An action sent on the old connection may have been lost;
+			// answer it with the current state so the caller can carry on.
+			lost := rc.expectResponse && rc.actionConn == old
+			rc.expectMu.Unlock()
+			rc.writeMu.Unlock()
+			old.Close()
+
+			rc.setLastState(sp.State)
+			rc.notice("reconnected")
+			select {
+			case rc.stateUpdateCh <- sp.State:
+			default:
+			}
+			if lost {
+				rc.responseCh <- responseResult{state: sp.State}
+			}
+			return true
+		}
+		if err == errSessionExpired {
+			return false
+		}
+		time.Sleep(backoff)
+		backoff = min(backoff*2, 5*time.Second)
+	}
+	return false
+}
+
+// resume dials the host and asks for this client's seat back.
+func (rc *RemoteClient) resume() (*ResumePayload, net.Conn, error) {
+	conn, err := dial(rc.addr, 5*time.Second, rc.tlsConfig)
+	if err != nil {
+		return nil, nil, err
+	}
+	conn.SetDeadline(time.Now().Add(10 * time.Second))
+	if err := WriteMessage(conn, NewResumeMsg(rc.token)); err != nil {
+		conn.Close()
+		return nil, nil, err
+	}
+	msg, err := ReadMessage(conn)
+	if err != nil {
+		conn.Close()
+		return nil, nil, err
+	}
+	conn.SetDeadline(time.Time{})
+	switch msg.Type {
+	case MsgResume:
+		rp, err := DecodeResume(msg)
+		if err != nil || rp.State == nil {
+			conn.Close()
+			return nil, nil, fmt.Errorf("bad resume reply")
+		}
+		return rp, conn, nil
+	case MsgError:
+		conn.Close()
+		return nil, nil, errSessionExpired
+	default:
+		conn.Close()
+		return nil, nil, fmt.Errorf("expected resume, got %s", msg.Type)
+	}
+}
+
+// notice shows a connection status line in the chat.
+func (rc *RemoteClient) notice(text string) {
+	select {
+	case rc.chatCh <- &ChatPayload{Name: "*", Text: text}:
+	default:
+	}
+}
+
+// setTurnDeadline records the clock sent with turn_start.
+func (rc *RemoteClient) setTurnDeadline(timeLeftMs int64) {
+	rc.stateMu.Lock()
+	defer rc.stateMu.Unlock()
+	if timeLeftMs > 0 {
+		rc.turnDeadline = time.Now().Add(time.Duration(timeLeftMs) * time.Millisecond)
+	} else {
+		rc.turnDeadline = time.Time{}
+	}
+}
+
+// TurnDeadline returns when the server plays this player's current turn for
+// them, or the zero time if it is not their turn or turns are untimed.
+func (rc *RemoteClient) TurnDeadline() time.Time {
+	rc.stateMu.Lock()
+	defer rc.stateMu.Unlock()
+	if rc.lastState == nil || rc.lastState.CurrentPlayer != rc.playerID {
+		return time.Time{}
+	}
+	return rc.turnDeadline
+}
+
 func (rc *RemoteClient) setLastState(gs *engine.GameState) {
+	rc.storeState(gs, nil, false)
+}
+
+// setLastUpdate is setLastState for a state_update, which also says which
+// dice were kept into the roll it shows.
+func (rc *RemoteClient) setLastUpdate(sp *StatePayload) {
+	rc.storeState(&sp.State, sp.Held, true)
+}
+
+func (rc *RemoteClient) storeState(gs *engine.GameState, held []int, update bool) {
 	rc.stateMu.Lock()
 	defer rc.stateMu.Unlock()
 	rc.lastState = gs
+	if rc.witness != nil {
+		rc.witness.observe(gs, held, update)
+	}
+}
+
+// checkDice checks the rolls seen against the seeds revealed at game_over
+// and posts the outcome in the chat.
+func (rc *RemoteClient) checkDice(fd *FairDicePayload) {
+	rc.stateMu.Lock()
+	if rc.witness == nil {
+		rc.stateMu.Unlock()
+		return
+	}
+	err := rc.witness.check(fd)
+	rc.diceErr = err
+	report := rc.witness.report(err)
+	rc.stateMu.Unlock()
+	rc.notice(report)
+}
+
+// DiceCheck reports whether the dice of a game with verifiable dice were
+// fair: nil once every roll seen matched the seeds revealed at game_over,
+// or an error wrapping ErrDiceTampered. It is ErrDiceUnverified before
+// game_over and for games without verifiable dice.
+func (rc *RemoteClient) DiceCheck() error {
+	rc.stateMu.Lock()
+	defer rc.stateMu.Unlock()
+	return rc.diceErr
 }
 
 func (rc *RemoteClient) getLastState() *engine.GameState {
@@ -229,13 +472,17 @@ func (rc *RemoteClient) getLastState() *engine.GameState {
 
 // sendAction sends an action and waits for the response from the listener.
 func (rc *RemoteClient) sendAction(ap ActionPayload) (*engine.GameState, error) {
+	rc.writeMu.Lock()
 	rc.expectMu.Lock()
 	rc.expectResponse = true
 	rc.expectMu.Unlock()
-
-	rc.writeMu.Lock()
+	rc.actionConn = rc.conn
 	err := WriteMessage(rc.conn, NewActionMsg(ap))
 	rc.writeMu.Unlock()
+	if err != nil && rc.addr != "" && rc.token != "" {
+		// The listener is redialing; it answers with the resumed state.
+		err = nil
+	}
 	if err != nil {
 		rc.expectMu.Lock()
 		rc.expectResponse = false
@@ -261,6 +508,7 @@ func (rc *RemoteClient) Hold(indices []int) (*engine.GameState, error) {
 }
 
 func (rc *RemoteClient) Score(category engine.Category) (*engine.GameState, error) {
+	played := rc.getLastState().Round
 	gs, err := rc.sendAction(ActionPayload{Action: ActionScore, Category: string(category)})
 	if err != nil {
 		return nil, err
@@ -271,18 +519,23 @@ func (rc *RemoteClient) Score(category engine.Category) (*engine.GameState, erro
 	}
 
 	// After scoring, it's the host's turn. Wait for turn_start or game_over.
-	select {
-	case state := <-rc.turnCh:
-		if state == nil {
-			// Listener errored
-			rc.listenMu.Lock()
-			err := rc.listenErr
-			rc.listenMu.Unlock()
-			return nil, err
+	for {
+		select {
+		case state := <-rc.turnCh:
+			if state == nil {
+				// Listener errored
+				rc.listenMu.Lock()
+				err := rc.listenErr
+				rc.listenMu.Unlock()
+				return nil, err
+			}
+			if state.Round <= played {
+				continue // the turn we just played
+			}
+			return state, nil
+		case state := <-rc.gameOverCh:
+			return state, nil
 		}
-		return state, nil
-	case state := <-rc.gameOverCh:
-		return state, nil
 	}
 }
 
@@ -324,18 +577,35 @@ func (rc *RemoteClient) PlayerID() string {
 	return rc.playerID
 }
 
+// Features returns the protocol version and capabilities negotiated with
+// the server.
+func (rc *RemoteClient) Features() Features {
+	return rc.features
+}
+
+// CoachEnabled reports whether every player agreed to in-game hints.
+func (rc *RemoteClient) CoachEnabled() bool {
+	return rc.coach
+}
+
 func (rc *RemoteClient) Close() error {
-	return rc.conn.Close()
+	rc.closed.Store(true)
+	return rc.currentConn().Close()
 }
 
 // RunGuest connects to a host and plays as the guest.
-func RunGuest(addr string, name string) error {
-	rc, err := NewRemoteClient(addr, name)
+func RunGuest(addr string, name string, opts ...Option) error {
+	o := newOptions(opts)
+	rc, err := NewRemoteClient(addr, name, opts...)
 	if err != nil {
 		return err
 	}
 	defer rc.Close()
+	return runGuestTUI(rc, name, o)
+}
 
+// runGuestTUI plays the game behind rc in the TUI.
+func runGuestTUI(rc *RemoteClient, name string, o options) error {
 	chatCh := make(chan cli.ChatEntry, 16)
 	go func() {
 		for cp := range rc.ChatCh() {
@@ -353,15 +623,30 @@ func RunGuest(addr string, name string) error {
 	}()
 
 	gs := rc.getLastState()
-	opts := []cli.GameOption{
+	f := rc.Features()
+	guiOpts := []cli.GameOption{
 		cli.WithChatChannel(chatCh),
 		cli.WithStateUpdateChannel(stateUpdateCh),
+		cli.WithConnectionInfo(f.String()),
+	}
+	if f.Has(CapTurnClock) {
+		guiOpts = append(guiOpts, cli.WithTurnDeadline(rc.TurnDeadline))
+	}
+
+	if rc.CoachEnabled() && o.coach != nil {
+		guiOpts = append(guiOpts, cli.WithCoach(o.coach))
+	} else if o.coach != nil {
+		fmt.Println("Hints disabled: not every player enabled --coach")
 	}
 
 	// If opponent goes first, start TUI in waiting state
 	if gs.CurrentPlayer != rc.playerID {
-		opts = append(opts, cli.WithInitialWaiting())
+		guiOpts = append(guiOpts, cli.WithInitialWaiting())
 	}
 
-	return cli.RunGame(rc, name, opts...)
+	if err := cli.RunGame(rc, name, guiOpts...); err != nil {
+		return err
+	}
+	o.recordGame(stats.ModeP2P, *rc.getLastState(), rc.playerID, nil)
+	return nil
 }
@@ -1,8 +1,10 @@
 package p2p
 
 import (
+	"errors"
 	"math/rand"
 	"net"
+	"slices"
 	"testing"
 	"time"
 
@@ -25,7 +27,9 @@ func newMockHost(conn net.Conn, rngSrc rand.Source) (*mockHost, error) {
 	}
 	hs, _ := DecodeHandshake(msg)
 
-	if err := WriteMessage(conn, NewHandshakeMsg("MockHost")); err != nil {
+	o := newOptions(nil)
+	reply := handshakeReply(HandshakePayload{Name: "MockHost", PlayerID: "player-1"}, negotiate(o.capabilities(), hs))
+	if err := WriteMessage(conn, reply); err != nil {
 		return nil, err
 	}
 
@@ -293,10 +297,10 @@ func TestRemoteClient_DynamicPlayerID(t *testing.T) {
 		}
 
 		// Send handshake response with PlayerID set
-		hsResp := newMessage(MsgHandshake, HandshakePayload{
+		hsResp := handshakeReply(HandshakePayload{
 			Name:     "Host",
 			PlayerID: "player-42",
-		})
+		}, Features{})
 		if err := WriteMessage(hostConn, hsResp); err != nil {
 			return
 		}
@@ -319,29 +323,46 @@ func TestRemoteClient_DynamicPlayerID(t *testing.T) {
 	}
 }
 
-func TestRemoteClient_DynamicPlayerID_BackwardCompat(t *testing.T) {
+func TestRemoteClient_RejectsUnversionedHost(t *testing.T) {
 	hostConn, guestConn := net.Pipe()
 	defer hostConn.Close()
 	defer guestConn.Close()
 
 	go func() {
-		msg, err := ReadMessage(hostConn)
-		if err != nil {
-			return
-		}
-		if msg.Type != MsgHandshake {
+		if _, err := ReadMessage(hostConn); err != nil {
 			return
 		}
+		// A host from before versioning replies without a version.
+		_ = WriteMessage(hostConn, newMessage(MsgHandshake, HandshakePayload{Name: "OldHost"}))
+	}()
+
+	_, err := newRemoteClientFromConn(guestConn, "Guest")
+	if !errors.Is(err, ErrIncompatibleVersion) {
+		t.Errorf("newRemoteClientFromConn = %v, want ErrIncompatibleVersion", err)
+	}
+}
+
+func TestRemoteClient_Features(t *testing.T) {
+	hostConn, guestConn := net.Pipe()
+	defer hostConn.Close()
+	defer guestConn.Close()
 
-		// Send handshake response WITHOUT PlayerID (old host)
-		if err := WriteMessage(hostConn, NewHandshakeMsg("OldHost")); err != nil {
+	go func() {
+		msg, err := ReadMessage(hostConn)
+		if err != nil {
 			return
 		}
-
-		state := sampleState()
-		if err := WriteMessage(hostConn, NewGameStartMsg(state)); err != nil {
+		hs, _ := DecodeHandshake(msg)
+		if hs.Version != ProtocolVersion || !slices.Contains(hs.Capabilities, CapResume) {
+			t.Errorf("guest handshake = %+v", hs)
+		}
+		// This host has no turn clock and doesn't hold seats.
+		f := negotiate([]string{CapChat, CapCoach}, hs)
+		reply := handshakeReply(HandshakePayload{Name: "Host", PlayerID: "player-1", Token: "tok"}, f)
+		if err := WriteMessage(hostConn, reply); err != nil {
 			return
 		}
+		_ = WriteMessage(hostConn, NewGameStartMsg(sampleState()))
 	}()
 
 	rc, err := newRemoteClientFromConn(guestConn, "Guest")
@@ -350,8 +371,12 @@ func TestRemoteClient_DynamicPlayerID_BackwardCompat(t *testing.T) {
 	}
 	defer rc.Close()
 
-	if rc.playerID != "player-1" {
-		t.Errorf("expected playerID player-1 (backward compat), got %s", rc.playerID)
+	f := rc.Features()
+	if f.Version != ProtocolVersion || !f.Has(CapChat) || !f.Has(CapCoach) {
+		t.Errorf("features = %+v", f)
+	}
+	if f.Has(CapResume) || rc.token != "" {
+		t.Errorf("resume negotiated without the host offering it: %+v, token %q", f, rc.token)
 	}
 }
 
@@ -4,24 +4,72 @@ import (
 	"fmt"
 	"math/rand"
 	"net"
+	"slices"
 	"sync"
+	"time"
 
 	"github.com/edge2992/yatzcli/cli"
 	"github.com/edge2992/yatzcli/engine"
+	"github.com/edge2992/yatzcli/stats"
 )
 
 // Host manages a P2P game session, holding the authoritative Game instance.
+// The host plays as player-0 from its own TUI; each guest connects over TCP
+// and is assigned the next player ID.
 type Host struct {
-	game      *engine.Game
-	hostName  string
-	guestName string
-	port      int
-	conn      net.Conn
-	mu        sync.Mutex
+	game     *engine.Game
+	hostName string
+	guests   []*clientConn
+	coach    bool // every player agreed to hints
+
+	// stateCh feeds guest moves to the host TUI while it waits.
+	stateCh chan *engine.GameState
+	// noticeCh feeds chat and announcements to the host TUI.
+	noticeCh chan cli.ChatEntry
+
+	// sess holds the seats of guests whose connection dropped.
+	sess *sessions
+	// clock limits guest turns; nil when turns are untimed.
+	clock *turnClock
+	// dealer rolls the dice when they are verifiable; nil otherwise.
+	dealer *fairDealer
+
+	mu           sync.Mutex
+	disconnected map[string]bool // player IDs that did not reconnect in time
 }
 
-// HostGameClient wraps a LocalClient and broadcasts state updates to the guest
-// after each action. It implements engine.GameClient.
+// newHost creates a host for game, whose dice dealer rolls if they are
+// verifiable, and starts reading from each guest. Hints are on if the host
+// and every guest asked for them.
+func newHost(game *engine.Game, hostName string, guests []*clientConn, dealer *fairDealer, opts ...Option) *Host {
+	o := newOptions(opts)
+	h := &Host{
+		game:         game,
+		hostName:     hostName,
+		guests:       guests,
+		coach:        o.coach != nil,
+		stateCh:      make(chan *engine.GameState, 64),
+		noticeCh:     make(chan cli.ChatEntry, 16),
+		sess:         newSessions(o.grace),
+		clock:        newTurnClock(o.turnLimit, o.autoPlay),
+		dealer:       dealer,
+		disconnected: make(map[string]bool),
+	}
+	for _, cc := range guests {
+		h.coach = h.coach && cc.coach
+	}
+	for _, cc := range guests {
+		h.sess.add(cc)
+		go h.readGuest(cc, cc.conn)
+	}
+	return h
+}
+
+// hostPlayerID is the player ID of the host's own seat.
+const hostPlayerID = "player-0"
+
+// HostGameClient wraps a LocalClient and broadcasts state updates to the
+// guests after each action. It implements engine.GameClient.
 type HostGameClient struct {
 	local *engine.LocalClient
 	host  *Host
@@ -32,7 +80,7 @@ func (h *HostGameClient) Roll() (*engine.GameState, error) {
 	if err != nil {
 		return nil, err
 	}
-	if err := h.host.sendStateUpdate(*gs); err != nil {
+	if err := h.host.sendStateUpdate(*gs, nil); err != nil {
 		return nil, fmt.Errorf("send state update: %w", err)
 	}
 	return gs, nil
@@ -43,7 +91,7 @@ func (h *HostGameClient) Hold(indices []int) (*engine.GameState, error) {
 	if err != nil {
 		return nil, err
 	}
-	if err := h.host.sendStateUpdate(*gs); err != nil {
+	if err := h.host.sendStateUpdate(*gs, indices); err != nil {
 		return nil, fmt.Errorf("send state update: %w", err)
 	}
 	return gs, nil
@@ -54,20 +102,19 @@ func (h *HostGameClient) Score(category engine.Category) (*engine.GameState, err
 	if err != nil {
 		return nil, err
 	}
-	if err := h.host.sendStateUpdate(*gs); err != nil {
+	if err := h.host.sendStateUpdate(*gs, nil); err != nil {
 		return nil, fmt.Errorf("send state update: %w", err)
 	}
 	if gs.Phase == engine.PhaseFinished {
 		_ = h.host.sendGameOver(*gs)
 		return gs, nil
 	}
-	// If it's now the guest's turn, handle their actions
-	if gs.CurrentPlayer == "player-1" {
-		finalState, err := h.host.handleGuestTurn()
+	// Let every guest play until it's the host's turn again.
+	for gs.CurrentPlayer != hostPlayerID && gs.Phase != engine.PhaseFinished {
+		gs, err = h.host.handleGuestTurn()
 		if err != nil {
 			return nil, fmt.Errorf("handle guest turn: %w", err)
 		}
-		return finalState, nil
 	}
 	return gs, nil
 }
@@ -76,65 +123,181 @@ func (h *HostGameClient) GetState() (*engine.GameState, error) {
 	return h.local.GetState()
 }
 
-func (h *Host) sendStateUpdate(gs engine.GameState) error {
-	h.mu.Lock()
-	defer h.mu.Unlock()
-	return WriteMessage(h.conn, NewStateUpdateMsg(gs))
-}
-
-func (h *Host) sendTurnStart(gs engine.GameState) error {
-	h.mu.Lock()
-	defer h.mu.Unlock()
-	return WriteMessage(h.conn, NewTurnStartMsg(gs))
+// sendStateUpdate, sendGameOver and sendGameStart broadcast to every guest.
+// Write failures are not fatal: the guest's reader notices the lost
+// connection and announces it. held lists the dice kept when gs follows a
+// hold, so guests can check the reroll.
+func (h *Host) sendStateUpdate(gs engine.GameState, held []int) error {
+	h.sess.setState(gs)
+	broadcast(h.guests, newStateUpdateMsgWithHeld(gs, held))
+	return nil
 }
 
 func (h *Host) sendGameOver(gs engine.GameState) error {
-	h.mu.Lock()
-	defer h.mu.Unlock()
-	return WriteMessage(h.conn, NewGameOverMsg(gs))
+	h.sess.end()
+	broadcast(h.guests, newMessage(MsgGameOver, StatePayload{State: gs, FairDice: h.dealer.payload(true)}))
+	return nil
 }
 
 func (h *Host) sendGameStart(gs engine.GameState) error {
-	h.mu.Lock()
-	defer h.mu.Unlock()
-	return WriteMessage(h.conn, NewGameStartMsg(gs))
+	h.sess.setState(gs)
+	broadcast(h.guests, newMessage(MsgGameStart, StatePayload{State: gs, Coach: h.coach, FairDice: h.dealer.payload(false)}))
+	return nil
 }
 
-func (h *Host) sendError(errMsg string) error {
-	h.mu.Lock()
-	defer h.mu.Unlock()
-	return WriteMessage(h.conn, NewErrorMsg(errMsg))
+// guestFor returns the connection of the guest playing as playerID.
+func (h *Host) guestFor(playerID string) *clientConn {
+	for _, cc := range h.guests {
+		if cc.playerID == playerID {
+			return cc
+		}
+	}
+	return nil
+}
+
+// readGuest forwards a guest's actions to its action channel and relays
+// chat until conn is lost. The guest's seat is then held for a resume.
+func (h *Host) readGuest(cc *clientConn, conn net.Conn) {
+	for {
+		msg, err := ReadMessage(conn)
+		if err != nil {
+			if h.sess.drop(cc, conn, func() {
+				h.mu.Lock()
+				h.disconnected[cc.playerID] = true
+				h.mu.Unlock()
+				h.announce(fmt.Sprintf("%s disconnected; an AI plays their remaining turns", cc.name), cc)
+			}) {
+				h.announce(fmt.Sprintf("%s lost connection; holding their seat for %s", cc.name, h.sess.grace), cc)
+			}
+			return
+		}
+
+		switch msg.Type {
+		case MsgAction:
+			ap, err := DecodeAction(msg)
+			if err != nil {
+				_ = writeToClient(cc, NewErrorMsg(fmt.Sprintf("invalid action: %v", err)))
+				continue
+			}
+			cc.actionCh <- ap
+
+		case MsgChat:
+			broadcast(h.guests, msg)
+			if cp, err := DecodeChat(msg); err == nil {
+				h.notify(cli.ChatEntry{Name: cp.Name, Text: cp.Text})
+			}
+
+		case MsgSeed:
+			sp, err := DecodeSeed(msg)
+			if err == nil && h.dealer != nil {
+				err = h.dealer.reveal(slices.Index(h.guests, cc)+1, sp.Seed)
+			} else if err == nil {
+				err = fmt.Errorf("this game doesn't use verifiable dice")
+			}
+			if err != nil {
+				_ = writeToClient(cc, NewErrorMsg(fmt.Sprintf("invalid seed: %v", err)))
+			}
+
+		default:
+			_ = writeToClient(cc, NewErrorMsg(fmt.Sprintf("unexpected message type: %s", msg.Type)))
+		}
+	}
 }
 
-// handleGuestTurn reads actions from the guest over TCP and applies them
-// to the game engine. It loops until the guest scores (ending their turn)
-// or the game finishes. Returns the final state after the guest's turn.
+// resumed starts reading from a guest that reclaimed its seat on conn.
+func (h *Host) resumed(cc *clientConn, conn net.Conn) {
+	h.announce(fmt.Sprintf("%s reconnected", cc.name), cc)
+	go h.readGuest(cc, conn)
+}
+
+// announce tells the host and every guest except exclude about an event.
+func (h *Host) announce(text string, exclude *clientConn) {
+	h.notify(cli.ChatEntry{Name: "*", Text: text})
+	msg := NewErrorMsg(text)
+	for _, cc := range h.guests {
+		if cc != exclude {
+			_ = writeToClient(cc, msg)
+		}
+	}
+}
+
+func (h *Host) notify(entry cli.ChatEntry) {
+	select {
+	case h.noticeCh <- entry:
+	default:
+	}
+}
+
+// showGuestMove shows a guest's move in the host TUI.
+func (h *Host) showGuestMove(gs engine.GameState) {
+	select {
+	case h.stateCh <- &gs:
+	default:
+	}
+}
+
+// handleGuestTurn plays the current player's turn, who must be a guest. It
+// applies the guest's actions until they score or the game finishes, and
+// returns the state after the turn. A guest whose connection dropped is
+// waited for until their grace period ends; after that the engine's AI plays
+// their turns. With a turn clock, a guest who runs out of time has the rest
+// of their turn auto-played.
 func (h *Host) handleGuestTurn() (*engine.GameState, error) {
 	gs := h.game.GetState()
-	if err := h.sendTurnStart(gs); err != nil {
-		return nil, fmt.Errorf("send turn_start: %w", err)
+	cc := h.guestFor(gs.CurrentPlayer)
+	if cc == nil {
+		return nil, fmt.Errorf("no guest plays as %s", gs.CurrentPlayer)
+	}
+
+	h.mu.Lock()
+	gone := h.disconnected[cc.playerID]
+	h.mu.Unlock()
+	if gone {
+		return h.playAITurn(cc)
+	}
+
+	limit := h.clock.limitFor(cc.playerID)
+	var deadline time.Time
+	if limit > 0 {
+		deadline = time.Now().Add(limit)
 	}
+	h.sess.setDeadline(deadline)
+	timeout, stop := turnTimer(limit)
+	defer stop()
+
+	// If the connection dropped, a resume re-sends turn_start.
+	cc.dropStaleActions()
+	_ = writeToClient(cc, newTurnStartMsgWithClock(gs, limit))
 
 	for {
-		msg, err := ReadMessage(h.conn)
-		if err != nil {
-			return nil, fmt.Errorf("read guest action: %w", err)
+		var ap *ActionPayload
+		var ok bool
+		select {
+		case ap, ok = <-cc.actionCh:
+		case <-timeout:
+			text := fmt.Sprintf("%s ran out of time; their turn was auto-played", cc.name)
+			if h.clock.timedOut(cc.playerID) {
+				text = fmt.Sprintf("%s is AFK; their turns are auto-played", cc.name)
+			}
+			h.announce(text, nil)
+			return h.playAITurn(cc)
 		}
-		if msg.Type != MsgAction {
-			return nil, fmt.Errorf("expected action message, got %s", msg.Type)
+		if !ok {
+			// Seat lost mid-turn: the AI finishes it.
+			return h.playAITurn(cc)
 		}
-
-		ap, err := DecodeAction(msg)
-		if err != nil {
-			return nil, fmt.Errorf("decode action: %w", err)
+		if h.clock.acted(cc.playerID) {
+			h.announce(fmt.Sprintf("%s is back", cc.name), nil)
 		}
 
 		var actionErr error
+		var held []int
 		switch ap.Action {
 		case ActionRoll:
 			actionErr = h.game.Roll()
 		case ActionHold:
 			actionErr = h.game.Hold(ap.Indices)
+			held = ap.Indices
 		case ActionScore:
 			actionErr = h.game.Score(engine.Category(ap.Category))
 		default:
@@ -142,16 +305,13 @@ func (h *Host) handleGuestTurn() (*engine.GameState, error) {
 		}
 
 		if actionErr != nil {
-			if err := h.sendError(actionErr.Error()); err != nil {
-				return nil, fmt.Errorf("send error: %w", err)
-			}
+			_ = writeToClient(cc, NewErrorMsg(actionErr.Error()))
 			continue
 		}
 
 		state := h.game.GetState()
-		if err := h.sendStateUpdate(state); err != nil {
-			return nil, fmt.Errorf("send state_update: %w", err)
-		}
+		_ = h.sendStateUpdate(state, held)
+		h.showGuestMove(state)
 
 		if state.Phase == engine.PhaseFinished {
 			_ = h.sendGameOver(state)
@@ -165,56 +325,146 @@ func (h *Host) handleGuestTurn() (*engine.GameState, error) {
 	}
 }
 
+// playAITurn finishes the current turn of a guest who left or ran out of
+// time, with the turn clock's strategy if there is one.
+func (h *Host) playAITurn(cc *clientConn) (*engine.GameState, error) {
+	if h.clock != nil {
+		if err := h.clock.autoPlay(h.game, cc.playerID); err != nil {
+			return nil, err
+		}
+	} else if _, err := engine.NewAIPlayer(h.game, cc.playerID).PlayTurn(); err != nil {
+		return nil, fmt.Errorf("AI turn for %s: %w", cc.playerID, err)
+	}
+	state := h.game.GetState()
+	_ = h.sendStateUpdate(state, nil)
+	h.showGuestMove(state)
+	if state.Phase == engine.PhaseFinished {
+		_ = h.sendGameOver(state)
+	}
+	return &state, nil
+}
+
+// handshakeGuest reads a guest's handshake and replies with the host name,
+// the guest's assigned player ID, its session token and the negotiated
+// capabilities. A guest from an incompatible version or with the wrong room
+// password is told so.
+func handshakeGuest(conn net.Conn, hostName, playerID string, o *options) (*clientConn, error) {
+	hs, err := readHandshake(conn, o.password)
+	if err != nil {
+		return nil, err
+	}
+	if o.fairDice {
+		if err := checkCommitment(hs); err != nil {
+			_ = WriteMessage(conn, NewErrorMsg(err.Error()))
+			return nil, err
+		}
+	}
+
+	cc := newClientConn(conn, hs.Name, playerID)
+	cc.commitment = hs.Commitment
+	f := negotiate(o.capabilities(), hs)
+	cc.coach = hs.Coach && f.Has(CapCoach)
+	resp := handshakeReply(HandshakePayload{Name: hostName, PlayerID: playerID, Token: cc.token}, f)
+	if err := WriteMessage(conn, resp); err != nil {
+		return nil, fmt.Errorf("send handshake: %w", err)
+	}
+	return cc, nil
+}
+
 // RunHost starts a P2P game as host. It listens on the given port, accepts
-// one guest connection, performs a handshake, then runs the game.
-func RunHost(port int, name string) error {
+// a connection from each guest (one by default, see WithPlayers), performs
+// the handshakes, then runs the game with the host playing from the TUI.
+// Guests that fail the handshake, e.g. with the wrong password, are turned
+// away. With WithWebSocketPort, guests may also join over WebSocket. The
+// port stays open during the game for guests resuming a dropped connection.
+func RunHost(port int, name string, opts ...Option) error {
+	o := newOptions(opts)
+
+	var ln net.Listener
 	ln, err := net.Listen("tcp", fmt.Sprintf(":%d", port))
 	if err != nil {
 		return fmt.Errorf("listen: %w", err)
 	}
+	if o.wsPort > 0 {
+		wsLn, err := net.Listen("tcp", fmt.Sprintf(":%d", o.wsPort))
+		if err != nil {
+			ln.Close()
+			return fmt.Errorf("listen: %w", err)
+		}
+		fmt.Printf("Accepting WebSocket guests on port %d\n", o.wsPort)
+		ln = MultiListener(ln, ListenWebSocket(wsLn))
+	}
 	defer ln.Close()
+	ln = o.listen(ln)
 
-	fmt.Printf("Waiting for guest on port %d...\n", port)
-
-	conn, err := ln.Accept()
-	if err != nil {
-		return fmt.Errorf("accept: %w", err)
+	numGuests := o.players - 1
+	guests := make([]*clientConn, 0, numGuests)
+	defer func() {
+		for _, cc := range guests {
+			cc.conn.Close()
+		}
+	}()
+	for len(guests) < numGuests {
+		fmt.Printf("Waiting for guests on port %d (%d/%d)...\n", port, len(guests), numGuests)
+		conn, err := ln.Accept()
+		if err != nil {
+			return fmt.Errorf("accept: %w", err)
+		}
+		playerID := fmt.Sprintf("player-%d", len(guests)+1)
+		cc, err := handshakeGuest(conn, name, playerID, &o)
+		if err != nil {
+			fmt.Printf("Rejected %s: %v\n", conn.RemoteAddr(), err)
+			conn.Close()
+			continue
+		}
+		fmt.Printf("%s joined as %s\n", cc.name, playerID)
+		guests = append(guests, cc)
 	}
-	defer conn.Close()
 
-	return runHostWithConn(conn, name, nil)
+	return runHostWithGuests(ln, guests, name, nil, opts...)
 }
 
-// runHostWithConn runs the host game logic on an already-established connection.
+// runHostWithConn runs the host game logic with a single guest on an
+// already-established connection, after its handshake.
 // rngSrc can be nil for production (uses time-based seed).
-func runHostWithConn(conn net.Conn, hostName string, rngSrc rand.Source) error {
-	// Handshake: receive guest name, send host name
-	msg, err := ReadMessage(conn)
+func runHostWithConn(conn net.Conn, hostName string, rngSrc rand.Source, opts ...Option) error {
+	o := newOptions(opts)
+	cc, err := handshakeGuest(conn, hostName, "player-1", &o)
 	if err != nil {
-		return fmt.Errorf("read handshake: %w", err)
+		return fmt.Errorf("guest 1: %w", err)
 	}
-	if msg.Type != MsgHandshake {
-		return fmt.Errorf("expected handshake, got %s", msg.Type)
-	}
-	hs, err := DecodeHandshake(msg)
-	if err != nil {
-		return fmt.Errorf("decode handshake: %w", err)
+	fmt.Printf("%s joined as %s\n", cc.name, cc.playerID)
+	return runHostWithGuests(nil, []*clientConn{cc}, hostName, rngSrc, opts...)
+}
+
+// runHostWithGuests runs the host game logic with guests that have
+// completed the handshake. If ln is not nil, guests whose connection drops
+// can resume their seat through it.
+func runHostWithGuests(ln net.Listener, guests []*clientConn, hostName string, rngSrc rand.Source, opts ...Option) error {
+	o := newOptions(opts)
+
+	names := []string{hostName}
+	for _, cc := range guests {
+		names = append(names, cc.name)
 	}
-	guestName := hs.Name
 
-	if err := WriteMessage(conn, NewHandshakeMsg(hostName)); err != nil {
-		return fmt.Errorf("send handshake: %w", err)
+	var dealer *fairDealer
+	if o.fairDice {
+		dealer = newFairDealer(len(names))
+		dealer.pick(0)
+		for i, cc := range guests {
+			dealer.commit(i+1, cc.commitment)
+		}
+		rngSrc = dealer
 	}
 
 	// Create game
-	game := engine.NewGame([]string{hostName, guestName}, rngSrc)
-	localClient := engine.NewLocalClient(game, "player-0", nil)
+	game := engine.NewGameWithRules(names, rngSrc, o.rules)
+	localClient := engine.NewLocalClient(game, hostPlayerID, nil)
 
-	host := &Host{
-		game:      game,
-		hostName:  hostName,
-		guestName: guestName,
-		conn:      conn,
+	host := newHost(game, hostName, guests, dealer, opts...)
+	if ln != nil {
+		go acceptResumes(ln, host.sess, host.resumed, nil)
 	}
 
 	hostClient := &HostGameClient{
@@ -227,7 +477,26 @@ func runHostWithConn(conn net.Conn, hostName string, rngSrc rand.Source) error {
 	if err := host.sendGameStart(gs); err != nil {
 		return fmt.Errorf("send game_start: %w", err)
 	}
+	if dealer != nil {
+		if err := dealer.wait(seedTimeout); err != nil {
+			host.announce(err.Error(), nil)
+			return err
+		}
+	}
 
 	// Run TUI for host player
-	return cli.RunGame(hostClient, hostName)
+	guiOpts := []cli.GameOption{
+		cli.WithChatChannel(host.noticeCh),
+		cli.WithStateUpdateChannel(host.stateCh),
+	}
+	if host.coach {
+		guiOpts = append(guiOpts, cli.WithCoach(o.coach))
+	} else if o.coach != nil {
+		fmt.Println("Hints disabled: not every player enabled --coach")
+	}
+	if err := cli.RunGame(hostClient, hostName, guiOpts...); err != nil {
+		return err
+	}
+	o.recordGame(stats.ModeP2P, game.GetState(), hostPlayerID, nil)
+	return nil
 }
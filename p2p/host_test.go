@@ -1,9 +1,13 @@
 package p2p
 
 import (
+	"errors"
+	"fmt"
 	"math/rand"
 	"net"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/edge2992/yatzcli/engine"
 )
@@ -114,18 +118,66 @@ func TestHostHandshake(t *testing.T) {
 	guestConn.Close()
 }
 
+func TestHostHandshake_WrongPassword(t *testing.T) {
+	hostConn, guestConn := net.Pipe()
+	defer hostConn.Close()
+	defer guestConn.Close()
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- runHostWithConn(hostConn, "Host", rand.NewSource(1), WithPassword("s3cret"))
+	}()
+
+	hello := newHello(HandshakePayload{Name: "Guest", Password: "guess"})
+	if err := WriteMessage(guestConn, hello); err != nil {
+		t.Fatalf("send handshake: %v", err)
+	}
+	msg, err := ReadMessage(guestConn)
+	if err != nil {
+		t.Fatalf("read reply: %v", err)
+	}
+	if msg.Type != MsgError {
+		t.Fatalf("expected error, got %s", msg.Type)
+	}
+	if err := <-errCh; !errors.Is(err, errWrongPassword) {
+		t.Errorf("runHostWithConn = %v, want errWrongPassword", err)
+	}
+}
+
+func TestHostHandshake_IncompatibleVersion(t *testing.T) {
+	hostConn, guestConn := net.Pipe()
+	defer hostConn.Close()
+	defer guestConn.Close()
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- runHostWithConn(hostConn, "Host", rand.NewSource(1))
+	}()
+
+	// A guest from before versioning sends no version.
+	if err := WriteMessage(guestConn, newMessage(MsgHandshake, HandshakePayload{Name: "Old"})); err != nil {
+		t.Fatalf("send handshake: %v", err)
+	}
+	msg, err := ReadMessage(guestConn)
+	if err != nil {
+		t.Fatalf("read reply: %v", err)
+	}
+	ep, _ := DecodeError(msg)
+	if msg.Type != MsgError || !strings.Contains(ep.Message, "upgrade") {
+		t.Errorf("reply = %s %+v, want an upgrade error", msg.Type, ep)
+	}
+	if err := <-errCh; !errors.Is(err, ErrIncompatibleVersion) {
+		t.Errorf("runHostWithConn = %v, want ErrIncompatibleVersion", err)
+	}
+}
+
 func TestHandleGuestTurn(t *testing.T) {
 	hostConn, guestConn := net.Pipe()
 	defer hostConn.Close()
 	defer guestConn.Close()
 
 	game := engine.NewGame([]string{"Host", "Guest"}, rand.NewSource(42))
-	host := &Host{
-		game:      game,
-		hostName:  "Host",
-		guestName: "Guest",
-		conn:      hostConn,
-	}
+	host := newHost(game, "Host", []*clientConn{newClientConn(hostConn, "Guest", "player-1")}, nil)
 
 	// Simulate: advance to guest's turn by making host score
 	if err := game.Roll(); err != nil {
@@ -219,12 +271,7 @@ func TestHandleGuestTurnInvalidAction(t *testing.T) {
 	defer guestConn.Close()
 
 	game := engine.NewGame([]string{"Host", "Guest"}, rand.NewSource(42))
-	host := &Host{
-		game:      game,
-		hostName:  "Host",
-		guestName: "Guest",
-		conn:      hostConn,
-	}
+	host := newHost(game, "Host", []*clientConn{newClientConn(hostConn, "Guest", "player-1")}, nil)
 
 	// Advance to guest's turn
 	if err := game.Roll(); err != nil {
@@ -301,3 +348,218 @@ func TestHandleGuestTurnInvalidAction(t *testing.T) {
 		t.Fatalf("handleGuestTurn error: %v", err)
 	}
 }
+
+// playScriptedGuest answers every turn_start on conn by rolling once and
+// scoring the first available category, until game_over. It returns the
+// error announcements it received.
+func playScriptedGuest(conn net.Conn, playerID string) ([]string, error) {
+	var notices []string
+	myTurn := false
+	for {
+		msg, err := ReadMessage(conn)
+		if err != nil {
+			return notices, err
+		}
+		switch msg.Type {
+		case MsgTurnStart:
+			myTurn = true
+			if err := WriteMessage(conn, NewActionMsg(ActionPayload{Action: ActionRoll})); err != nil {
+				return notices, err
+			}
+		case MsgStateUpdate:
+			sp, _ := DecodeState(msg)
+			if myTurn && sp.State.CurrentPlayer == playerID && sp.State.RollCount > 0 {
+				myTurn = false
+				score := ActionPayload{Action: ActionScore, Category: string(sp.State.AvailableCategories[0])}
+				if err := WriteMessage(conn, NewActionMsg(score)); err != nil {
+					return notices, err
+				}
+			}
+		case MsgError:
+			ep, _ := DecodeError(msg)
+			notices = append(notices, ep.Message)
+		case MsgGameOver:
+			return notices, nil
+		}
+	}
+}
+
+// playHostTurns plays the host seat through hc until the game finishes.
+func playHostTurns(hc *HostGameClient) (*engine.GameState, error) {
+	for {
+		gs, err := hc.Roll()
+		if err != nil {
+			return nil, err
+		}
+		gs, err = hc.Score(gs.AvailableCategories[0])
+		if err != nil {
+			return nil, err
+		}
+		if gs.Phase == engine.PhaseFinished {
+			return gs, nil
+		}
+	}
+}
+
+func TestHost_MultipleGuests(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping full game in short mode")
+	}
+
+	game := engine.NewGame([]string{"Host", "Bob", "Carol"}, rand.NewSource(7))
+	var guests []*clientConn
+	var guestSides []net.Conn
+	for i, name := range []string{"Bob", "Carol"} {
+		hostSide, guestSide := net.Pipe()
+		defer hostSide.Close()
+		defer guestSide.Close()
+		guests = append(guests, newClientConn(hostSide, name, fmt.Sprintf("player-%d", i+1)))
+		guestSides = append(guestSides, guestSide)
+	}
+	host := newHost(game, "Host", guests, nil)
+	hc := &HostGameClient{local: engine.NewLocalClient(game, hostPlayerID, nil), host: host}
+
+	errCh := make(chan error, len(guestSides))
+	for i, conn := range guestSides {
+		go func(conn net.Conn, playerID string) {
+			_, err := playScriptedGuest(conn, playerID)
+			errCh <- err
+		}(conn, fmt.Sprintf("player-%d", i+1))
+	}
+
+	final, err := playHostTurns(hc)
+	if err != nil {
+		t.Fatalf("host play: %v", err)
+	}
+	for range guestSides {
+		if err := <-errCh; err != nil {
+			t.Fatalf("guest: %v", err)
+		}
+	}
+
+	if len(final.Players) != 3 {
+		t.Fatalf("expected 3 players, got %d", len(final.Players))
+	}
+	for _, p := range final.Players {
+		if len(p.Scorecard.AvailableCategories()) > 0 {
+			t.Errorf("%s did not fill every category", p.Name)
+		}
+	}
+}
+
+func TestHost_GuestDisconnectAnnounced(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping full game in short mode")
+	}
+
+	game := engine.NewGame([]string{"Host", "Bob", "Carol"}, rand.NewSource(7))
+	bobHost, bobGuest := net.Pipe()
+	carolHost, carolGuest := net.Pipe()
+	defer bobHost.Close()
+	defer bobGuest.Close()
+	defer carolHost.Close()
+	host := newHost(game, "Host", []*clientConn{
+		newClientConn(bobHost, "Bob", "player-1"),
+		newClientConn(carolHost, "Carol", "player-2"),
+	}, nil, WithGracePeriod(50*time.Millisecond))
+	hc := &HostGameClient{local: engine.NewLocalClient(game, hostPlayerID, nil), host: host}
+
+	type guestResult struct {
+		notices []string
+		err     error
+	}
+	bobDone := make(chan guestResult, 1)
+	go func() {
+		notices, err := playScriptedGuest(bobGuest, "player-1")
+		bobDone <- guestResult{notices, err}
+	}()
+
+	// Carol leaves before the game starts and does not come back within the
+	// grace period; the AI plays her turns.
+	carolGuest.Close()
+
+	final, err := playHostTurns(hc)
+	if err != nil {
+		t.Fatalf("host play: %v", err)
+	}
+	result := <-bobDone
+	if result.err != nil {
+		t.Fatalf("guest: %v", result.err)
+	}
+
+	if len(result.notices) != 2 ||
+		!strings.Contains(result.notices[0], "Carol lost connection") ||
+		!strings.Contains(result.notices[1], "Carol disconnected") {
+		t.Errorf("expected Bob to be told Carol disconnected, got %q", result.notices)
+	}
+	for _, want := range []string{"Carol lost connection", "Carol disconnected"} {
+		select {
+		case entry := <-host.noticeCh:
+			if !strings.Contains(entry.Text, want) {
+				t.Errorf("expected host notice %q, got %q", want, entry.Text)
+			}
+		default:
+			t.Errorf("expected host notice %q", want)
+		}
+	}
+	if len(final.Players[2].Scorecard.AvailableCategories()) > 0 {
+		t.Error("expected the AI to finish Carol's scorecard")
+	}
+}
+
+func TestHost_GuestResumesSeat(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping full game in short mode")
+	}
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	defer ln.Close()
+
+	game := engine.NewGame([]string{"Host", "Bob"}, rand.NewSource(3))
+	hostSide, guestSide := net.Pipe()
+	defer hostSide.Close()
+	bob := newClientConn(hostSide, "Bob", "player-1")
+	host := newHost(game, "Host", []*clientConn{bob}, nil, WithGracePeriod(5*time.Second))
+	go acceptResumes(ln, host.sess, host.resumed, nil)
+	hc := &HostGameClient{local: engine.NewLocalClient(game, hostPlayerID, nil), host: host}
+
+	// Bob drops before the game starts and comes back with his token.
+	guestSide.Close()
+	conn, err := net.Dial("tcp", ln.Addr().String())
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	defer conn.Close()
+	if err := WriteMessage(conn, NewResumeMsg(bob.token)); err != nil {
+		t.Fatalf("send resume: %v", err)
+	}
+	rp, err := DecodeResume(readExpectType(t, conn, MsgResume))
+	if err != nil || rp.PlayerID != "player-1" {
+		t.Fatalf("unexpected resume reply: %+v, %v", rp, err)
+	}
+
+	guestDone := make(chan error, 1)
+	go func() {
+		_, err := playScriptedGuest(conn, "player-1")
+		guestDone <- err
+	}()
+
+	final, err := playHostTurns(hc)
+	if err != nil {
+		t.Fatalf("host play: %v", err)
+	}
+	if err := <-guestDone; err != nil {
+		t.Fatalf("guest: %v", err)
+	}
+	if len(final.Players[1].Scorecard.AvailableCategories()) > 0 {
+		t.Error("expected Bob to finish his own scorecard")
+	}
+	host.mu.Lock()
+	defer host.mu.Unlock()
+	if host.disconnected["player-1"] {
+		t.Error("Bob's seat should not have been given to the AI")
+	}
+}
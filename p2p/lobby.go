@@ -0,0 +1,399 @@
+package p2p
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"math/rand"
+	"net"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/edge2992/yatzcli/engine"
+)
+
+// maxTableSeats caps the number of players at a lobby table.
+const maxTableSeats = 8
+
+var errNotAtTable = errors.New("not at a table")
+
+// Lobby is a long-running server hosting many games at once. Clients
+// connect, then list, create and join tables. Once a table is full any of
+// its players may start it, and its game runs in its own goroutine until it
+// ends and the players are disconnected. Options apply to every table;
+// WithRules sets the rules of tables created without naming any.
+type Lobby struct {
+	o         options
+	newSource func() rand.Source
+
+	mu     sync.Mutex
+	tables map[string]*table
+	seated map[*clientConn]*table
+	nextID int
+}
+
+// table is a lobby table; game is set once it starts.
+type table struct {
+	id      string
+	seq     int
+	seats   int
+	rules   engine.RuleSet
+	players []*clientConn
+	game    *serverGame
+}
+
+func (t *table) info() TableInfo {
+	names := make([]string, len(t.players))
+	for i, cc := range t.players {
+		names[i] = cc.name
+	}
+	return TableInfo{ID: t.id, Seats: t.seats, Rules: t.rules.Name(), Players: names}
+}
+
+// NewLobby returns a lobby whose games are played with opts.
+func NewLobby(opts ...Option) *Lobby {
+	return &Lobby{
+		o: newOptions(opts),
+		newSource: func() rand.Source {
+			return rand.NewSource(time.Now().UnixNano())
+		},
+		tables: make(map[string]*table),
+		seated: make(map[*clientConn]*table),
+	}
+}
+
+// Serve accepts connections on ln until it is closed.
+func (l *Lobby) Serve(ln net.Listener) error {
+	ln = l.o.listen(ln)
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			return err
+		}
+		go l.handleConn(conn)
+	}
+}
+
+func (l *Lobby) handleConn(conn net.Conn) {
+	conn.SetDeadline(time.Now().Add(30 * time.Second))
+	msg, err := ReadMessage(conn)
+	if err != nil {
+		conn.Close()
+		return
+	}
+	conn.SetDeadline(time.Time{})
+
+	switch msg.Type {
+	case MsgResume:
+		l.resume(conn, msg)
+		return
+	case MsgHandshake:
+	default:
+		_ = WriteMessage(conn, NewErrorMsg(fmt.Sprintf("expected handshake, got %s", msg.Type)))
+		conn.Close()
+		return
+	}
+	hs, err := DecodeHandshake(msg)
+	if err != nil {
+		conn.Close()
+		return
+	}
+	if err := checkHandshake(hs, l.o.password); err != nil {
+		log.Printf("[lobby] Rejected %s: %v", conn.RemoteAddr(), err)
+		_ = WriteMessage(conn, NewErrorMsg(err.Error()))
+		conn.Close()
+		return
+	}
+	if hs.Role == RoleSpectator {
+		_ = WriteMessage(conn, NewErrorMsg("spectating is not supported on a lobby server"))
+		conn.Close()
+		return
+	}
+
+	rated, err := l.o.signIn(hs)
+	if err != nil {
+		log.Printf("[lobby] Rejected %s: %v", conn.RemoteAddr(), err)
+		_ = WriteMessage(conn, NewErrorMsg(err.Error()))
+		conn.Close()
+		return
+	}
+
+	cc := newClientConn(conn, hs.Name, "")
+	cc.rated = rated
+	f := negotiate(l.o.capabilities(CapLobby), hs)
+	cc.coach = hs.Coach && f.Has(CapCoach)
+	if err := WriteMessage(conn, handshakeReply(HandshakePayload{Name: "lobby", Token: cc.token}, f)); err != nil {
+		conn.Close()
+		return
+	}
+	log.Printf("[lobby] %s connected", cc.name)
+
+	for {
+		msg, err := ReadMessage(conn)
+		if g, idx := l.gameOf(cc); g != nil {
+			// The table started while we were reading; its game takes
+			// over the connection.
+			if err != nil {
+				g.dropped(cc, conn, idx)
+				return
+			}
+			g.handle(cc, msg)
+			g.readLoop(cc, conn, idx)
+			return
+		}
+		if err != nil {
+			_ = l.leave(cc)
+			conn.Close()
+			log.Printf("[lobby] %s left", cc.name)
+			return
+		}
+		if err := l.handle(cc, msg); err != nil {
+			_ = writeToClient(cc, NewErrorMsg(err.Error()))
+		}
+	}
+}
+
+// handle processes a lobby message from a player not yet in a game.
+func (l *Lobby) handle(cc *clientConn, msg *Message) error {
+	switch msg.Type {
+	case MsgListTables:
+		return writeToClient(cc, newTablesMsg(l.openTables()))
+	case MsgCreateTable:
+		ti, err := DecodeTable(msg)
+		if err != nil {
+			return err
+		}
+		return l.create(cc, ti.Seats, ti.Rules)
+	case MsgJoinTable:
+		ti, err := DecodeTable(msg)
+		if err != nil {
+			return err
+		}
+		return l.join(cc, ti.ID)
+	case MsgLeaveTable:
+		return l.leave(cc)
+	case MsgStartTable:
+		return l.start(cc)
+	case MsgLeaderboard:
+		lp, err := DecodeLeaderboard(msg)
+		if err != nil {
+			return err
+		}
+		entries, err := l.leaderboard(lp.Limit)
+		if err != nil {
+			return err
+		}
+		return writeToClient(cc, newLeaderboardReplyMsg(entries))
+	default:
+		return fmt.Errorf("unexpected message type: %s", msg.Type)
+	}
+}
+
+// leaderboard returns up to limit of the best-rated accounts.
+func (l *Lobby) leaderboard(limit int) ([]LeaderboardEntry, error) {
+	if l.o.ratings == nil {
+		return nil, errors.New("this server doesn't keep ratings")
+	}
+	accts, err := l.o.ratings.Leaderboard(limit)
+	if err != nil {
+		log.Printf("[lobby] Leaderboard: %v", err)
+		return nil, errors.New("leaderboard unavailable")
+	}
+	entries := make([]LeaderboardEntry, len(accts))
+	for i, a := range accts {
+		entries[i] = LeaderboardEntry{Name: a.Name, Rating: a.Rating.Rating, Deviation: a.Rating.Deviation, Games: a.Games, Wins: a.Wins}
+	}
+	return entries, nil
+}
+
+// openTables lists the tables that haven't started, oldest first.
+func (l *Lobby) openTables() []TableInfo {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	var open []*table
+	for _, t := range l.tables {
+		if t.game == nil {
+			open = append(open, t)
+		}
+	}
+	sort.Slice(open, func(i, j int) bool { return open[i].seq < open[j].seq })
+	infos := make([]TableInfo, len(open))
+	for i, t := range open {
+		infos[i] = t.info()
+	}
+	return infos
+}
+
+func (l *Lobby) create(cc *clientConn, seats int, rulesName string) error {
+	if seats < 2 || seats > maxTableSeats {
+		return fmt.Errorf("a table needs 2 to %d seats, got %d", maxTableSeats, seats)
+	}
+	rules := l.o.rules
+	if rulesName != "" {
+		var err error
+		if rules, err = engine.RuleSetByName(rulesName); err != nil {
+			return err
+		}
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if t := l.seated[cc]; t != nil {
+		return fmt.Errorf("already at table %s", t.id)
+	}
+	l.nextID++
+	t := &table{
+		id:      fmt.Sprintf("t%d", l.nextID),
+		seq:     l.nextID,
+		seats:   seats,
+		rules:   rules,
+		players: []*clientConn{cc},
+	}
+	l.tables[t.id] = t
+	l.seated[cc] = t
+	log.Printf("[lobby] %s created table %s (%d seats, %s rules)", cc.name, t.id, seats, rules.Name())
+	broadcast(t.players, newTableUpdateMsg(t.info()))
+	return nil
+}
+
+func (l *Lobby) join(cc *clientConn, id string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if t := l.seated[cc]; t != nil {
+		return fmt.Errorf("already at table %s", t.id)
+	}
+	t := l.tables[id]
+	switch {
+	case t == nil:
+		return fmt.Errorf("no table %q", id)
+	case t.game != nil:
+		return fmt.Errorf("table %s has already started", id)
+	case len(t.players) >= t.seats:
+		return fmt.Errorf("table %s is full", id)
+	}
+	t.players = append(t.players, cc)
+	l.seated[cc] = t
+	log.Printf("[lobby] %s joined table %s (%d/%d)", cc.name, t.id, len(t.players), t.seats)
+	broadcast(t.players, newTableUpdateMsg(t.info()))
+	return nil
+}
+
+// leave takes cc off its table before the game starts. An empty table is
+// removed.
+func (l *Lobby) leave(cc *clientConn) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	t := l.seated[cc]
+	if t == nil || t.game != nil {
+		return errNotAtTable
+	}
+	delete(l.seated, cc)
+	i := clientIndex(t.players, cc)
+	t.players = append(t.players[:i], t.players[i+1:]...)
+	if len(t.players) == 0 {
+		delete(l.tables, t.id)
+		return nil
+	}
+	broadcast(t.players, newTableUpdateMsg(t.info()))
+	return nil
+}
+
+// start starts cc's table once it is full. Every player gets game_start
+// with their player ID before the game's goroutine sends anything else.
+func (l *Lobby) start(cc *clientConn) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	t := l.seated[cc]
+	switch {
+	case t == nil:
+		return errNotAtTable
+	case t.game != nil:
+		return fmt.Errorf("table %s has already started", t.id)
+	case len(t.players) < t.seats:
+		return fmt.Errorf("table %s needs %d more players", t.id, t.seats-len(t.players))
+	}
+
+	names := make([]string, len(t.players))
+	for i, p := range t.players {
+		p.playerID = fmt.Sprintf("player-%d", i)
+		names[i] = p.name
+	}
+	g := newServerGame(engine.NewGameWithRules(names, l.newSource(), t.rules), t.players, &l.o)
+	t.game = g
+
+	state := g.game.GetState()
+	for _, p := range t.players {
+		_ = writeToClient(p, newMessage(MsgGameStart, StatePayload{State: state, Coach: g.coach, PlayerID: p.playerID}))
+	}
+	log.Printf("[lobby] Table %s started: %v", t.id, names)
+	go l.run(t)
+	return nil
+}
+
+// run plays t's game, then closes the table and its connections.
+func (l *Lobby) run(t *table) {
+	g := t.game
+	if err := g.run(); err != nil {
+		log.Printf("[lobby] Table %s aborted: %v", t.id, err)
+	} else {
+		log.Printf("[lobby] Table %s finished", t.id)
+		g.recordGame()
+		g.rateGame()
+	}
+	g.sess.end()
+	g.watchers.closeAll()
+
+	l.mu.Lock()
+	delete(l.tables, t.id)
+	for _, cc := range t.players {
+		delete(l.seated, cc)
+	}
+	l.mu.Unlock()
+
+	for _, cc := range t.players {
+		cc.currentConn().Close()
+	}
+}
+
+// gameOf returns the started game cc is seated at and its seat index, or
+// nil.
+func (l *Lobby) gameOf(cc *clientConn) (*serverGame, int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	t := l.seated[cc]
+	if t == nil || t.game == nil {
+		return nil, -1
+	}
+	return t.game, clientIndex(t.players, cc)
+}
+
+// resume hands a resumed seat back to its game.
+func (l *Lobby) resume(conn net.Conn, msg *Message) {
+	rp, err := DecodeResume(msg)
+	if err != nil {
+		conn.Close()
+		return
+	}
+	var g *serverGame
+	l.mu.Lock()
+	for cc, t := range l.seated {
+		if cc.token == rp.Token && t.game != nil {
+			g = t.game
+			break
+		}
+	}
+	l.mu.Unlock()
+	if g == nil {
+		_ = WriteMessage(conn, NewErrorMsg(errSessionExpired.Error()))
+		conn.Close()
+		return
+	}
+	cc, err := g.sess.resume(conn, rp.Token)
+	if err != nil {
+		_ = WriteMessage(conn, NewErrorMsg(err.Error()))
+		conn.Close()
+		return
+	}
+	g.resumed(cc, conn)
+}
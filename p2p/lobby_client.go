@@ -0,0 +1,251 @@
+package p2p
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+)
+
+// LobbyClient is a player's connection to a Lobby before their game
+// starts. Once seated at a table, WaitForGame hands the connection over to
+// a RemoteClient.
+type LobbyClient struct {
+	conn  net.Conn
+	addr  string
+	name  string
+	o     options
+	token string
+	table *TableInfo // latest table_update
+
+	features Features
+}
+
+// DialLobby connects to the lobby server at addr and performs the
+// handshake.
+func DialLobby(addr, name string, opts ...Option) (*LobbyClient, error) {
+	conn, err := dial(addr, 0, newOptions(opts).tlsConfig)
+	if err != nil {
+		return nil, fmt.Errorf("connect: %w", err)
+	}
+	lc, err := newLobbyClientFromConn(conn, name, opts...)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	lc.addr = addr
+	return lc, nil
+}
+
+func newLobbyClientFromConn(conn net.Conn, name string, opts ...Option) (*LobbyClient, error) {
+	o := newOptions(opts)
+	hello := newHello(HandshakePayload{Name: name, Coach: o.coach != nil, Password: o.password, AccountKey: o.accountKey})
+	if err := WriteMessage(conn, hello); err != nil {
+		return nil, fmt.Errorf("send handshake: %w", err)
+	}
+	msg, err := ReadMessage(conn)
+	if err != nil {
+		return nil, fmt.Errorf("read handshake: %w", err)
+	}
+	if msg.Type == MsgError {
+		return nil, lobbyError(msg)
+	}
+	if msg.Type != MsgHandshake {
+		return nil, fmt.Errorf("expected handshake, got %s", msg.Type)
+	}
+	hs, err := DecodeHandshake(msg)
+	if err != nil {
+		return nil, fmt.Errorf("decode handshake: %w", err)
+	}
+	f, err := acceptedFeatures(hs)
+	if err != nil {
+		return nil, err
+	}
+	return &LobbyClient{conn: conn, name: name, o: o, token: hs.Token, features: f}, nil
+}
+
+func lobbyError(msg *Message) error {
+	ep, err := DecodeError(msg)
+	if err != nil {
+		return errors.New("lobby error")
+	}
+	return fmt.Errorf("lobby: %s", ep.Message)
+}
+
+// request sends msg and reads until a reply of type want or an error,
+// keeping any table_update that arrives meanwhile.
+func (lc *LobbyClient) request(msg *Message, want string) (*Message, error) {
+	if err := WriteMessage(lc.conn, msg); err != nil {
+		return nil, fmt.Errorf("send %s: %w", msg.Type, err)
+	}
+	for {
+		reply, err := ReadMessage(lc.conn)
+		if err != nil {
+			return nil, fmt.Errorf("read %s reply: %w", msg.Type, err)
+		}
+		switch reply.Type {
+		case want:
+			return reply, nil
+		case MsgError:
+			return nil, lobbyError(reply)
+		case MsgTableUpdate:
+			if t, err := DecodeTable(reply); err == nil {
+				lc.table = t
+			}
+		}
+	}
+}
+
+// ListTables returns the tables that are still open.
+func (lc *LobbyClient) ListTables() ([]TableInfo, error) {
+	msg, err := lc.request(NewListTablesMsg(), MsgTables)
+	if err != nil {
+		return nil, err
+	}
+	tp, err := DecodeTables(msg)
+	if err != nil {
+		return nil, err
+	}
+	return tp.Tables, nil
+}
+
+// Leaderboard returns up to limit of the server's best-rated players (all
+// of them if limit is zero).
+func (lc *LobbyClient) Leaderboard(limit int) ([]LeaderboardEntry, error) {
+	if !lc.features.Has(CapRatings) {
+		return nil, errors.New("lobby: this server doesn't keep ratings")
+	}
+	msg, err := lc.request(NewLeaderboardMsg(limit), MsgLeaderboard)
+	if err != nil {
+		return nil, err
+	}
+	lp, err := DecodeLeaderboard(msg)
+	if err != nil {
+		return nil, err
+	}
+	return lp.Entries, nil
+}
+
+// CreateTable opens a table with the given number of seats and sits at it.
+// An empty rules uses the server's default.
+func (lc *LobbyClient) CreateTable(seats int, rules string) (*TableInfo, error) {
+	return lc.seat(NewCreateTableMsg(seats, rules))
+}
+
+// JoinTable sits at the open table with the given ID.
+func (lc *LobbyClient) JoinTable(id string) (*TableInfo, error) {
+	return lc.seat(NewJoinTableMsg(id))
+}
+
+func (lc *LobbyClient) seat(msg *Message) (*TableInfo, error) {
+	reply, err := lc.request(msg, MsgTableUpdate)
+	if err != nil {
+		return nil, err
+	}
+	t, err := DecodeTable(reply)
+	if err != nil {
+		return nil, err
+	}
+	lc.table = t
+	return t, nil
+}
+
+// LeaveTable gets up from the current table.
+func (lc *LobbyClient) LeaveTable() error {
+	lc.table = nil
+	return WriteMessage(lc.conn, NewLeaveTableMsg())
+}
+
+// Start asks the server to start the current table. It is rejected unless
+// the table is full; the outcome arrives through WaitForGame.
+func (lc *LobbyClient) Start() error {
+	return WriteMessage(lc.conn, NewStartTableMsg())
+}
+
+// Table returns the latest known state of the current table, or nil.
+func (lc *LobbyClient) Table() *TableInfo {
+	return lc.table
+}
+
+// WaitForGame waits for the current table to start, calling onUpdate (if
+// non-nil) for each table_update meanwhile. The returned RemoteClient owns
+// the connection from then on.
+func (lc *LobbyClient) WaitForGame(onUpdate func(TableInfo)) (*RemoteClient, error) {
+	for {
+		msg, err := ReadMessage(lc.conn)
+		if err != nil {
+			return nil, fmt.Errorf("waiting for game: %w", err)
+		}
+		switch msg.Type {
+		case MsgTableUpdate:
+			t, err := DecodeTable(msg)
+			if err != nil {
+				continue
+			}
+			lc.table = t
+			if onUpdate != nil {
+				onUpdate(*t)
+			}
+		case MsgError:
+			return nil, lobbyError(msg)
+		case MsgGameStart:
+			sp, err := DecodeState(msg)
+			if err != nil {
+				return nil, fmt.Errorf("decode game_start: %w", err)
+			}
+			rc := newRemoteClient(lc.conn, lc.name, sp.PlayerID, lc.token, lc.features, sp, lc.o)
+			rc.addr = lc.addr
+			return rc, nil
+		}
+	}
+}
+
+func (lc *LobbyClient) Close() error {
+	return lc.conn.Close()
+}
+
+// TableRequest picks a table at a lobby: the table with ID TableID, or a
+// new one with Seats seats and Rules (empty for the server's default).
+type TableRequest struct {
+	TableID string
+	Seats   int
+	Rules   string
+}
+
+// RunLobbyGuest sits at a lobby table and plays its game in the TUI. The
+// player whose join fills the table starts it.
+func RunLobbyGuest(addr, name string, req TableRequest, opts ...Option) error {
+	lc, err := DialLobby(addr, name, opts...)
+	if err != nil {
+		return err
+	}
+	var t *TableInfo
+	if req.TableID != "" {
+		t, err = lc.JoinTable(req.TableID)
+	} else {
+		t, err = lc.CreateTable(req.Seats, req.Rules)
+	}
+	if err != nil {
+		lc.Close()
+		return err
+	}
+
+	show := func(t TableInfo) {
+		fmt.Printf("Table %s (%s): %d/%d players: %s\n", t.ID, t.Rules, len(t.Players), t.Seats, strings.Join(t.Players, ", "))
+	}
+	show(*t)
+	if len(t.Players) == t.Seats {
+		if err := lc.Start(); err != nil {
+			lc.Close()
+			return err
+		}
+	}
+
+	rc, err := lc.WaitForGame(show)
+	if err != nil {
+		lc.Close()
+		return err
+	}
+	defer rc.Close()
+	return runGuestTUI(rc, name, lc.o)
+}
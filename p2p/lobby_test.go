@@ -0,0 +1,355 @@
+package p2p
+
+import (
+	"net"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/edge2992/yatzcli/engine"
+	"github.com/edge2992/yatzcli/rating"
+)
+
+func startLobby(t *testing.T, opts ...Option) string {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	t.Cleanup(func() { ln.Close() })
+	go NewLobby(opts...).Serve(ln)
+	return ln.Addr().String()
+}
+
+func dialLobby(t *testing.T, addr, name string, opts ...Option) *LobbyClient {
+	t.Helper()
+	lc, err := DialLobby(addr, name, opts...)
+	if err != nil {
+		t.Fatalf("dial lobby as %s: %v", name, err)
+	}
+	t.Cleanup(func() { lc.Close() })
+	return lc
+}
+
+// waitForGame runs lc.WaitForGame in the background.
+func waitForGame(lc *LobbyClient) <-chan *RemoteClient {
+	ch := make(chan *RemoteClient, 1)
+	go func() {
+		rc, err := lc.WaitForGame(nil)
+		if err != nil {
+			ch <- nil
+			return
+		}
+		ch <- rc
+	}()
+	return ch
+}
+
+func TestLobby_TablesRunIndependently(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping lobby test in short mode")
+	}
+	addr := startLobby(t)
+
+	alice := dialLobby(t, addr, "Alice")
+	t1, err := alice.CreateTable(2, "")
+	if err != nil {
+		t.Fatalf("create table: %v", err)
+	}
+	carol := dialLobby(t, addr, "Carol")
+	t2, err := carol.CreateTable(2, "yacht")
+	if err != nil {
+		t.Fatalf("create table: %v", err)
+	}
+
+	bob := dialLobby(t, addr, "Bob")
+	tables, err := bob.ListTables()
+	if err != nil {
+		t.Fatalf("list tables: %v", err)
+	}
+	if len(tables) != 2 || tables[0].ID != t1.ID || tables[1].ID != t2.ID {
+		t.Fatalf("tables = %+v, want %s and %s", tables, t1.ID, t2.ID)
+	}
+	if tables[1].Rules != "yacht" || tables[1].Seats != 2 {
+		t.Errorf("table %s = %+v", t2.ID, tables[1])
+	}
+	if _, err := bob.JoinTable(t1.ID); err != nil {
+		t.Fatalf("Bob join: %v", err)
+	}
+	dave := dialLobby(t, addr, "Dave")
+	if _, err := dave.JoinTable(t2.ID); err != nil {
+		t.Fatalf("Dave join: %v", err)
+	}
+
+	// Start both tables.
+	games := map[string]<-chan *RemoteClient{
+		"Alice": waitForGame(alice), "Bob": waitForGame(bob),
+		"Carol": waitForGame(carol), "Dave": waitForGame(dave),
+	}
+	if err := alice.Start(); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	if err := dave.Start(); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	rcs := make(map[string]*RemoteClient)
+	for name, ch := range games {
+		rc := <-ch
+		if rc == nil {
+			t.Fatalf("%s: game did not start", name)
+		}
+		defer rc.Close()
+		rcs[name] = rc
+	}
+
+	if f := rcs["Alice"].Features(); f.Version != ProtocolVersion || !f.Has(CapLobby) || f.Has(CapTurnClock) {
+		t.Errorf("negotiated %v, want lobby without a turn clock", f)
+	}
+	if rcs["Alice"].PlayerID() != "player-0" || rcs["Bob"].PlayerID() != "player-1" {
+		t.Errorf("table %s player IDs = %s, %s", t1.ID, rcs["Alice"].PlayerID(), rcs["Bob"].PlayerID())
+	}
+	carolState, _ := rcs["Carol"].GetState()
+	if carolState.Rules != "yacht" || carolState.Players[1].Name != "Dave" {
+		t.Errorf("table %s state: rules %s, players %+v", t2.ID, carolState.Rules, carolState.Players)
+	}
+
+	// A move at one table isn't seen at the other.
+	if _, _, err := rcs["Alice"].WaitForTurn(); err != nil {
+		t.Fatalf("Alice wait for turn: %v", err)
+	}
+	gs, err := rcs["Alice"].Roll()
+	if err != nil {
+		t.Fatalf("Alice roll: %v", err)
+	}
+	if gs.RollCount != 1 || gs.Players[1].Name != "Bob" {
+		t.Errorf("after roll: RollCount %d, players %+v", gs.RollCount, gs.Players)
+	}
+	if _, _, err := rcs["Carol"].WaitForTurn(); err != nil {
+		t.Fatalf("Carol wait for turn: %v", err)
+	}
+	if gs, _ := rcs["Carol"].GetState(); gs.RollCount != 0 {
+		t.Errorf("Carol's table saw a roll: RollCount %d", gs.RollCount)
+	}
+
+	// Started tables are no longer listed.
+	erin := dialLobby(t, addr, "Erin")
+	if tables, err := erin.ListTables(); err != nil || len(tables) != 0 {
+		t.Errorf("tables after start = %+v, %v; want none", tables, err)
+	}
+}
+
+func TestLobby_Errors(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping lobby test in short mode")
+	}
+	addr := startLobby(t)
+
+	alice := dialLobby(t, addr, "Alice")
+	if _, err := alice.CreateTable(1, ""); err == nil {
+		t.Error("expected error creating a 1-seat table")
+	}
+	if _, err := alice.CreateTable(2, "nope"); err == nil {
+		t.Error("expected error for unknown rules")
+	}
+	if _, err := alice.JoinTable("t99"); err == nil || !strings.Contains(err.Error(), "no table") {
+		t.Errorf("join missing table: %v", err)
+	}
+	tbl, err := alice.CreateTable(2, "")
+	if err != nil {
+		t.Fatalf("create table: %v", err)
+	}
+	if _, err := alice.CreateTable(2, ""); err == nil {
+		t.Error("expected error creating a second table while seated")
+	}
+
+	// Starting before the table is full is rejected.
+	if _, err := alice.request(NewStartTableMsg(), MsgGameStart); err == nil || !strings.Contains(err.Error(), "needs 1 more") {
+		t.Errorf("start before full: %v", err)
+	}
+
+	bob := dialLobby(t, addr, "Bob")
+	if _, err := bob.JoinTable(tbl.ID); err != nil {
+		t.Fatalf("join: %v", err)
+	}
+	carol := dialLobby(t, addr, "Carol")
+	if _, err := carol.JoinTable(tbl.ID); err == nil || !strings.Contains(err.Error(), "full") {
+		t.Errorf("join full table: %v", err)
+	}
+
+	// When Bob leaves, the seat frees up. Bob's next request is handled
+	// after his leave.
+	if err := bob.LeaveTable(); err != nil {
+		t.Fatalf("leave: %v", err)
+	}
+	tables, err := bob.ListTables()
+	if err != nil || len(tables) != 1 || len(tables[0].Players) != 1 {
+		t.Fatalf("tables after leave = %+v, %v", tables, err)
+	}
+	if _, err := carol.JoinTable(tbl.ID); err != nil {
+		t.Fatalf("Carol join after leave: %v", err)
+	}
+}
+
+func TestLobby_FullGame(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping lobby test in short mode")
+	}
+	addr := startLobby(t, WithRules(engine.DefaultRules()))
+
+	alice := dialLobby(t, addr, "Alice")
+	tbl, err := alice.CreateTable(2, "")
+	if err != nil {
+		t.Fatalf("create table: %v", err)
+	}
+	bob := dialLobby(t, addr, "Bob")
+	if _, err := bob.JoinTable(tbl.ID); err != nil {
+		t.Fatalf("join: %v", err)
+	}
+
+	// Alice starts the table as soon as she sees it full.
+	aliceGame := make(chan *RemoteClient, 1)
+	go func() {
+		rc, _ := alice.WaitForGame(func(ti TableInfo) {
+			if len(ti.Players) == ti.Seats {
+				alice.Start()
+			}
+		})
+		aliceGame <- rc
+	}()
+	bobGame := waitForGame(bob)
+	rcA, rcB := <-aliceGame, <-bobGame
+	if rcA == nil || rcB == nil {
+		t.Fatal("game did not start")
+	}
+	defer rcA.Close()
+	defer rcB.Close()
+
+	playOut(t, rcA, rcB)
+}
+
+func TestLobby_JoinerStarts(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping lobby test in short mode")
+	}
+	addr := startLobby(t, WithRules(engine.DefaultRules()))
+
+	alice := dialLobby(t, addr, "Alice")
+	tbl, err := alice.CreateTable(2, "")
+	if err != nil {
+		t.Fatalf("create table: %v", err)
+	}
+	bob := dialLobby(t, addr, "Bob")
+	if _, err := bob.JoinTable(tbl.ID); err != nil {
+		t.Fatalf("join: %v", err)
+	}
+
+	// Bob filled the table, so he starts it.
+	aliceGame, bobGame := waitForGame(alice), waitForGame(bob)
+	if err := bob.Start(); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	rcA, rcB := <-aliceGame, <-bobGame
+	if rcA == nil || rcB == nil {
+		t.Fatal("game did not start")
+	}
+	defer rcA.Close()
+	defer rcB.Close()
+	playOut(t, rcA, rcB)
+}
+
+// playOut plays the game of rcs to the end, each scoring the first open
+// category after one roll.
+func playOut(t *testing.T, rcs ...*RemoteClient) {
+	t.Helper()
+	finished := make(chan *engine.GameState, len(rcs))
+	play := func(rc *RemoteClient) {
+		state, over, err := rc.WaitForTurn()
+		for err == nil && !over {
+			if state, err = rc.Roll(); err != nil {
+				break
+			}
+			state, err = rc.Score(state.AvailableCategories[0])
+			over = err == nil && state.Phase == engine.PhaseFinished
+		}
+		if err != nil {
+			t.Errorf("%s: %v", rc.playerName, err)
+		}
+		finished <- state
+	}
+	for _, rc := range rcs {
+		go play(rc)
+	}
+
+	for range rcs {
+		if gs := <-finished; gs == nil || gs.Phase != engine.PhaseFinished {
+			t.Fatalf("game did not finish: %+v", gs)
+		}
+	}
+}
+
+func TestLobby_Ratings(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping lobby test in short mode")
+	}
+	store, err := rating.Open(filepath.Join(t.TempDir(), "ratings.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+	addr := startLobby(t, WithRatings(store))
+
+	alice := dialLobby(t, addr, "Alice", WithAccountKey("alice-key"))
+	if !alice.features.Has(CapRatings) {
+		t.Fatalf("negotiated %v, want ratings", alice.features)
+	}
+	if _, err := DialLobby(addr, "Alice", WithAccountKey("not-alice")); err == nil || !strings.Contains(err.Error(), "another player's account") {
+		t.Errorf("impostor: %v", err)
+	}
+	guest := dialLobby(t, addr, "Guest")
+	if entries, err := guest.Leaderboard(10); err != nil || len(entries) != 0 {
+		t.Fatalf("leaderboard before any game = %+v, %v", entries, err)
+	}
+
+	tbl, err := alice.CreateTable(2, "")
+	if err != nil {
+		t.Fatalf("create table: %v", err)
+	}
+	bob := dialLobby(t, addr, "Bob", WithAccountKey("bob-key"))
+	if _, err := bob.JoinTable(tbl.ID); err != nil {
+		t.Fatalf("join: %v", err)
+	}
+	aliceGame, bobGame := waitForGame(alice), waitForGame(bob)
+	if err := bob.Start(); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	rcA, rcB := <-aliceGame, <-bobGame
+	if rcA == nil || rcB == nil {
+		t.Fatal("game did not start")
+	}
+	defer rcA.Close()
+	defer rcB.Close()
+	playOut(t, rcA, rcB)
+
+	// The game is rated once the table closes.
+	var entries []LeaderboardEntry
+	for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); time.Sleep(20 * time.Millisecond) {
+		if entries, err = guest.Leaderboard(10); err != nil || len(entries) == 2 {
+			break
+		}
+	}
+	if err != nil || len(entries) != 2 {
+		t.Fatalf("leaderboard = %+v, %v, want Alice and Bob", entries, err)
+	}
+	top, bottom := entries[0], entries[1]
+	if top.Games != 1 || bottom.Games != 1 || top.Rating < bottom.Rating || top.Deviation >= rating.DefaultDeviation {
+		t.Errorf("leaderboard = %+v", entries)
+	}
+	if top.Rating != bottom.Rating && (top.Wins != 1 || bottom.Wins != 0) {
+		t.Errorf("leaderboard = %+v: the higher rated player should have won", entries)
+	}
+
+	if _, err := dialLobby(t, startLobby(t), "Guest").Leaderboard(10); err == nil {
+		t.Error("leaderboard from a lobby without ratings")
+	}
+}
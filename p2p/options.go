@@ -0,0 +1,234 @@
+package p2p
+
+import (
+	"crypto/tls"
+	"log"
+	"slices"
+	"time"
+
+	"github.com/edge2992/yatzcli/engine"
+	"github.com/edge2992/yatzcli/rating"
+	"github.com/edge2992/yatzcli/stats"
+)
+
+// Option configures a P2P game.
+type Option func(*options)
+
+type options struct {
+	rules   engine.RuleSet
+	coach   engine.Strategy
+	players int
+	grace   time.Duration
+
+	turnLimit time.Duration
+	autoPlay  engine.Strategy
+
+	aiSeats []AISeat
+	aiDelay time.Duration
+
+	tlsConfig *tls.Config
+	password  string
+
+	wsPort int
+
+	fairDice bool
+
+	record func(stats.Game) error
+
+	ratings    *rating.Store
+	accountKey string
+}
+
+func newOptions(opts []Option) options {
+	o := options{rules: engine.DefaultRules(), players: 2, grace: DefaultGracePeriod, aiDelay: DefaultAIDelay}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// WithRules sets the rule set the game is played under.
+func WithRules(rules engine.RuleSet) Option {
+	return func(o *options) {
+		if rules != nil {
+			o.rules = rules
+		}
+	}
+}
+
+// WithCoach asks for the in-game hint key, with recommendations from
+// strategy. Hints are only enabled when every player asks for them.
+func WithCoach(strategy engine.Strategy) Option {
+	return func(o *options) {
+		o.coach = strategy
+	}
+}
+
+// WithPlayers sets the number of players in a hosted game, including the
+// host. RunHost waits for players-1 guests before starting.
+func WithPlayers(n int) Option {
+	return func(o *options) {
+		if n >= 2 {
+			o.players = n
+		}
+	}
+}
+
+// WithGracePeriod sets how long a dropped player's seat is held for a
+// resume. For RemoteClient it is how long to keep redialing.
+func WithGracePeriod(d time.Duration) Option {
+	return func(o *options) {
+		o.grace = d
+	}
+}
+
+// WithTurnClock limits each turn to limit. When a player runs out of time
+// the server plays the rest of their turn with strategy (statistical if
+// nil); players who time out repeatedly are marked AFK and get a shorter
+// clock until they act again.
+func WithTurnClock(limit time.Duration, strategy engine.Strategy) Option {
+	return func(o *options) {
+		o.turnLimit = limit
+		o.autoPlay = strategy
+	}
+}
+
+// DefaultAIDelay is the pause around each roll and hold of an in-process AI
+// seat, so remote players can follow its turns.
+const DefaultAIDelay = 800 * time.Millisecond
+
+// AISeat is a seat RunServer plays in-process with Strategy.
+type AISeat struct {
+	Name     string
+	Strategy engine.Strategy
+}
+
+// WithAISeats adds in-process AI players to a RunServer game. They take
+// seats after the remote players, so RunServer accepts that many fewer
+// connections.
+func WithAISeats(seats ...AISeat) Option {
+	return func(o *options) {
+		o.aiSeats = append(o.aiSeats, seats...)
+	}
+}
+
+// WithAIDelay sets the pause around each step of an AI seat's turn
+// (DefaultAIDelay by default; zero plays AI turns at full speed).
+func WithAIDelay(d time.Duration) Option {
+	return func(o *options) {
+		o.aiDelay = d
+	}
+}
+
+// WithTLS secures connections with cfg. Servers and hosts wrap their
+// listener (cfg from ServerTLSConfig); clients dial with it (cfg from
+// PinnedTLSConfig or TrustOnFirstUseTLSConfig).
+func WithTLS(cfg *tls.Config) Option {
+	return func(o *options) {
+		o.tlsConfig = cfg
+	}
+}
+
+// WithPassword sets the room password. Servers and hosts turn away
+// handshakes without it; clients send it in their handshake.
+func WithPassword(password string) Option {
+	return func(o *options) {
+		o.password = password
+	}
+}
+
+// WithWebSocketPort makes RunHost also accept guests over WebSocket on port,
+// alongside its TCP port.
+func WithWebSocketPort(port int) Option {
+	return func(o *options) {
+		o.wsPort = port
+	}
+}
+
+// WithVerifiableDice makes RunServer and RunHost roll dice every player can
+// check: each player commits to a seed at game start, the dice come from
+// the combined seeds, and the seeds are revealed at game_over for
+// RemoteClient to verify every roll and hold. The server still knows the
+// rolls in advance, so this guards against rigged dice, not foresight.
+// Players whose client doesn't support it are turned away.
+func WithVerifiableDice() Option {
+	return func(o *options) {
+		o.fairDice = true
+	}
+}
+
+// WithGameRecorder hands every finished game to record, e.g. stats.Record.
+// Hosts and guests record the game with their own seat marked; servers and
+// lobbies record each game with their AI seats' strategies.
+func WithGameRecorder(record func(stats.Game) error) Option {
+	return func(o *options) {
+		o.record = record
+	}
+}
+
+// WithRatings makes RunServer and Lobby keep accounts and Glicko-2 ratings
+// in store. Players who send an account key (see WithAccountKey) are
+// signed in to the account with their name, which the first key claims;
+// games between two or more signed-in players are rated, each against
+// every other. A Lobby also answers leaderboard requests. Without a key a
+// player plays unrated, under a name no account holds.
+func WithRatings(store *rating.Store) Option {
+	return func(o *options) {
+		o.ratings = store
+	}
+}
+
+// WithAccountKey makes clients sign in with key to the account with their
+// name on servers that keep ratings.
+func WithAccountKey(key string) Option {
+	return func(o *options) {
+		o.accountKey = key
+	}
+}
+
+// signIn checks hs's account on a server that keeps ratings, and reports
+// whether the player's games are rated.
+func (o *options) signIn(hs *HandshakePayload) (bool, error) {
+	if o.ratings == nil {
+		return false, nil
+	}
+	return o.ratings.SignIn(hs.Name, hs.AccountKey)
+}
+
+// rateGame rates the finished game in state between the players whose
+// IDs are in rated, if there are at least two, and logs a failure.
+func (o *options) rateGame(state engine.GameState, rated map[string]bool) {
+	if o.ratings == nil || state.Phase != engine.PhaseFinished {
+		return
+	}
+	var names []string
+	var scores []int
+	for _, p := range state.Players {
+		if !rated[p.ID] {
+			continue
+		}
+		if slices.Contains(names, p.Name) {
+			log.Printf("Rate game: %q sat twice; not rated", p.Name)
+			return
+		}
+		names = append(names, p.Name)
+		scores = append(scores, p.Scorecard.Total())
+	}
+	if len(names) < 2 {
+		return
+	}
+	if err := o.ratings.RecordGame(names, scores); err != nil {
+		log.Printf("Rate game: %v", err)
+	}
+}
+
+// recordGame records the finished game in state with o's recorder, if any,
+// and logs a failure.
+func (o *options) recordGame(mode string, state engine.GameState, you string, strategies map[string]string) {
+	if o.record == nil || state.Phase != engine.PhaseFinished {
+		return
+	}
+	if err := o.record(stats.NewGame(mode, state, you, strategies)); err != nil {
+		log.Printf("Record game: %v", err)
+	}
+}
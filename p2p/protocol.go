@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"time"
 
 	"github.com/edge2992/yatzcli/engine"
 )
@@ -18,6 +19,22 @@ const (
 	MsgGameOver    = "game_over"
 	MsgError       = "error"
 	MsgChat        = "chat"
+	MsgResume      = "resume"
+	MsgSeed        = "seed"
+	// MsgLeaderboard asks a lobby server for its leaderboard, and carries
+	// the reply.
+	MsgLeaderboard = "leaderboard"
+)
+
+// Lobby messages, used before a game starts on a lobby server (see Lobby).
+const (
+	MsgListTables  = "list_tables"
+	MsgTables      = "tables"
+	MsgCreateTable = "create_table"
+	MsgJoinTable   = "join_table"
+	MsgLeaveTable  = "leave_table"
+	MsgStartTable  = "start_table"
+	MsgTableUpdate = "table_update"
 )
 
 const (
@@ -34,6 +51,41 @@ type Message struct {
 type HandshakePayload struct {
 	Name     string `json:"name"`
 	PlayerID string `json:"player_id,omitempty"`
+	// Coach asks for the in-game hint key. Hints are only enabled when
+	// every player asks for them.
+	Coach bool `json:"coach,omitempty"`
+	// Token is the session token assigned in the reply. Sending it in a
+	// resume message reclaims the seat after a dropped connection.
+	Token string `json:"token,omitempty"`
+	// Role is RoleSpectator for a read-only client; empty means a player.
+	Role string `json:"role,omitempty"`
+	// Password is the room password, if the server requires one.
+	Password string `json:"password,omitempty"`
+	// Version is the sender's ProtocolVersion; absent before versioning.
+	Version int `json:"version,omitempty"`
+	// Capabilities are those the client understands, and in the reply
+	// those negotiated for the connection.
+	Capabilities []string `json:"capabilities,omitempty"`
+	// Commitment is the hex SHA-256 of the player's dice seed, for games
+	// with verifiable dice.
+	Commitment string `json:"commitment,omitempty"`
+	// AccountKey signs the player in to the account named Name on a
+	// server that keeps ratings (see WithRatings).
+	AccountKey string `json:"account_key,omitempty"`
+}
+
+// Handshake roles.
+const (
+	RolePlayer    = "player"
+	RoleSpectator = "spectator"
+)
+
+// ResumePayload is sent by a reconnecting client with its session token.
+// The reply carries the player's ID and the current state.
+type ResumePayload struct {
+	Token    string            `json:"token,omitempty"`
+	PlayerID string            `json:"player_id,omitempty"`
+	State    *engine.GameState `json:"state,omitempty"`
 }
 
 type ActionPayload struct {
@@ -44,6 +96,63 @@ type ActionPayload struct {
 
 type StatePayload struct {
 	State engine.GameState `json:"state"`
+	// Coach is set on game_start when every player agreed to hints.
+	Coach bool `json:"coach,omitempty"`
+	// TimeLeftMs is set on turn_start when turns are timed: the
+	// milliseconds left before the server plays the turn for the player.
+	TimeLeftMs int64 `json:"time_left_ms,omitempty"`
+	// PlayerID is set on game_start from a lobby table: the recipient's
+	// player ID, which isn't known at handshake time.
+	PlayerID string `json:"player_id,omitempty"`
+	// FairDice is set on game_start and game_over of a game with
+	// verifiable dice.
+	FairDice *FairDicePayload `json:"fair_dice,omitempty"`
+	// Held is set on the state_update of a reroll: the indices of the dice
+	// kept from the previous roll. It is empty when none were.
+	Held []int `json:"held,omitempty"`
+}
+
+// FairDicePayload lists each seat's seed commitment, in seat order. On
+// game_over it also reveals the seeds.
+type FairDicePayload struct {
+	Commitments []string `json:"commitments"`
+	Seeds       []string `json:"seeds,omitempty"`
+}
+
+// SeedPayload reveals a player's dice seed, hex-encoded, to the server.
+type SeedPayload struct {
+	Seed string `json:"seed"`
+}
+
+// TableInfo describes a lobby table. A create_table request carries Seats
+// and Rules, a join_table request carries ID, and table_update carries the
+// whole table.
+type TableInfo struct {
+	ID      string   `json:"id,omitempty"`
+	Seats   int      `json:"seats,omitempty"`
+	Rules   string   `json:"rules,omitempty"`
+	Players []string `json:"players,omitempty"`
+}
+
+// TablesPayload answers list_tables with the tables still open.
+type TablesPayload struct {
+	Tables []TableInfo `json:"tables"`
+}
+
+// LeaderboardPayload is a leaderboard request for up to Limit entries (all
+// of them if zero), and in the reply the entries, best rated first.
+type LeaderboardPayload struct {
+	Limit   int                `json:"limit,omitempty"`
+	Entries []LeaderboardEntry `json:"entries,omitempty"`
+}
+
+// LeaderboardEntry is an account's Glicko-2 rating and record.
+type LeaderboardEntry struct {
+	Name      string  `json:"name"`
+	Rating    float64 `json:"rating"`
+	Deviation float64 `json:"deviation"`
+	Games     int     `json:"games"`
+	Wins      int     `json:"wins"`
 }
 
 type ErrorPayload struct {
@@ -58,6 +167,9 @@ type ChatPayload struct {
 
 // WriteMessage writes a length-prefixed (uint32 big-endian) JSON message to w.
 func WriteMessage(w io.Writer, msg *Message) error {
+	if mc, ok := w.(messageConn); ok {
+		return mc.writeMessage(msg)
+	}
 	data, err := json.Marshal(msg)
 	if err != nil {
 		return fmt.Errorf("marshal message: %w", err)
@@ -75,6 +187,9 @@ const maxMessageSize = 1 << 20 // 1 MB
 
 // ReadMessage reads a length-prefixed JSON message from r.
 func ReadMessage(r io.Reader) (*Message, error) {
+	if mc, ok := r.(messageConn); ok {
+		return mc.readMessage()
+	}
 	var length uint32
 	if err := binary.Read(r, binary.BigEndian, &length); err != nil {
 		return nil, fmt.Errorf("read length: %w", err)
@@ -105,7 +220,36 @@ func newMessage(msgType string, payload any) *Message {
 }
 
 func NewHandshakeMsg(name string) *Message {
-	return newMessage(MsgHandshake, HandshakePayload{Name: name})
+	return newHello(HandshakePayload{Name: name})
+}
+
+// NewSpectateMsg is the handshake of a read-only client.
+func NewSpectateMsg(name string) *Message {
+	return newHello(HandshakePayload{Name: name, Role: RoleSpectator})
+}
+
+// newHello is a client's handshake, with this build's protocol version and
+// capabilities.
+func newHello(hs HandshakePayload) *Message {
+	hs.Version = ProtocolVersion
+	hs.Capabilities = clientCapabilities
+	return newMessage(MsgHandshake, hs)
+}
+
+func NewResumeMsg(token string) *Message {
+	return newMessage(MsgResume, ResumePayload{Token: token})
+}
+
+func NewSeedMsg(seed string) *Message {
+	return newMessage(MsgSeed, SeedPayload{Seed: seed})
+}
+
+func DecodeSeed(msg *Message) (*SeedPayload, error) {
+	var p SeedPayload
+	if err := json.Unmarshal(msg.Payload, &p); err != nil {
+		return nil, fmt.Errorf("decode seed: %w", err)
+	}
+	return &p, nil
 }
 
 func NewActionMsg(ap ActionPayload) *Message {
@@ -120,10 +264,22 @@ func NewGameStartMsg(state engine.GameState) *Message {
 	return newMessage(MsgGameStart, StatePayload{State: state})
 }
 
+// newStateUpdateMsgWithHeld is NewStateUpdateMsg for a reroll that kept the
+// dice held.
+func newStateUpdateMsgWithHeld(state engine.GameState, held []int) *Message {
+	return newMessage(MsgStateUpdate, StatePayload{State: state, Held: held})
+}
+
 func NewTurnStartMsg(state engine.GameState) *Message {
 	return newMessage(MsgTurnStart, StatePayload{State: state})
 }
 
+// newTurnStartMsgWithClock is NewTurnStartMsg telling the player how long
+// they have. A zero timeLeft means the turn is untimed.
+func newTurnStartMsgWithClock(state engine.GameState, timeLeft time.Duration) *Message {
+	return newMessage(MsgTurnStart, StatePayload{State: state, TimeLeftMs: timeLeft.Milliseconds()})
+}
+
 func NewGameOverMsg(state engine.GameState) *Message {
 	return newMessage(MsgGameOver, StatePayload{State: state})
 }
@@ -175,3 +331,71 @@ func DecodeError(msg *Message) (*ErrorPayload, error) {
 	}
 	return &p, nil
 }
+
+func DecodeResume(msg *Message) (*ResumePayload, error) {
+	var p ResumePayload
+	if err := json.Unmarshal(msg.Payload, &p); err != nil {
+		return nil, fmt.Errorf("decode resume: %w", err)
+	}
+	return &p, nil
+}
+
+func NewListTablesMsg() *Message {
+	return newMessage(MsgListTables, struct{}{})
+}
+
+func NewCreateTableMsg(seats int, rules string) *Message {
+	return newMessage(MsgCreateTable, TableInfo{Seats: seats, Rules: rules})
+}
+
+func NewJoinTableMsg(tableID string) *Message {
+	return newMessage(MsgJoinTable, TableInfo{ID: tableID})
+}
+
+func NewLeaveTableMsg() *Message {
+	return newMessage(MsgLeaveTable, struct{}{})
+}
+
+func NewStartTableMsg() *Message {
+	return newMessage(MsgStartTable, struct{}{})
+}
+
+func newTableUpdateMsg(t TableInfo) *Message {
+	return newMessage(MsgTableUpdate, t)
+}
+
+func newTablesMsg(tables []TableInfo) *Message {
+	return newMessage(MsgTables, TablesPayload{Tables: tables})
+}
+
+func DecodeTable(msg *Message) (*TableInfo, error) {
+	var p TableInfo
+	if err := json.Unmarshal(msg.Payload, &p); err != nil {
+		return nil, fmt.Errorf("decode table: %w", err)
+	}
+	return &p, nil
+}
+
+func DecodeTables(msg *Message) (*TablesPayload, error) {
+	var p TablesPayload
+	if err := json.Unmarshal(msg.Payload, &p); err != nil {
+		return nil, fmt.Errorf("decode tables: %w", err)
+	}
+	return &p, nil
+}
+
+func NewLeaderboardMsg(limit int) *Message {
+	return newMessage(MsgLeaderboard, LeaderboardPayload{Limit: limit})
+}
+
+func newLeaderboardReplyMsg(entries []LeaderboardEntry) *Message {
+	return newMessage(MsgLeaderboard, LeaderboardPayload{Entries: entries})
+}
+
+func DecodeLeaderboard(msg *Message) (*LeaderboardPayload, error) {
+	var p LeaderboardPayload
+	if err := json.Unmarshal(msg.Payload, &p); err != nil {
+		return nil, fmt.Errorf("decode leaderboard: %w", err)
+	}
+	return &p, nil
+}
@@ -9,19 +9,65 @@ import (
 	"time"
 
 	"github.com/edge2992/yatzcli/engine"
+	"github.com/edge2992/yatzcli/stats"
 )
 
 type clientConn struct {
 	conn     net.Conn
 	name     string
 	playerID string
+	token    string // session token for resuming the seat
+	coach    bool   // asked for hints in the handshake
+	rated    bool   // signed in to an account, see WithRatings
+	// commitment is the player's dice seed commitment, for verifiable dice.
+	commitment string
+	// strategy is set for an in-process AI seat, which has no connection.
+	strategy engine.Strategy
 	actionCh chan *ActionPayload
-	mu       sync.Mutex // protects conn writes
+	mu       sync.Mutex // protects conn writes and swaps
+
+	// Guarded by sessions.mu.
+	dropped bool // connection lost, seat held for a resume
+	epoch   int  // bumped on every drop and resume
+}
+
+func newClientConn(conn net.Conn, name, playerID string) *clientConn {
+	return &clientConn{
+		conn:     conn,
+		name:     name,
+		playerID: playerID,
+		token:    newSessionToken(),
+		actionCh: make(chan *ActionPayload, 8),
+	}
+}
+
+func (cc *clientConn) currentConn() net.Conn {
+	cc.mu.Lock()
+	defer cc.mu.Unlock()
+	return cc.conn
+}
+
+// dropStaleActions discards actions sent outside the player's turn, such
+// as those that arrived after their last turn was auto-played.
+func (cc *clientConn) dropStaleActions() {
+	for {
+		select {
+		case _, ok := <-cc.actionCh:
+			if !ok {
+				return
+			}
+		default:
+			return
+		}
+	}
 }
 
 func writeToClient(cc *clientConn, msg *Message) error {
 	cc.mu.Lock()
 	defer cc.mu.Unlock()
+	if cc.conn == nil {
+		return nil // AI seat
+	}
 	return WriteMessage(cc.conn, msg)
 }
 
@@ -31,171 +77,368 @@ func broadcast(clients []*clientConn, msg *Message) {
 	}
 }
 
-func notifyDisconnect(clients []*clientConn, disconnectedName string, excludeIdx int) {
-	errMsg := NewErrorMsg(fmt.Sprintf("player %q disconnected", disconnectedName))
-	for i, cc := range clients {
-		if i == excludeIdx {
-			continue
+// acceptClients accepts connections until numPlayers players have joined.
+// Spectators who connect meanwhile are returned separately and don't take a
+// seat. Connections without a valid handshake, from an incompatible
+// version or with the wrong password, are turned away.
+func acceptClients(ln net.Listener, numPlayers int, o *options) (clients, spectators []*clientConn, err error) {
+	clients = make([]*clientConn, 0, numPlayers)
+	closeAll := func() {
+		for _, cc := range clients {
+			cc.conn.Close()
+		}
+		for _, cc := range spectators {
+			cc.conn.Close()
 		}
-		_ = writeToClient(cc, errMsg)
 	}
-}
-
-func acceptClients(ln net.Listener, numPlayers int) ([]*clientConn, error) {
-	clients := make([]*clientConn, 0, numPlayers)
-	for i := 0; i < numPlayers; i++ {
+	for i := 0; i < numPlayers; {
 		conn, err := ln.Accept()
 		if err != nil {
 			// Close already-accepted connections
-			for _, cc := range clients {
-				cc.conn.Close()
-			}
-			return nil, fmt.Errorf("accept client %d: %w", i, err)
+			closeAll()
+			return nil, nil, fmt.Errorf("accept client %d: %w", i, err)
 		}
 
-		// Handshake with timeout
-		conn.SetDeadline(time.Now().Add(30 * time.Second))
-		msg, err := ReadMessage(conn)
+		hs, err := readHandshake(conn, o.password)
 		if err != nil {
+			log.Printf("[server] Rejected %s: %v", conn.RemoteAddr(), err)
 			conn.Close()
-			for _, cc := range clients {
-				cc.conn.Close()
+			continue
+		}
+
+		if hs.Role == RoleSpectator {
+			cc := newClientConn(conn, hs.Name, "")
+			f := negotiate(o.capabilities(CapSpectate), hs)
+			resp := handshakeReply(HandshakePayload{Name: "server", Role: RoleSpectator}, f)
+			if err := WriteMessage(conn, resp); err != nil {
+				conn.Close()
+				continue
 			}
-			return nil, fmt.Errorf("read handshake from client %d: %w", i, err)
+			spectators = append(spectators, cc)
+			log.Printf("[server] Spectator %s connected", hs.Name)
+			continue
 		}
-		if msg.Type != MsgHandshake {
-			conn.Close()
-			for _, cc := range clients {
-				cc.conn.Close()
+
+		if o.fairDice {
+			if err := checkCommitment(hs); err != nil {
+				log.Printf("[server] Rejected %s: %v", conn.RemoteAddr(), err)
+				_ = WriteMessage(conn, NewErrorMsg(err.Error()))
+				conn.Close()
+				continue
 			}
-			return nil, fmt.Errorf("expected handshake from client %d, got %s", i, msg.Type)
 		}
-		hs, err := DecodeHandshake(msg)
+
+		rated, err := o.signIn(hs)
 		if err != nil {
+			log.Printf("[server] Rejected %s: %v", conn.RemoteAddr(), err)
+			_ = WriteMessage(conn, NewErrorMsg(err.Error()))
 			conn.Close()
-			for _, cc := range clients {
-				cc.conn.Close()
-			}
-			return nil, fmt.Errorf("decode handshake from client %d: %w", i, err)
+			continue
 		}
 
 		playerID := fmt.Sprintf("player-%d", i)
+		cc := newClientConn(conn, hs.Name, playerID)
+		cc.commitment = hs.Commitment
+		cc.rated = rated
+		f := negotiate(o.capabilities(CapSpectate), hs)
+		cc.coach = hs.Coach && f.Has(CapCoach)
 
-		// Respond with server name + assigned playerID
-		resp := newMessage(MsgHandshake, HandshakePayload{
+		// Respond with server name + assigned playerID and session token
+		resp := handshakeReply(HandshakePayload{
 			Name:     "server",
 			PlayerID: playerID,
-		})
+			Token:    cc.token,
+		}, f)
 		if err := WriteMessage(conn, resp); err != nil {
 			conn.Close()
-			for _, cc := range clients {
-				cc.conn.Close()
-			}
-			return nil, fmt.Errorf("send handshake to client %d: %w", i, err)
+			continue
 		}
-		conn.SetDeadline(time.Time{}) // clear deadline
 
-		clients = append(clients, &clientConn{
-			conn:     conn,
-			name:     hs.Name,
-			playerID: playerID,
-			actionCh: make(chan *ActionPayload, 8),
-		})
-		log.Printf("[server] Player %s connected as %s (%d/%d)", hs.Name, playerID, i+1, numPlayers)
+		clients = append(clients, cc)
+		i++
+		log.Printf("[server] Player %s connected as %s (%d/%d)", hs.Name, playerID, i, numPlayers)
+	}
+	return clients, spectators, nil
+}
+
+// readHandshake reads a client's handshake within 30 seconds and checks
+// it with checkHandshake. A client that fails the check is told why.
+func readHandshake(conn net.Conn, password string) (*HandshakePayload, error) {
+	conn.SetDeadline(time.Now().Add(30 * time.Second))
+	defer conn.SetDeadline(time.Time{})
+	msg, err := ReadMessage(conn)
+	if err != nil {
+		return nil, fmt.Errorf("read handshake: %w", err)
+	}
+	if msg.Type != MsgHandshake {
+		return nil, fmt.Errorf("expected handshake, got %s", msg.Type)
+	}
+	hs, err := DecodeHandshake(msg)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkHandshake(hs, password); err != nil {
+		_ = WriteMessage(conn, NewErrorMsg(err.Error()))
+		return nil, err
+	}
+	return hs, nil
+}
+
+// checkHandshake admits a client whose protocol version this build plays
+// with and who gave the room password.
+func checkHandshake(hs *HandshakePayload, password string) error {
+	if err := checkVersion(hs.Version); err != nil {
+		return err
+	}
+	return checkPassword(password, hs.Password)
+}
+
+// handshakeReply is the server's side of a handshake that negotiated f.
+// The session token is only sent when resuming was negotiated.
+func handshakeReply(hs HandshakePayload, f Features) *Message {
+	hs.Version = ProtocolVersion
+	hs.Capabilities = f.Capabilities
+	if !f.Has(CapResume) {
+		hs.Token = ""
+	}
+	return newMessage(MsgHandshake, hs)
+}
+
+// serverGame is a game run by RunServer or a Lobby table: the seated
+// players, anyone watching, and the session state held for dropped players.
+type serverGame struct {
+	game     *engine.Game
+	clients  []*clientConn
+	watchers *watchers
+	sess     *sessions
+	clock    *turnClock
+	coach    bool
+	aiDelay  time.Duration
+	password string
+	// capabilities are offered to late spectators.
+	capabilities []string
+	// dealer rolls the dice when they are verifiable; nil otherwise.
+	dealer *fairDealer
+	o      *options
+}
+
+// newServerGame seats clients at game. Hints are enabled only if every
+// remote player asked for them.
+func newServerGame(game *engine.Game, clients []*clientConn, o *options) *serverGame {
+	g := &serverGame{
+		game:     game,
+		clients:  clients,
+		watchers: &watchers{},
+		sess:     newSessions(o.grace),
+		clock:    newTurnClock(o.turnLimit, o.autoPlay),
+		coach:    true,
+		aiDelay:  o.aiDelay,
+		password: o.password,
+		o:        o,
+
+		capabilities: o.capabilities(CapSpectate),
+	}
+	hasAI := false
+	for _, cc := range clients {
+		if cc.strategy != nil {
+			hasAI = true
+			continue
+		}
+		g.coach = g.coach && cc.coach
+		g.sess.add(cc)
+	}
+	if hasAI {
+		game.OnEvent(g.showAIStep)
+	}
+	g.sess.setState(game.GetState())
+	return g
+}
+
+// broadcast sends msg to every player and spectator.
+func (g *serverGame) broadcast(msg *Message) {
+	broadcast(g.clients, msg)
+	g.watchers.broadcast(msg)
+}
+
+// notify sends a notice to everyone but the player at excludeIdx.
+func (g *serverGame) notify(text string, excludeIdx int) {
+	errMsg := NewErrorMsg(text)
+	for i, cc := range g.clients {
+		if i == excludeIdx {
+			continue
+		}
+		_ = writeToClient(cc, errMsg)
 	}
-	return clients, nil
+	g.watchers.broadcast(errMsg)
 }
 
-func readLoop(cc *clientConn, clients []*clientConn, clientIdx int) {
+func (g *serverGame) readLoop(cc *clientConn, conn net.Conn, clientIdx int) {
 	for {
-		msg, err := ReadMessage(cc.conn)
+		msg, err := ReadMessage(conn)
 		if err != nil {
-			log.Printf("[server] Player %s (%s) disconnected", cc.name, cc.playerID)
-			notifyDisconnect(clients, cc.name, clientIdx)
-			// Send nil to actionCh to signal disconnect
-			close(cc.actionCh)
+			g.dropped(cc, conn, clientIdx)
 			return
 		}
+		g.handle(cc, msg)
+	}
+}
 
-		switch msg.Type {
-		case MsgAction:
-			ap, err := DecodeAction(msg)
-			if err != nil {
-				_ = writeToClient(cc, NewErrorMsg(fmt.Sprintf("invalid action: %v", err)))
-				continue
-			}
-			log.Printf("[server] %s (%s): %s", cc.name, cc.playerID, ap.Action)
-			cc.actionCh <- ap
+// resumed announces that cc is back on conn and reads from it.
+func (g *serverGame) resumed(cc *clientConn, conn net.Conn) {
+	idx := clientIndex(g.clients, cc)
+	log.Printf("[server] Player %s (%s) reconnected", cc.name, cc.playerID)
+	g.notify(fmt.Sprintf("player %q reconnected", cc.name), idx)
+	g.readLoop(cc, conn, idx)
+}
+
+// dropped holds the seat of a player whose connection conn was lost;
+// actionCh is closed if the player doesn't resume.
+func (g *serverGame) dropped(cc *clientConn, conn net.Conn, clientIdx int) {
+	if g.sess.drop(cc, conn, func() {
+		log.Printf("[server] Player %s (%s) did not reconnect", cc.name, cc.playerID)
+		g.notify(fmt.Sprintf("player %q disconnected; an AI plays their remaining turns", cc.name), clientIdx)
+	}) {
+		log.Printf("[server] Player %s (%s) disconnected, holding seat for %s", cc.name, cc.playerID, g.sess.grace)
+		g.notify(fmt.Sprintf("player %q lost connection; holding their seat for %s", cc.name, g.sess.grace), clientIdx)
+	}
+}
+
+// handle processes a message from a seated player.
+func (g *serverGame) handle(cc *clientConn, msg *Message) {
+	switch msg.Type {
+	case MsgAction:
+		ap, err := DecodeAction(msg)
+		if err != nil {
+			_ = writeToClient(cc, NewErrorMsg(fmt.Sprintf("invalid action: %v", err)))
+			return
+		}
+		log.Printf("[server] %s (%s): %s", cc.name, cc.playerID, ap.Action)
+		cc.actionCh <- ap
 
-		case MsgChat:
-			// Broadcast chat to all clients
-			broadcast(clients, msg)
+	case MsgChat:
+		// Broadcast chat to all clients
+		g.broadcast(msg)
 
-		default:
-			_ = writeToClient(cc, NewErrorMsg(fmt.Sprintf("unexpected message type: %s", msg.Type)))
+	case MsgSeed:
+		sp, err := DecodeSeed(msg)
+		if err == nil && g.dealer != nil {
+			err = g.dealer.reveal(clientIndex(g.clients, cc), sp.Seed)
+		} else if err == nil {
+			err = fmt.Errorf("this game doesn't use verifiable dice")
 		}
+		if err != nil {
+			_ = writeToClient(cc, NewErrorMsg(fmt.Sprintf("invalid seed: %v", err)))
+		}
+
+	default:
+		_ = writeToClient(cc, NewErrorMsg(fmt.Sprintf("unexpected message type: %s", msg.Type)))
 	}
 }
 
-func gameLoop(game *engine.Game, clients []*clientConn) error {
+func (g *serverGame) run() error {
+	if g.dealer != nil {
+		if err := g.dealer.wait(seedTimeout); err != nil {
+			g.notify(err.Error(), -1)
+			return err
+		}
+	}
 	for {
-		state := game.GetState()
+		state := g.game.GetState()
+		g.sess.setState(state)
 		if state.Phase == engine.PhaseFinished {
 			log.Printf("[server] Game over")
 			for _, p := range state.Players {
 				log.Printf("[server]   %s: %d pts", p.Name, p.Scorecard.Total())
 			}
-			broadcast(clients, NewGameOverMsg(state))
+			g.broadcast(g.gameOverMsg(state))
 			return nil
 		}
 
 		currentIdx := state.CurrentPlayerIndex
-		cc := clients[currentIdx]
+		cc := g.clients[currentIdx]
 		log.Printf("[server] Round %d: %s's turn (%s)", state.Round, cc.name, cc.playerID)
 
-		// Send turn_start to current player
-		if err := writeToClient(cc, NewTurnStartMsg(state)); err != nil {
-			return fmt.Errorf("send turn_start to %s: %w", cc.playerID, err)
+		if cc.strategy != nil {
+			g.sess.setDeadline(time.Time{})
+			if err := g.playAITurn(cc); err != nil {
+				return err
+			}
+			continue
+		}
+
+		limit := g.clock.limitFor(cc.playerID)
+		var deadline time.Time
+		if limit > 0 {
+			deadline = time.Now().Add(limit)
 		}
+		g.sess.setDeadline(deadline)
+
+		// Send turn_start to current player. If their connection dropped,
+		// a resume re-sends it.
+		cc.dropStaleActions()
+		_ = writeToClient(cc, newTurnStartMsgWithClock(state, limit))
 
 		// Process actions from current player until they score
-		if err := processPlayerTurn(game, cc, clients); err != nil {
+		if err := g.playTurn(cc, limit); err != nil {
 			return err
 		}
 	}
 }
 
-func processPlayerTurn(game *engine.Game, cc *clientConn, clients []*clientConn) error {
+// playTurn applies the current player's actions until they score. If the
+// turn is timed (limit > 0) and the clock runs out, or the player left for
+// good, the rest of the turn is auto-played.
+func (g *serverGame) playTurn(cc *clientConn, limit time.Duration) error {
+	timeout, stop := turnTimer(limit)
+	defer stop()
+
 	for {
-		ap, ok := <-cc.actionCh
+		var ap *ActionPayload
+		var ok bool
+		select {
+		case ap, ok = <-cc.actionCh:
+		case <-timeout:
+			log.Printf("[server] %s (%s) ran out of time", cc.name, cc.playerID)
+			text := fmt.Sprintf("player %q ran out of time; their turn was auto-played", cc.name)
+			if g.clock.timedOut(cc.playerID) {
+				text = fmt.Sprintf("player %q is AFK; their turns are auto-played", cc.name)
+			}
+			g.notify(text, -1)
+			return g.autoPlayTurn(cc)
+		}
 		if !ok {
-			return fmt.Errorf("player %s disconnected", cc.playerID)
+			return g.autoPlayTurn(cc)
+		}
+		if g.clock.acted(cc.playerID) {
+			log.Printf("[server] %s (%s) is back", cc.name, cc.playerID)
+			g.notify(fmt.Sprintf("player %q is back", cc.name), -1)
 		}
 
 		var actionErr error
+		var held []int
 		switch ap.Action {
 		case ActionRoll:
-			actionErr = game.Roll()
+			actionErr = g.game.Roll()
 		case ActionHold:
-			actionErr = game.Hold(ap.Indices)
+			actionErr = g.game.Hold(ap.Indices)
+			held = ap.Indices
 		case ActionScore:
-			actionErr = game.Score(engine.Category(ap.Category))
+			actionErr = g.game.Score(engine.Category(ap.Category))
 		default:
 			actionErr = fmt.Errorf("unknown action: %s", ap.Action)
 		}
 
 		if actionErr != nil {
-			if err := writeToClient(cc, NewErrorMsg(actionErr.Error())); err != nil {
-				return fmt.Errorf("send error to %s: %w", cc.playerID, err)
-			}
+			_ = writeToClient(cc, NewErrorMsg(actionErr.Error()))
 			continue
 		}
 
-		state := game.GetState()
-		broadcast(clients, NewStateUpdateMsg(state))
+		state := g.game.GetState()
+		g.sess.setState(state)
+		g.broadcast(newStateUpdateMsgWithHeld(state, held))
 
 		if state.Phase == engine.PhaseFinished {
-			broadcast(clients, NewGameOverMsg(state))
+			g.broadcast(g.gameOverMsg(state))
 			return nil
 		}
 
@@ -206,18 +449,133 @@ func processPlayerTurn(game *engine.Game, cc *clientConn, clients []*clientConn)
 	}
 }
 
+// autoPlayTurn finishes the current turn of a player who left or ran out of
+// time, with the turn clock's strategy if there is one.
+func (g *serverGame) autoPlayTurn(cc *clientConn) error {
+	if g.clock != nil {
+		if err := g.clock.autoPlay(g.game, cc.playerID); err != nil {
+			return err
+		}
+	} else if _, err := engine.NewAIPlayer(g.game, cc.playerID).PlayTurn(); err != nil {
+		return fmt.Errorf("AI turn for %s: %w", cc.playerID, err)
+	}
+	g.publishTurn()
+	return nil
+}
+
+// playAITurn plays the turn of an AI seat. Its rolls and holds are
+// broadcast as they happen by showAIStep.
+func (g *serverGame) playAITurn(cc *clientConn) error {
+	time.Sleep(g.aiDelay)
+	if _, err := engine.NewAIPlayerWithStrategy(g.game, cc.playerID, cc.strategy).PlayTurn(); err != nil {
+		return fmt.Errorf("AI turn for %s: %w", cc.playerID, err)
+	}
+	g.publishTurn()
+	return nil
+}
+
+// showAIStep broadcasts each roll and hold of an AI seat, then pauses so
+// remote players can follow along. It is registered with Game.OnEvent.
+func (g *serverGame) showAIStep(e engine.Event) {
+	if e.Type != engine.EventRoll && e.Type != engine.EventHold {
+		return
+	}
+	for _, cc := range g.clients {
+		if cc.playerID == e.Player && cc.strategy != nil {
+			state := g.game.GetState()
+			g.sess.setState(state)
+			g.broadcast(newStateUpdateMsgWithHeld(state, e.Held))
+			time.Sleep(g.aiDelay)
+			return
+		}
+	}
+}
+
+// publishTurn broadcasts the state after a turn played by the server, and
+// game_over if it was the last.
+func (g *serverGame) publishTurn() {
+	state := g.game.GetState()
+	g.sess.setState(state)
+	g.broadcast(NewStateUpdateMsg(state))
+	if state.Phase == engine.PhaseFinished {
+		g.broadcast(g.gameOverMsg(state))
+	}
+}
+
+// recordGame records the finished game, with the strategies of its AI
+// seats.
+func (g *serverGame) recordGame() {
+	strategies := make(map[string]string)
+	for _, cc := range g.clients {
+		if cc.strategy != nil {
+			strategies[cc.playerID] = cc.strategy.Name()
+		}
+	}
+	g.o.recordGame(stats.ModeServer, g.game.GetState(), "", strategies)
+}
+
+// rateGame rates the finished game between its signed-in players.
+func (g *serverGame) rateGame() {
+	rated := make(map[string]bool)
+	for _, cc := range g.clients {
+		rated[cc.playerID] = cc.rated
+	}
+	g.o.rateGame(g.game.GetState(), rated)
+}
+
+// gameStartMsg announces the game, with whether hints were agreed on and
+// the dice commitments.
+func (g *serverGame) gameStartMsg() *Message {
+	return newMessage(MsgGameStart, StatePayload{State: g.game.GetState(), Coach: g.coach, FairDice: g.dealer.payload(false)})
+}
+
+// gameOverMsg ends the game, revealing the dice seeds.
+func (g *serverGame) gameOverMsg(state engine.GameState) *Message {
+	return newMessage(MsgGameOver, StatePayload{State: state, FairDice: g.dealer.payload(true)})
+}
+
 // RunServer accepts numPlayers TCP connections, runs a headless Yahtzee game,
-// and broadcasts state updates to all clients.
-func RunServer(ln net.Listener, numPlayers int, rngSrc rand.Source) error {
-	clients, err := acceptClients(ln, numPlayers)
+// and broadcasts state updates to all clients. Seats added with WithAISeats
+// count towards numPlayers and are played in-process. While the game runs,
+// ln keeps accepting resume connections from players whose connection
+// dropped (see WithGracePeriod). Spectators (see Watch) may connect at any
+// time; they get every broadcast but cannot act. Turns are untimed unless
+// WithTurnClock is given.
+func RunServer(ln net.Listener, numPlayers int, rngSrc rand.Source, opts ...Option) error {
+	o := newOptions(opts)
+	remote := numPlayers - len(o.aiSeats)
+	if remote < 1 {
+		return fmt.Errorf("%d players with %d AI seats leaves no seat for a remote player", numPlayers, len(o.aiSeats))
+	}
+
+	ln = o.listen(ln)
+	clients, spectators, err := acceptClients(ln, remote, &o)
 	if err != nil {
 		return fmt.Errorf("accept clients: %w", err)
 	}
 	defer func() {
 		for _, cc := range clients {
-			cc.conn.Close()
+			if cc.conn != nil {
+				cc.conn.Close()
+			}
 		}
 	}()
+	var dealer *fairDealer
+	if o.fairDice {
+		dealer = newFairDealer(len(clients) + len(o.aiSeats))
+		for i, cc := range clients {
+			dealer.commit(i, cc.commitment)
+		}
+		rngSrc = dealer
+	}
+	for _, seat := range o.aiSeats {
+		if dealer != nil {
+			dealer.pick(len(clients))
+		}
+		cc := &clientConn{name: seat.Name, playerID: fmt.Sprintf("player-%d", len(clients)), strategy: seat.Strategy}
+		clients = append(clients, cc)
+		log.Printf("[server] AI %s (%s) seated as %s", seat.Name, seat.Strategy.Name(), cc.playerID)
+	}
 
 	// Build player names list
 	names := make([]string, len(clients))
@@ -225,17 +583,42 @@ func RunServer(ln net.Listener, numPlayers int, rngSrc rand.Source) error {
 		names[i] = cc.name
 	}
 
-	game := engine.NewGame(names, rngSrc)
-	log.Printf("[server] Game started with %d players: %v", len(names), names)
+	game := engine.NewGameWithRules(names, rngSrc, o.rules)
+	log.Printf("[server] Game started with %d players (%s rules): %v", len(names), o.rules.Name(), names)
+
+	g := newServerGame(game, clients, &o)
+	g.dealer = dealer
+	defer g.watchers.closeAll()
+	defer g.sess.end()
 
 	// Broadcast game_start
-	state := game.GetState()
-	broadcast(clients, NewGameStartMsg(state))
+	for _, cc := range spectators {
+		g.watchers.add(cc)
+		go g.watchLoop(cc)
+	}
+	g.broadcast(g.gameStartMsg())
 
 	// Start per-client reader goroutines
 	for i, cc := range clients {
-		go readLoop(cc, clients, i)
+		if cc.strategy == nil {
+			go g.readLoop(cc, cc.conn, i)
+		}
 	}
+	go acceptResumes(ln, g.sess, g.resumed, g.watch)
 
-	return gameLoop(game, clients)
+	if err := g.run(); err != nil {
+		return err
+	}
+	g.recordGame()
+	g.rateGame()
+	return nil
+}
+
+func clientIndex(clients []*clientConn, cc *clientConn) int {
+	for i, c := range clients {
+		if c == cc {
+			return i
+		}
+	}
+	return -1
 }
@@ -145,3 +145,90 @@ func TestE2E_FullGameViaServer(t *testing.T) {
 		t.Logf("Player %s: %d points", p.Name, p.Scorecard.Total())
 	}
 }
+
+func TestE2E_RemoteClientResumesAfterDrop(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping E2E test in short mode")
+	}
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	defer ln.Close()
+
+	go RunServer(ln, 2, rand.NewSource(42), WithGracePeriod(5*time.Second))
+
+	type connectResult struct {
+		rc  *RemoteClient
+		err error
+	}
+	ch := make(chan connectResult, 2)
+	for _, name := range []string{"Alice", "Bob"} {
+		go func(name string) {
+			rc, err := NewRemoteClient(ln.Addr().String(), name)
+			ch <- connectResult{rc, err}
+		}(name)
+	}
+	var clients []*RemoteClient
+	for range 2 {
+		r := <-ch
+		if r.err != nil {
+			t.Fatalf("connect: %v", r.err)
+		}
+		defer r.rc.Close()
+		clients = append(clients, r.rc)
+	}
+	first, other := clients[0], clients[1]
+	if first.PlayerID() != "player-0" {
+		first, other = other, first
+	}
+
+	if _, _, err := first.WaitForTurn(); err != nil {
+		t.Fatalf("WaitForTurn: %v", err)
+	}
+
+	// Cut the connection under the client; it should redial and resume.
+	first.currentConn().Close()
+	deadline := time.After(5 * time.Second)
+	for reconnected := false; !reconnected; {
+		select {
+		case cp := <-first.ChatCh():
+			reconnected = cp.Text == "reconnected"
+		case <-deadline:
+			t.Fatal("client did not reconnect")
+		}
+	}
+
+	state, err := first.Roll()
+	if err != nil {
+		t.Fatalf("Roll after resume: %v", err)
+	}
+	if state.RollCount != 1 {
+		t.Fatalf("expected roll count 1, got %d", state.RollCount)
+	}
+
+	// Alice's Score returns once Bob has played his turn.
+	scoreDone := make(chan error, 1)
+	go func() {
+		_, err := first.Score(state.AvailableCategories[0])
+		scoreDone <- err
+	}()
+	if _, _, err := other.WaitForTurn(); err != nil {
+		t.Fatalf("Bob WaitForTurn: %v", err)
+	}
+	gs, err := other.Roll()
+	if err != nil {
+		t.Fatalf("Bob Roll: %v", err)
+	}
+	// Bob's Score blocks until his next turn, which never comes here.
+	go other.Score(gs.AvailableCategories[0])
+	select {
+	case err := <-scoreDone:
+		if err != nil {
+			t.Fatalf("Alice Score: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Alice's Score did not return")
+	}
+}
@@ -3,6 +3,7 @@ package p2p
 import (
 	"math/rand"
 	"net"
+	"strings"
 	"testing"
 	"time"
 
@@ -10,6 +11,14 @@ import (
 )
 
 func connectAndHandshake(t *testing.T, addr, name string) (net.Conn, string) {
+	t.Helper()
+	conn, hs := connectWithSession(t, addr, name)
+	return conn, hs.PlayerID
+}
+
+// connectWithSession is connectAndHandshake returning the whole handshake
+// reply, including the session token.
+func connectWithSession(t *testing.T, addr, name string) (net.Conn, *HandshakePayload) {
 	t.Helper()
 	conn, err := net.Dial("tcp", addr)
 	if err != nil {
@@ -30,7 +39,7 @@ func connectAndHandshake(t *testing.T, addr, name string) (net.Conn, string) {
 		t.Fatalf("decode handshake: %v", err)
 	}
 
-	return conn, hs.PlayerID
+	return conn, hs
 }
 
 func readExpectType(t *testing.T, conn net.Conn, expectedType string) *Message {
@@ -105,6 +114,54 @@ func TestServer_Handshake(t *testing.T) {
 	conn2.Close()
 }
 
+func TestServer_Password(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping server test in short mode")
+	}
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	defer ln.Close()
+	addr := ln.Addr().String()
+	go RunServer(ln, 2, rand.NewSource(42), WithPassword("s3cret"))
+
+	join := func(name, password string) net.Conn {
+		conn, err := net.Dial("tcp", addr)
+		if err != nil {
+			t.Fatalf("dial: %v", err)
+		}
+		hello := newHello(HandshakePayload{Name: name, Password: password})
+		if err := WriteMessage(conn, hello); err != nil {
+			t.Fatalf("send handshake: %v", err)
+		}
+		return conn
+	}
+
+	// A wrong password is turned away without taking a seat.
+	intruder := join("Mallory", "guess")
+	defer intruder.Close()
+	ep, _ := DecodeError(readExpectType(t, intruder, MsgError))
+	if ep == nil || !strings.Contains(ep.Message, "password") {
+		t.Errorf("rejection = %+v, want a password error", ep)
+	}
+
+	alice := join("Alice", "s3cret")
+	defer alice.Close()
+	bob := join("Bob", "s3cret")
+	defer bob.Close()
+	hs, _ := DecodeHandshake(readExpectType(t, alice, MsgHandshake))
+	if hs.PlayerID != "player-0" {
+		t.Errorf("Alice got %s, want player-0", hs.PlayerID)
+	}
+	readExpectType(t, bob, MsgHandshake)
+	sp, _ := DecodeState(readExpectType(t, bob, MsgGameStart))
+	if sp.State.Players[1].Name != "Bob" {
+		t.Errorf("players = %+v", sp.State.Players)
+	}
+}
+
 func TestServer_TurnManagement(t *testing.T) {
 	if testing.Short() {
 		t.Skip("skipping server test in short mode")
@@ -375,3 +432,534 @@ func TestServer_FullGame(t *testing.T) {
 	}
 	t.Fatal("game did not finish after all rounds")
 }
+
+func TestServer_CoachRequiresEveryPlayer(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping server test in short mode")
+	}
+
+	tests := []struct {
+		name  string
+		coach [2]bool
+		want  bool
+	}{
+		{"both agree", [2]bool{true, true}, true},
+		{"one declines", [2]bool{true, false}, false},
+		{"neither", [2]bool{false, false}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ln, err := net.Listen("tcp", "127.0.0.1:0")
+			if err != nil {
+				t.Fatalf("listen: %v", err)
+			}
+			defer ln.Close()
+			go RunServer(ln, 2, rand.NewSource(42))
+
+			type connectResult struct {
+				rc  *RemoteClient
+				err error
+			}
+			results := make(chan connectResult, 2)
+			for i, name := range []string{"Alice", "Bob"} {
+				var opts []Option
+				if tt.coach[i] {
+					opts = append(opts, WithCoach(&engine.StatisticalStrategy{}))
+				}
+				go func(name string, opts []Option) {
+					rc, err := NewRemoteClient(ln.Addr().String(), name, opts...)
+					results <- connectResult{rc, err}
+				}(name, opts)
+			}
+			for i := 0; i < 2; i++ {
+				r := <-results
+				if r.err != nil {
+					t.Fatalf("connect: %v", r.err)
+				}
+				defer r.rc.Close()
+				if got := r.rc.CoachEnabled(); got != tt.want {
+					t.Errorf("%s: CoachEnabled() = %v, want %v", r.rc.playerName, got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func expectNotice(t *testing.T, conn net.Conn, substr string) {
+	t.Helper()
+	ep, _ := DecodeError(readExpectType(t, conn, MsgError))
+	if !strings.Contains(ep.Message, substr) {
+		t.Fatalf("expected notice containing %q, got %q", substr, ep.Message)
+	}
+}
+
+func TestServer_ResumeAfterDisconnect(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping server test in short mode")
+	}
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	defer ln.Close()
+	addr := ln.Addr().String()
+
+	go RunServer(ln, 2, rand.NewSource(42), WithGracePeriod(5*time.Second))
+
+	conn1, hs1 := connectWithSession(t, addr, "Alice")
+	conn2, _ := connectWithSession(t, addr, "Bob")
+	defer conn2.Close()
+	if hs1.Token == "" {
+		t.Fatal("expected a session token in the handshake")
+	}
+
+	readExpectType(t, conn1, MsgGameStart)
+	readExpectType(t, conn2, MsgGameStart)
+	readExpectType(t, conn1, MsgTurnStart)
+
+	// Alice drops during her turn; Bob is told her seat is held.
+	conn1.Close()
+	expectNotice(t, conn2, "lost connection")
+
+	conn1, err = net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatalf("redial: %v", err)
+	}
+	defer conn1.Close()
+	if err := WriteMessage(conn1, NewResumeMsg(hs1.Token)); err != nil {
+		t.Fatalf("send resume: %v", err)
+	}
+	rp, err := DecodeResume(readExpectType(t, conn1, MsgResume))
+	if err != nil {
+		t.Fatalf("decode resume: %v", err)
+	}
+	if rp.PlayerID != "player-0" || rp.State == nil || rp.State.CurrentPlayer != "player-0" {
+		t.Fatalf("unexpected resume reply: %+v", rp)
+	}
+	readExpectType(t, conn1, MsgTurnStart)
+	expectNotice(t, conn2, "reconnected")
+
+	// The game carries on from the same turn.
+	if err := WriteMessage(conn1, NewActionMsg(ActionPayload{Action: ActionRoll})); err != nil {
+		t.Fatalf("send roll: %v", err)
+	}
+	sp, _ := DecodeState(readExpectType(t, conn1, MsgStateUpdate))
+	if sp.State.RollCount != 1 {
+		t.Errorf("expected roll count 1, got %d", sp.State.RollCount)
+	}
+	readExpectType(t, conn2, MsgStateUpdate)
+}
+
+func TestServer_ResumeAfterGracePeriod(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping server test in short mode")
+	}
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	defer ln.Close()
+	addr := ln.Addr().String()
+
+	go RunServer(ln, 2, rand.NewSource(42), WithGracePeriod(50*time.Millisecond))
+
+	conn1, _ := connectWithSession(t, addr, "Alice")
+	defer conn1.Close()
+	conn2, hs2 := connectWithSession(t, addr, "Bob")
+
+	readExpectType(t, conn1, MsgGameStart)
+	readExpectType(t, conn2, MsgGameStart)
+	readExpectType(t, conn1, MsgTurnStart)
+
+	conn2.Close()
+	expectNotice(t, conn1, "lost connection")
+	expectNotice(t, conn1, "disconnected")
+
+	conn2, err = net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatalf("redial: %v", err)
+	}
+	defer conn2.Close()
+	if err := WriteMessage(conn2, NewResumeMsg(hs2.Token)); err != nil {
+		t.Fatalf("send resume: %v", err)
+	}
+	expectNotice(t, conn2, "expired")
+}
+
+func TestServer_AIFinishesForDroppedPlayer(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping server test in short mode")
+	}
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	defer ln.Close()
+	addr := ln.Addr().String()
+
+	go RunServer(ln, 2, rand.NewSource(42), WithGracePeriod(50*time.Millisecond))
+
+	conn1, _ := connectAndHandshake(t, addr, "Alice")
+	defer conn1.Close()
+	conn2, _ := connectAndHandshake(t, addr, "Bob")
+
+	readExpectType(t, conn1, MsgGameStart)
+	readExpectType(t, conn2, MsgGameStart)
+	readExpectType(t, conn1, MsgTurnStart)
+
+	conn2.Close()
+	expectNotice(t, conn1, "lost connection")
+	expectNotice(t, conn1, "an AI plays their remaining turns")
+
+	// Alice plays out the game; Bob's turns are played for him.
+	send := func(ap ActionPayload) {
+		t.Helper()
+		if err := WriteMessage(conn1, NewActionMsg(ap)); err != nil {
+			t.Fatalf("send %s: %v", ap.Action, err)
+		}
+	}
+	send(ActionPayload{Action: ActionRoll})
+	for {
+		conn1.SetDeadline(time.Now().Add(5 * time.Second))
+		msg, err := ReadMessage(conn1)
+		if err != nil {
+			t.Fatalf("read: %v", err)
+		}
+		switch msg.Type {
+		case MsgTurnStart:
+			send(ActionPayload{Action: ActionRoll})
+		case MsgStateUpdate:
+			sp, _ := DecodeState(msg)
+			if sp.State.CurrentPlayer == "player-0" && sp.State.RollCount > 0 && sp.State.Phase != engine.PhaseFinished {
+				send(ActionPayload{Action: ActionScore, Category: string(sp.State.AvailableCategories[0])})
+			}
+		case MsgError:
+			ep, _ := DecodeError(msg)
+			t.Fatalf("unexpected error: %s", ep.Message)
+		case MsgGameOver:
+			return
+		}
+	}
+}
+
+func TestServer_TurnClock(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping server test in short mode")
+	}
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	defer ln.Close()
+	addr := ln.Addr().String()
+
+	go RunServer(ln, 2, rand.NewSource(42), WithTurnClock(100*time.Millisecond, &engine.GreedyStrategy{}))
+
+	conn1, _ := connectAndHandshake(t, addr, "Alice")
+	defer conn1.Close()
+	conn2, _ := connectAndHandshake(t, addr, "Bob")
+	defer conn2.Close()
+	readExpectType(t, conn1, MsgGameStart)
+	readExpectType(t, conn2, MsgGameStart)
+
+	// Bob plays his turn right away.
+	playBobTurn := func() {
+		t.Helper()
+		readExpectType(t, conn2, MsgTurnStart)
+		if err := WriteMessage(conn2, NewActionMsg(ActionPayload{Action: ActionRoll})); err != nil {
+			t.Fatalf("send roll: %v", err)
+		}
+		sp, _ := DecodeState(readExpectType(t, conn2, MsgStateUpdate))
+		readExpectType(t, conn1, MsgStateUpdate)
+		score := ActionPayload{Action: ActionScore, Category: string(sp.State.AvailableCategories[0])}
+		if err := WriteMessage(conn2, NewActionMsg(score)); err != nil {
+			t.Fatalf("send score: %v", err)
+		}
+		readExpectType(t, conn2, MsgStateUpdate)
+		readExpectType(t, conn1, MsgStateUpdate)
+	}
+
+	// Alice sits on two turns in a row: both are auto-played and she is
+	// marked AFK after the second.
+	for _, want := range []string{"ran out of time", "is AFK"} {
+		sp, _ := DecodeState(readExpectType(t, conn1, MsgTurnStart))
+		if sp.TimeLeftMs != 100 {
+			t.Fatalf("expected 100ms on the clock, got %d", sp.TimeLeftMs)
+		}
+		expectNotice(t, conn1, want)
+		expectNotice(t, conn2, want)
+		st, _ := DecodeState(readExpectType(t, conn1, MsgStateUpdate))
+		if st.State.CurrentPlayer != "player-1" {
+			t.Fatalf("expected Bob's turn after auto-play, got %s", st.State.CurrentPlayer)
+		}
+		readExpectType(t, conn2, MsgStateUpdate)
+		playBobTurn()
+	}
+
+	// Acting again clears the AFK mark.
+	readExpectType(t, conn1, MsgTurnStart)
+	if err := WriteMessage(conn1, NewActionMsg(ActionPayload{Action: ActionRoll})); err != nil {
+		t.Fatalf("send roll: %v", err)
+	}
+	expectNotice(t, conn2, "is back")
+}
+
+func TestServer_LateActionsDropped(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping server test in short mode")
+	}
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	defer ln.Close()
+	addr := ln.Addr().String()
+
+	go RunServer(ln, 2, rand.NewSource(42), WithTurnClock(100*time.Millisecond, &engine.GreedyStrategy{}))
+
+	conn1, _ := connectAndHandshake(t, addr, "Alice")
+	defer conn1.Close()
+	conn2, _ := connectAndHandshake(t, addr, "Bob")
+	defer conn2.Close()
+	readExpectType(t, conn1, MsgGameStart)
+	readExpectType(t, conn2, MsgGameStart)
+
+	// Alice's turn runs out, then her roll arrives.
+	readExpectType(t, conn1, MsgTurnStart)
+	expectNotice(t, conn1, "ran out of time")
+	expectNotice(t, conn2, "ran out of time")
+	readExpectType(t, conn1, MsgStateUpdate)
+	readExpectType(t, conn2, MsgStateUpdate)
+	if err := WriteMessage(conn1, NewActionMsg(ActionPayload{Action: ActionRoll})); err != nil {
+		t.Fatalf("send roll: %v", err)
+	}
+
+	// Bob plays his turn.
+	readExpectType(t, conn2, MsgTurnStart)
+	if err := WriteMessage(conn2, NewActionMsg(ActionPayload{Action: ActionRoll})); err != nil {
+		t.Fatalf("send roll: %v", err)
+	}
+	sp, _ := DecodeState(readExpectType(t, conn2, MsgStateUpdate))
+	readExpectType(t, conn1, MsgStateUpdate)
+	score := ActionPayload{Action: ActionScore, Category: string(sp.State.AvailableCategories[0])}
+	if err := WriteMessage(conn2, NewActionMsg(score)); err != nil {
+		t.Fatalf("send score: %v", err)
+	}
+	readExpectType(t, conn2, MsgStateUpdate)
+	readExpectType(t, conn1, MsgStateUpdate)
+
+	// The late roll isn't played on Alice's next turn, which runs out too.
+	readExpectType(t, conn1, MsgTurnStart)
+	expectNotice(t, conn1, "is AFK")
+}
+
+func TestServer_Spectators(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping server test in short mode")
+	}
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	defer ln.Close()
+	addr := ln.Addr().String()
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- RunServer(ln, 2, rand.NewSource(42))
+	}()
+
+	// A spectator who connects before the players doesn't take a seat.
+	spec, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	defer spec.Close()
+	if err := WriteMessage(spec, NewSpectateMsg("Eve")); err != nil {
+		t.Fatalf("send handshake: %v", err)
+	}
+	hs, _ := DecodeHandshake(readExpectType(t, spec, MsgHandshake))
+	if hs.Role != RoleSpectator || hs.PlayerID != "" {
+		t.Fatalf("spectator handshake reply = %+v", hs)
+	}
+
+	conn1, pid1 := connectAndHandshake(t, addr, "Alice")
+	defer conn1.Close()
+	conn2, pid2 := connectAndHandshake(t, addr, "Bob")
+	defer conn2.Close()
+	if pid1 != "player-0" || pid2 != "player-1" {
+		t.Fatalf("player IDs = %s, %s", pid1, pid2)
+	}
+
+	sp, _ := DecodeState(readExpectType(t, spec, MsgGameStart))
+	if len(sp.State.Players) != 2 {
+		t.Fatalf("spectator game_start has %d players, want 2", len(sp.State.Players))
+	}
+	readExpectType(t, conn1, MsgGameStart)
+	readExpectType(t, conn2, MsgGameStart)
+	readExpectType(t, conn1, MsgTurnStart)
+
+	// Spectators can't act, but can chat.
+	if err := WriteMessage(spec, NewActionMsg(ActionPayload{Action: ActionRoll})); err != nil {
+		t.Fatalf("send action: %v", err)
+	}
+	ep, _ := DecodeError(readExpectType(t, spec, MsgError))
+	if !strings.Contains(ep.Message, "spectators cannot act") {
+		t.Errorf("action error = %q", ep.Message)
+	}
+	if err := WriteMessage(spec, NewChatMsg("", "Eve", "go Alice")); err != nil {
+		t.Fatalf("send chat: %v", err)
+	}
+	cp, _ := DecodeChat(readExpectType(t, conn1, MsgChat))
+	if cp.Name != "Eve" || cp.Text != "go Alice" {
+		t.Errorf("chat = %+v", cp)
+	}
+	readExpectType(t, conn2, MsgChat)
+	readExpectType(t, spec, MsgChat)
+
+	// Moves reach the spectator.
+	if err := WriteMessage(conn1, NewActionMsg(ActionPayload{Action: ActionRoll})); err != nil {
+		t.Fatalf("roll: %v", err)
+	}
+	readExpectType(t, conn1, MsgStateUpdate)
+	readExpectType(t, conn2, MsgStateUpdate)
+	sp, _ = DecodeState(readExpectType(t, spec, MsgStateUpdate))
+	if sp.State.RollCount != 1 {
+		t.Errorf("spectator state RollCount = %d, want 1", sp.State.RollCount)
+	}
+	spec.Close()
+
+	// A late spectator starts from the current state and follows the game
+	// to the end.
+	w, err := Watch(addr, "Mallory")
+	if err != nil {
+		t.Fatalf("watch: %v", err)
+	}
+	defer w.Close()
+	watched := make(chan []*engine.GameState, 1)
+	go func() {
+		var states []*engine.GameState
+		for gs := range w.States() {
+			states = append(states, gs)
+		}
+		watched <- states
+	}()
+
+	// Play out the game: every turn rolls once and scores the first open
+	// category.
+	conns := [2]net.Conn{conn1, conn2}
+	rolled := sp.State
+	for turn := 0; ; turn++ {
+		current, other := conns[turn%2], conns[1-turn%2]
+		if turn > 0 {
+			readExpectType(t, current, MsgTurnStart)
+			if err := WriteMessage(current, NewActionMsg(ActionPayload{Action: ActionRoll})); err != nil {
+				t.Fatalf("turn %d roll: %v", turn, err)
+			}
+			rp, _ := DecodeState(readExpectType(t, current, MsgStateUpdate))
+			readExpectType(t, other, MsgStateUpdate)
+			rolled = rp.State
+		}
+		cat := rolled.AvailableCategories[0]
+		if err := WriteMessage(current, NewActionMsg(ActionPayload{Action: ActionScore, Category: string(cat)})); err != nil {
+			t.Fatalf("turn %d score: %v", turn, err)
+		}
+		scored, _ := DecodeState(readExpectType(t, current, MsgStateUpdate))
+		readExpectType(t, other, MsgStateUpdate)
+		if scored.State.Phase == engine.PhaseFinished {
+			readExpectType(t, current, MsgGameOver)
+			readExpectType(t, other, MsgGameOver)
+			break
+		}
+	}
+
+	states := <-watched
+	if len(states) == 0 || states[0].RollCount != 1 {
+		t.Fatalf("late spectator's first state should be mid-turn, got %d states", len(states))
+	}
+	if last := states[len(states)-1]; last.Phase != engine.PhaseFinished {
+		t.Errorf("late spectator's last state phase = %v, want finished", last.Phase)
+	}
+	if err := <-w.Done(); err != nil {
+		t.Errorf("watch ended with %v, want nil after game_over", err)
+	}
+	if err := <-errCh; err != nil {
+		t.Fatalf("server error: %v", err)
+	}
+}
+
+func TestServer_AISeats(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping server test in short mode")
+	}
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	defer ln.Close()
+	addr := ln.Addr().String()
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- RunServer(ln, 2, rand.NewSource(42),
+			WithAISeats(AISeat{Name: "Bot", Strategy: &engine.GreedyStrategy{}}),
+			WithAIDelay(time.Millisecond))
+	}()
+
+	// One remote player fills the table.
+	conn, pid := connectAndHandshake(t, addr, "Alice")
+	defer conn.Close()
+	if pid != "player-0" {
+		t.Fatalf("player ID = %s, want player-0", pid)
+	}
+	sp, _ := DecodeState(readExpectType(t, conn, MsgGameStart))
+	if len(sp.State.Players) != 2 || sp.State.Players[1].Name != "Bot" {
+		t.Fatalf("players = %+v, want Alice and Bot", sp.State.Players)
+	}
+
+	for turn := 0; ; turn++ {
+		msg := readExpectType(t, conn, MsgTurnStart)
+		ts, _ := DecodeState(msg)
+		if err := WriteMessage(conn, NewActionMsg(ActionPayload{Action: ActionRoll})); err != nil {
+			t.Fatalf("roll: %v", err)
+		}
+		readExpectType(t, conn, MsgStateUpdate)
+		if err := WriteMessage(conn, NewActionMsg(ActionPayload{Action: ActionScore, Category: string(ts.State.AvailableCategories[0])})); err != nil {
+			t.Fatalf("score: %v", err)
+		}
+		readExpectType(t, conn, MsgStateUpdate)
+
+		// The AI's turn arrives step by step: at least its roll and its
+		// score, each from the AI's seat.
+		var steps int
+		for {
+			upd := readExpectType(t, conn, MsgStateUpdate)
+			us, _ := DecodeState(upd)
+			if us.State.CurrentPlayer != "player-1" {
+				// The score ended the AI's turn.
+				if us.State.Phase == engine.PhaseFinished {
+					readExpectType(t, conn, MsgGameOver)
+					if steps < 1 {
+						t.Errorf("last AI turn showed %d steps before scoring", steps)
+					}
+					if err := <-errCh; err != nil {
+						t.Fatalf("server error: %v", err)
+					}
+					return
+				}
+				break
+			}
+			steps++
+		}
+		if steps < 1 {
+			t.Errorf("turn %d: AI turn showed %d steps before scoring", turn, steps)
+		}
+	}
+}
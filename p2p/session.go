@@ -0,0 +1,193 @@
+package p2p
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/edge2992/yatzcli/engine"
+)
+
+// DefaultGracePeriod is how long a dropped player's seat is held for a
+// resume, and how long RemoteClient keeps redialing.
+const DefaultGracePeriod = 60 * time.Second
+
+var errSessionExpired = errors.New("unknown or expired session")
+
+// newSessionToken returns a random token identifying a seat.
+func newSessionToken() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		panic(fmt.Sprintf("session token: %v", err))
+	}
+	return hex.EncodeToString(b)
+}
+
+// sessions holds seats across dropped connections. When a player's
+// connection is lost the seat is kept for the grace period; a client that
+// sends a resume message with the seat's token within that time gets the
+// seat back along with the latest state. Otherwise the seat's action
+// channel is closed, as if the player had left for good.
+type sessions struct {
+	grace time.Duration
+
+	mu    sync.Mutex
+	seats map[string]*clientConn // by token
+	state engine.GameState
+	// deadline is when the current turn's clock runs out; zero if untimed.
+	deadline time.Time
+	ended    bool
+}
+
+func newSessions(grace time.Duration) *sessions {
+	return &sessions{grace: grace, seats: make(map[string]*clientConn)}
+}
+
+// add holds cc's seat under its token.
+func (s *sessions) add(cc *clientConn) {
+	s.mu.Lock()
+	s.seats[cc.token] = cc
+	s.mu.Unlock()
+}
+
+// setState records the latest state for resuming clients.
+func (s *sessions) setState(gs engine.GameState) {
+	s.mu.Lock()
+	s.state = gs
+	s.mu.Unlock()
+}
+
+// setDeadline records when the current turn's clock runs out.
+func (s *sessions) setDeadline(t time.Time) {
+	s.mu.Lock()
+	s.deadline = t
+	s.mu.Unlock()
+}
+
+// currentState returns the latest state recorded with setState.
+func (s *sessions) currentState() engine.GameState {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.state
+}
+
+// end stops holding seats once the game is over.
+func (s *sessions) end() {
+	s.mu.Lock()
+	s.ended = true
+	s.mu.Unlock()
+}
+
+// drop marks the seat's connection conn as lost. It reports false when conn
+// is stale (the player already resumed on a new connection) or the game is
+// over. onExpire runs if the player does not resume within the grace period.
+func (s *sessions) drop(cc *clientConn, conn net.Conn, onExpire func()) bool {
+	s.mu.Lock()
+	if s.ended || cc.currentConn() != conn {
+		s.mu.Unlock()
+		return false
+	}
+	cc.dropped = true
+	cc.epoch++
+	epoch := cc.epoch
+	s.mu.Unlock()
+
+	time.AfterFunc(s.grace, func() {
+		s.mu.Lock()
+		expired := !s.ended && cc.dropped && cc.epoch == epoch
+		if expired {
+			delete(s.seats, cc.token)
+			close(cc.actionCh)
+		}
+		s.mu.Unlock()
+		if expired && onExpire != nil {
+			onExpire()
+		}
+	})
+	return true
+}
+
+// resume moves the seat identified by token onto conn. It answers with a
+// resume message carrying the current state, followed by turn_start when it
+// is the player's turn, before any other message can reach conn.
+func (s *sessions) resume(conn net.Conn, token string) (*clientConn, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	cc, ok := s.seats[token]
+	if !ok || s.ended {
+		return nil, errSessionExpired
+	}
+	state := s.state
+	var timeLeft time.Duration
+	if !s.deadline.IsZero() {
+		timeLeft = max(time.Until(s.deadline), time.Millisecond)
+	}
+
+	cc.mu.Lock()
+	old := cc.conn
+	cc.conn = conn
+	err := WriteMessage(conn, newMessage(MsgResume, ResumePayload{PlayerID: cc.playerID, State: &state}))
+	if err == nil && state.CurrentPlayer == cc.playerID && state.Phase != engine.PhaseFinished {
+		err = WriteMessage(conn, newTurnStartMsgWithClock(state, timeLeft))
+	}
+	cc.mu.Unlock()
+
+	// The old connection may still look alive if the client noticed the
+	// drop first; closing it makes its reader exit.
+	old.Close()
+	cc.dropped = false
+	cc.epoch++
+	if err != nil {
+		return nil, fmt.Errorf("send resume: %w", err)
+	}
+	return cc, nil
+}
+
+// acceptResumes accepts connections on ln until it is closed, handing
+// resumed seats to startReader. If watch is non-nil, spectator handshakes
+// are handed to it. Anyone else is told the game has started.
+func acceptResumes(ln net.Listener, s *sessions, startReader func(cc *clientConn, conn net.Conn), watch func(conn net.Conn, hs *HandshakePayload)) {
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		go func() {
+			conn.SetDeadline(time.Now().Add(30 * time.Second))
+			msg, err := ReadMessage(conn)
+			if err != nil {
+				conn.Close()
+				return
+			}
+			if watch != nil && msg.Type == MsgHandshake {
+				if hs, err := DecodeHandshake(msg); err == nil && hs.Role == RoleSpectator {
+					conn.SetDeadline(time.Time{})
+					watch(conn, hs)
+					return
+				}
+			}
+			if msg.Type != MsgResume {
+				_ = WriteMessage(conn, NewErrorMsg("game already in progress"))
+				conn.Close()
+				return
+			}
+			rp, err := DecodeResume(msg)
+			if err != nil {
+				conn.Close()
+				return
+			}
+			conn.SetDeadline(time.Time{})
+			cc, err := s.resume(conn, rp.Token)
+			if err != nil {
+				_ = WriteMessage(conn, NewErrorMsg(err.Error()))
+				conn.Close()
+				return
+			}
+			startReader(cc, conn)
+		}()
+	}
+}
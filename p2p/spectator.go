@@ -0,0 +1,316 @@
+package p2p
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"sync"
+
+	"github.com/edge2992/yatzcli/cli"
+	"github.com/edge2992/yatzcli/engine"
+)
+
+var errGameOver = errors.New("game is over")
+
+// watchers are the spectators of a game. Sends to them hold mu, so a late
+// spectator's catch-up can't interleave with a broadcast.
+type watchers struct {
+	mu     sync.Mutex
+	conns  []*clientConn
+	closed bool
+}
+
+func (w *watchers) add(cc *clientConn) {
+	w.mu.Lock()
+	w.conns = append(w.conns, cc)
+	w.mu.Unlock()
+}
+
+// join sends greet to cc and then adds it, so cc sees no broadcast before
+// its greeting.
+func (w *watchers) join(cc *clientConn, greet ...*Message) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		return errGameOver
+	}
+	for _, msg := range greet {
+		if err := writeToClient(cc, msg); err != nil {
+			return err
+		}
+	}
+	w.conns = append(w.conns, cc)
+	return nil
+}
+
+func (w *watchers) remove(cc *clientConn) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for i, c := range w.conns {
+		if c == cc {
+			w.conns = append(w.conns[:i], w.conns[i+1:]...)
+			return
+		}
+	}
+}
+
+func (w *watchers) broadcast(msg *Message) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	broadcast(w.conns, msg)
+}
+
+// closeAll disconnects every spectator and turns away later ones.
+func (w *watchers) closeAll() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.closed = true
+	for _, cc := range w.conns {
+		cc.conn.Close()
+	}
+	w.conns = nil
+}
+
+// watch seats a spectator who connected after the game started. They get
+// the current state as game_start, then every broadcast.
+func (g *serverGame) watch(conn net.Conn, hs *HandshakePayload) {
+	cc := newClientConn(conn, hs.Name, "")
+	state := g.sess.currentState()
+	err := checkHandshake(hs, g.password)
+	if err == nil && state.Phase == engine.PhaseFinished {
+		err = errGameOver
+	}
+	if err == nil {
+		f := negotiate(g.capabilities, hs)
+		err = g.watchers.join(cc,
+			handshakeReply(HandshakePayload{Name: "server", Role: RoleSpectator}, f),
+			newMessage(MsgGameStart, StatePayload{State: state, Coach: g.coach, FairDice: g.dealer.payload(false)}))
+	}
+	if err != nil {
+		_ = WriteMessage(conn, NewErrorMsg(err.Error()))
+		conn.Close()
+		return
+	}
+	log.Printf("[server] Spectator %s connected", cc.name)
+	g.watchLoop(cc)
+}
+
+// watchLoop reads from a spectator until they leave. Spectators may chat
+// but not act.
+func (g *serverGame) watchLoop(cc *clientConn) {
+	defer cc.conn.Close()
+	defer g.watchers.remove(cc)
+	for {
+		msg, err := ReadMessage(cc.conn)
+		if err != nil {
+			log.Printf("[server] Spectator %s left", cc.name)
+			return
+		}
+		switch msg.Type {
+		case MsgChat:
+			g.broadcast(msg)
+		case MsgAction:
+			_ = writeToClient(cc, NewErrorMsg("spectators cannot act"))
+		default:
+			_ = writeToClient(cc, NewErrorMsg(fmt.Sprintf("unexpected message type: %s", msg.Type)))
+		}
+	}
+}
+
+// Watcher is a read-only connection to a game run by RunServer.
+type Watcher struct {
+	conn   net.Conn
+	name   string
+	states chan *engine.GameState
+	chatCh chan *ChatPayload
+	done   chan error
+
+	features Features
+
+	// witness checks the dice of a game with verifiable dice, as
+	// RemoteClient does.
+	mu      sync.Mutex
+	witness *diceWitness
+	diceErr error
+}
+
+// Watch connects to the server at addr as a spectator. WithTLS and
+// WithPassword apply; other options are ignored.
+func Watch(addr, name string, opts ...Option) (*Watcher, error) {
+	o := newOptions(opts)
+	conn, err := dial(addr, 0, o.tlsConfig)
+	if err != nil {
+		return nil, fmt.Errorf("connect to server: %w", err)
+	}
+	return newWatcherFromConn(conn, name, o.password)
+}
+
+func newWatcherFromConn(conn net.Conn, name, password string) (*Watcher, error) {
+	hello := newHello(HandshakePayload{Name: name, Role: RoleSpectator, Password: password})
+	if err := WriteMessage(conn, hello); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("send handshake: %w", err)
+	}
+	msg, err := ReadMessage(conn)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("read handshake response: %w", err)
+	}
+	if msg.Type == MsgError {
+		conn.Close()
+		if ep, err := DecodeError(msg); err == nil {
+			return nil, fmt.Errorf("server refused: %s", ep.Message)
+		}
+		return nil, errors.New("server refused")
+	}
+	if msg.Type != MsgHandshake {
+		conn.Close()
+		return nil, fmt.Errorf("expected handshake response, got %s", msg.Type)
+	}
+	hs, err := DecodeHandshake(msg)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("decode handshake response: %w", err)
+	}
+	f, err := acceptedFeatures(hs)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	w := &Watcher{
+		conn:     conn,
+		name:     name,
+		features: f,
+		states:   make(chan *engine.GameState, 64),
+		chatCh:   make(chan *ChatPayload, 16),
+		done:     make(chan error, 1),
+		diceErr:  ErrDiceUnverified,
+	}
+	go w.listen()
+	return w, nil
+}
+
+func (w *Watcher) listen() {
+	var over bool
+	var err error
+	for {
+		var msg *Message
+		msg, err = ReadMessage(w.conn)
+		if err != nil {
+			break
+		}
+		switch msg.Type {
+		case MsgGameStart, MsgStateUpdate, MsgGameOver:
+			sp, derr := DecodeState(msg)
+			if derr != nil {
+				continue
+			}
+			over = over || msg.Type == MsgGameOver
+			w.checkDice(msg.Type, sp)
+			w.states <- &sp.State
+
+		case MsgChat:
+			if cp, derr := DecodeChat(msg); derr == nil {
+				w.chat(cp)
+			}
+
+		case MsgError:
+			if ep, derr := DecodeError(msg); derr == nil {
+				w.chat(&ChatPayload{Name: "*", Text: ep.Message})
+			}
+		}
+	}
+	if over {
+		err = nil
+	} else {
+		err = fmt.Errorf("connection lost: %w", err)
+	}
+	w.done <- err
+	close(w.states)
+	close(w.chatCh)
+}
+
+// checkDice follows the dice of a game with verifiable dice through msgType
+// messages, and checks them at game_over.
+func (w *Watcher) checkDice(msgType string, sp *StatePayload) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	switch {
+	case msgType == MsgGameStart && sp.FairDice != nil:
+		w.witness = newDiceWitness(sp.FairDice.Commitments)
+		w.witness.observe(&sp.State, nil, false)
+	case w.witness == nil:
+	case msgType == MsgStateUpdate:
+		w.witness.observe(&sp.State, sp.Held, true)
+	case msgType == MsgGameOver:
+		w.diceErr = w.witness.check(sp.FairDice)
+		w.chat(&ChatPayload{Name: "*", Text: w.witness.report(w.diceErr)})
+	}
+}
+
+// DiceCheck is RemoteClient.DiceCheck for a spectator.
+func (w *Watcher) DiceCheck() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.diceErr
+}
+
+func (w *Watcher) chat(cp *ChatPayload) {
+	select {
+	case w.chatCh <- cp:
+	default:
+	}
+}
+
+// States delivers game_start, every state update and the final state. It is
+// closed when the connection ends.
+func (w *Watcher) States() <-chan *engine.GameState {
+	return w.states
+}
+
+// ChatCh delivers chat messages and server notices.
+func (w *Watcher) ChatCh() <-chan *ChatPayload {
+	return w.chatCh
+}
+
+// Done reports why the stream ended, once States is closed: nil after
+// game_over, the connection error otherwise.
+func (w *Watcher) Done() <-chan error {
+	return w.done
+}
+
+// Features returns the protocol version and capabilities negotiated with
+// the server.
+func (w *Watcher) Features() Features {
+	return w.features
+}
+
+// SendChat sends a chat message to the players and other spectators.
+func (w *Watcher) SendChat(text string) error {
+	return WriteMessage(w.conn, NewChatMsg("", w.name, text))
+}
+
+func (w *Watcher) Close() error {
+	return w.conn.Close()
+}
+
+// RunWatch connects to the server at addr as a spectator and shows the game.
+func RunWatch(addr, name string, opts ...Option) error {
+	w, err := Watch(addr, name, opts...)
+	if err != nil {
+		return err
+	}
+	defer w.Close()
+
+	chatCh := make(chan cli.ChatEntry, 16)
+	go func() {
+		for cp := range w.ChatCh() {
+			chatCh <- cli.ChatEntry{Name: cp.Name, Text: cp.Text}
+		}
+		close(chatCh)
+	}()
+
+	return cli.RunWatch(w.States(), chatCh, w.Done())
+}
@@ -0,0 +1,216 @@
+package p2p
+
+import (
+	"bufio"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+var errWrongPassword = errors.New("wrong room password")
+
+// checkPassword reports errWrongPassword unless got matches the room
+// password want. An empty want admits everyone.
+func checkPassword(want, got string) error {
+	if want == "" || subtle.ConstantTimeCompare([]byte(want), []byte(got)) == 1 {
+		return nil
+	}
+	return errWrongPassword
+}
+
+// LoadOrCreateCertificate loads the TLS certificate kept in dir as cert.pem
+// and key.pem. On first use it generates a self-signed one; players pin its
+// fingerprint instead of verifying it against a CA.
+func LoadOrCreateCertificate(dir string) (tls.Certificate, error) {
+	certPath := filepath.Join(dir, "cert.pem")
+	keyPath := filepath.Join(dir, "key.pem")
+	if cert, err := tls.LoadX509KeyPair(certPath, keyPath); err == nil {
+		return cert, nil
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return tls.Certificate{}, fmt.Errorf("load certificate: %w", err)
+	}
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return tls.Certificate{}, fmt.Errorf("generate key: %w", err)
+	}
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return tls.Certificate{}, fmt.Errorf("generate serial: %w", err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: "yatzcli"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().AddDate(10, 0, 0),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		return tls.Certificate{}, fmt.Errorf("create certificate: %w", err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return tls.Certificate{}, fmt.Errorf("marshal key: %w", err)
+	}
+
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return tls.Certificate{}, err
+	}
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+	if err := os.WriteFile(keyPath, keyPEM, 0o600); err != nil {
+		return tls.Certificate{}, err
+	}
+	if err := os.WriteFile(certPath, certPEM, 0o644); err != nil {
+		return tls.Certificate{}, err
+	}
+	return tls.X509KeyPair(certPEM, keyPEM)
+}
+
+// Fingerprint returns the SHA-256 fingerprint of cert's leaf certificate as
+// colon-separated hex, the form players pass to --fingerprint.
+func Fingerprint(cert tls.Certificate) string {
+	if len(cert.Certificate) == 0 {
+		return ""
+	}
+	return fingerprintOf(cert.Certificate[0])
+}
+
+func fingerprintOf(der []byte) string {
+	sum := sha256.Sum256(der)
+	return formatHex(sum[:])
+}
+
+// normalizeFingerprint lets fingerprints be given in any case, with or
+// without colons.
+func normalizeFingerprint(fp string) string {
+	fp = strings.ReplaceAll(strings.TrimSpace(fp), ":", "")
+	if b, err := hex.DecodeString(fp); err == nil {
+		return formatHex(b)
+	}
+	return fp
+}
+
+func formatHex(b []byte) string {
+	parts := make([]string, len(b))
+	for i, x := range b {
+		parts[i] = fmt.Sprintf("%02X", x)
+	}
+	return strings.Join(parts, ":")
+}
+
+// ServerTLSConfig serves cert, e.g. one from LoadOrCreateCertificate.
+func ServerTLSConfig(cert tls.Certificate) *tls.Config {
+	return &tls.Config{Certificates: []tls.Certificate{cert}, MinVersion: tls.VersionTLS12}
+}
+
+// PinnedTLSConfig accepts only a server whose certificate has the given
+// fingerprint. The pin replaces CA verification, which a self-signed
+// certificate can't pass.
+func PinnedTLSConfig(fingerprint string) *tls.Config {
+	want := normalizeFingerprint(fingerprint)
+	return pinnedConfig(func(got string) error {
+		if got != want {
+			return fmt.Errorf("server certificate fingerprint %s does not match the pinned %s", got, want)
+		}
+		return nil
+	})
+}
+
+// TrustOnFirstUseTLSConfig pins the fingerprint addr presents on the first
+// connection in the known-hosts file at path, and rejects a different
+// certificate from addr afterwards. onNew, if non-nil, is told about a
+// newly trusted fingerprint.
+func TrustOnFirstUseTLSConfig(path, addr string, onNew func(fingerprint string)) *tls.Config {
+	return pinnedConfig(func(got string) error {
+		knownHostsMu.Lock()
+		defer knownHostsMu.Unlock()
+		hosts, err := readKnownHosts(path)
+		if err != nil {
+			return err
+		}
+		if want, ok := hosts[addr]; ok {
+			if got != want {
+				return fmt.Errorf("server certificate for %s changed: got %s, trusted %s (remove it from %s if this is expected)", addr, got, want, path)
+			}
+			return nil
+		}
+		if err := appendKnownHost(path, addr, got); err != nil {
+			return err
+		}
+		if onNew != nil {
+			onNew(got)
+		}
+		return nil
+	})
+}
+
+func pinnedConfig(verify func(fingerprint string) error) *tls.Config {
+	return &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		// The certificate is self-signed; VerifyPeerCertificate checks the
+		// pin instead.
+		InsecureSkipVerify: true,
+		VerifyPeerCertificate: func(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+			if len(rawCerts) == 0 {
+				return errors.New("server sent no certificate")
+			}
+			return verify(fingerprintOf(rawCerts[0]))
+		},
+	}
+}
+
+var knownHostsMu sync.Mutex
+
+// readKnownHosts reads "addr fingerprint" lines.
+func readKnownHosts(path string) (map[string]string, error) {
+	hosts := make(map[string]string)
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return hosts, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		fields := strings.Fields(sc.Text())
+		if len(fields) == 2 {
+			hosts[fields[0]] = fields[1]
+		}
+	}
+	return hosts, sc.Err()
+}
+
+func appendKnownHost(path, addr, fingerprint string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintf(f, "%s %s\n", addr, fingerprint); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,108 @@
+package p2p
+
+import (
+	"crypto/tls"
+	"net"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func startTLSLobby(t *testing.T, cert tls.Certificate, opts ...Option) string {
+	t.Helper()
+	return startLobby(t, append(opts, WithTLS(ServerTLSConfig(cert)))...)
+}
+
+func TestLoadOrCreateCertificate(t *testing.T) {
+	dir := t.TempDir()
+	cert, err := LoadOrCreateCertificate(dir)
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	again, err := LoadOrCreateCertificate(dir)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if Fingerprint(cert) != Fingerprint(again) {
+		t.Errorf("fingerprint changed on reload: %s, then %s", Fingerprint(cert), Fingerprint(again))
+	}
+	if other, _ := LoadOrCreateCertificate(t.TempDir()); Fingerprint(other) == Fingerprint(cert) {
+		t.Error("two new certificates share a fingerprint")
+	}
+}
+
+func TestTLS_PinnedFingerprint(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping lobby test in short mode")
+	}
+	cert, err := LoadOrCreateCertificate(t.TempDir())
+	if err != nil {
+		t.Fatalf("certificate: %v", err)
+	}
+	addr := startTLSLobby(t, cert, WithPassword("s3cret"))
+	fp := Fingerprint(cert)
+
+	// Pins are accepted in any case and without colons.
+	loose := strings.ToLower(strings.ReplaceAll(fp, ":", ""))
+	lc, err := DialLobby(addr, "Alice", WithTLS(PinnedTLSConfig(loose)), WithPassword("s3cret"))
+	if err != nil {
+		t.Fatalf("dial with the right pin: %v", err)
+	}
+	defer lc.Close()
+	if _, err := lc.ListTables(); err != nil {
+		t.Errorf("list tables over TLS: %v", err)
+	}
+
+	other, _ := LoadOrCreateCertificate(t.TempDir())
+	if _, err := DialLobby(addr, "Bob", WithTLS(PinnedTLSConfig(Fingerprint(other))), WithPassword("s3cret")); err == nil {
+		t.Error("expected a wrong pin to be rejected")
+	}
+	if _, err := DialLobby(addr, "Bob", WithTLS(PinnedTLSConfig(fp)), WithPassword("guess")); err == nil || !strings.Contains(err.Error(), "password") {
+		t.Errorf("dial with a wrong password: %v", err)
+	}
+
+	// A plaintext client can't talk to a TLS server, and doesn't stop it.
+	if conn, err := net.Dial("tcp", addr); err == nil {
+		_ = WriteMessage(conn, NewHandshakeMsg("Plain"))
+		if _, err := ReadMessage(conn); err == nil {
+			t.Error("expected a plaintext client to get no reply")
+		}
+		conn.Close()
+	}
+	if _, err := DialLobby(addr, "Carol", WithTLS(PinnedTLSConfig(fp)), WithPassword("s3cret")); err != nil {
+		t.Errorf("dial after a plaintext client: %v", err)
+	}
+}
+
+func TestTLS_TrustOnFirstUse(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping lobby test in short mode")
+	}
+	cert, err := LoadOrCreateCertificate(t.TempDir())
+	if err != nil {
+		t.Fatalf("certificate: %v", err)
+	}
+	addr := startTLSLobby(t, cert)
+	knownHosts := filepath.Join(t.TempDir(), "known_hosts")
+
+	var trusted []string
+	onNew := func(fp string) { trusted = append(trusted, fp) }
+	for range 2 {
+		lc, err := DialLobby(addr, "Alice", WithTLS(TrustOnFirstUseTLSConfig(knownHosts, addr, onNew)))
+		if err != nil {
+			t.Fatalf("dial: %v", err)
+		}
+		lc.Close()
+	}
+	if len(trusted) != 1 || trusted[0] != Fingerprint(cert) {
+		t.Errorf("trusted %v, want %s once", trusted, Fingerprint(cert))
+	}
+
+	// A different certificate presented for addr is refused.
+	other, _ := LoadOrCreateCertificate(t.TempDir())
+	impostor := startTLSLobby(t, other)
+	_, err = DialLobby(impostor, "Alice", WithTLS(TrustOnFirstUseTLSConfig(knownHosts, addr, onNew)))
+	if err == nil || !strings.Contains(err.Error(), "changed") {
+		t.Errorf("dial with a changed certificate: %v", err)
+	}
+}
@@ -0,0 +1,306 @@
+package p2p
+
+import (
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// Transports. Every part of the package talks to a net.Conn through
+// ReadMessage and WriteMessage: over TCP a message is length-prefixed JSON,
+// over WebSocket it is one text frame holding the same JSON. dial picks the
+// transport from the address, and servers accept either through a
+// net.Listener (see ListenWebSocket and MultiListener).
+
+// messageConn is a connection that frames messages itself.
+type messageConn interface {
+	readMessage() (*Message, error)
+	writeMessage(msg *Message) error
+}
+
+// isWebSocketURL reports whether addr is a ws:// or wss:// URL rather than
+// a host:port.
+func isWebSocketURL(addr string) bool {
+	return strings.HasPrefix(addr, "ws://") || strings.HasPrefix(addr, "wss://")
+}
+
+// dial connects to addr: a ws:// or wss:// URL over WebSocket, otherwise a
+// host:port over TCP. cfg, if non-nil, secures a TCP or wss connection.
+func dial(addr string, timeout time.Duration, cfg *tls.Config) (net.Conn, error) {
+	if isWebSocketURL(addr) {
+		d := &websocket.Dialer{
+			Proxy:            http.ProxyFromEnvironment,
+			HandshakeTimeout: timeout,
+			TLSClientConfig:  cfg,
+		}
+		ws, _, err := d.Dial(addr, nil)
+		if err != nil {
+			return nil, err
+		}
+		return newWSConn(ws), nil
+	}
+	d := &net.Dialer{Timeout: timeout}
+	if cfg != nil {
+		return tls.DialWithDialer(d, "tcp", addr, cfg)
+	}
+	return d.Dial("tcp", addr)
+}
+
+// listen secures ln with TLS when WithTLS was given. A WebSocket listener
+// is secured beneath its HTTP server, so it serves wss.
+func (o *options) listen(ln net.Listener) net.Listener {
+	if o.tlsConfig == nil {
+		return ln
+	}
+	switch l := ln.(type) {
+	case *wsListener:
+		return newWSListener(l.ln, o.tlsConfig)
+	case *multiListener:
+		lns := make([]net.Listener, len(l.lns))
+		for i, child := range l.lns {
+			lns[i] = o.listen(child)
+		}
+		return MultiListener(lns...)
+	default:
+		return tls.NewListener(ln, o.tlsConfig)
+	}
+}
+
+// wsConn is a WebSocket connection. Read and Write treat it as a byte
+// stream of binary frames; ReadMessage and WriteMessage use one text frame
+// per message instead.
+type wsConn struct {
+	ws *websocket.Conn
+	r  io.Reader // current frame for Read
+
+	wmu sync.Mutex
+}
+
+func newWSConn(ws *websocket.Conn) *wsConn {
+	ws.SetReadLimit(maxMessageSize)
+	return &wsConn{ws: ws}
+}
+
+func (c *wsConn) readMessage() (*Message, error) {
+	_, data, err := c.ws.ReadMessage()
+	if err != nil {
+		return nil, fmt.Errorf("read message: %w", err)
+	}
+	var msg Message
+	if err := json.Unmarshal(data, &msg); err != nil {
+		return nil, fmt.Errorf("unmarshal message: %w", err)
+	}
+	return &msg, nil
+}
+
+func (c *wsConn) writeMessage(msg *Message) error {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("marshal message: %w", err)
+	}
+	c.wmu.Lock()
+	defer c.wmu.Unlock()
+	if err := c.ws.WriteMessage(websocket.TextMessage, data); err != nil {
+		return fmt.Errorf("write message: %w", err)
+	}
+	return nil
+}
+
+func (c *wsConn) Read(p []byte) (int, error) {
+	for {
+		if c.r == nil {
+			_, r, err := c.ws.NextReader()
+			if err != nil {
+				return 0, err
+			}
+			c.r = r
+		}
+		n, err := c.r.Read(p)
+		if errors.Is(err, io.EOF) {
+			c.r = nil
+			if n == 0 {
+				continue
+			}
+			err = nil
+		}
+		return n, err
+	}
+}
+
+func (c *wsConn) Write(p []byte) (int, error) {
+	c.wmu.Lock()
+	defer c.wmu.Unlock()
+	if err := c.ws.WriteMessage(websocket.BinaryMessage, p); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+func (c *wsConn) Close() error                       { return c.ws.Close() }
+func (c *wsConn) LocalAddr() net.Addr                { return c.ws.LocalAddr() }
+func (c *wsConn) RemoteAddr() net.Addr               { return c.ws.RemoteAddr() }
+func (c *wsConn) SetReadDeadline(t time.Time) error  { return c.ws.SetReadDeadline(t) }
+func (c *wsConn) SetWriteDeadline(t time.Time) error { return c.ws.SetWriteDeadline(t) }
+
+func (c *wsConn) SetDeadline(t time.Time) error {
+	if err := c.ws.SetReadDeadline(t); err != nil {
+		return err
+	}
+	return c.ws.SetWriteDeadline(t)
+}
+
+// wsListener accepts WebSocket connections, upgraded by an HTTP server on
+// ln that starts with the first Accept.
+type wsListener struct {
+	ln        net.Listener
+	tlsConfig *tls.Config
+
+	start     sync.Once
+	conns     chan net.Conn
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// ListenWebSocket serves the game protocol over WebSocket on ln, at any
+// path. Connections from any origin are accepted, so browser clients can
+// play; use WithPassword to restrict who joins.
+func ListenWebSocket(ln net.Listener) net.Listener {
+	return newWSListener(ln, nil)
+}
+
+func newWSListener(ln net.Listener, cfg *tls.Config) *wsListener {
+	return &wsListener{
+		ln:        ln,
+		tlsConfig: cfg,
+		conns:     make(chan net.Conn),
+		done:      make(chan struct{}),
+	}
+}
+
+var upgrader = websocket.Upgrader{
+	CheckOrigin: func(*http.Request) bool { return true },
+}
+
+func (l *wsListener) serve() {
+	ln := l.ln
+	if l.tlsConfig != nil {
+		ln = tls.NewListener(ln, l.tlsConfig)
+	}
+	srv := &http.Server{
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ws, err := upgrader.Upgrade(w, r, nil)
+			if err != nil {
+				return // Upgrade has replied with an HTTP error
+			}
+			select {
+			case l.conns <- newWSConn(ws):
+			case <-l.done:
+				ws.Close()
+			}
+		}),
+		ReadHeaderTimeout: 30 * time.Second,
+	}
+	go func() {
+		_ = srv.Serve(ln)
+		l.Close()
+	}()
+}
+
+func (l *wsListener) Accept() (net.Conn, error) {
+	l.start.Do(l.serve)
+	select {
+	case conn := <-l.conns:
+		return conn, nil
+	case <-l.done:
+		return nil, net.ErrClosed
+	}
+}
+
+func (l *wsListener) Close() error {
+	var err error
+	l.closeOnce.Do(func() {
+		close(l.done)
+		err = l.ln.Close()
+	})
+	return err
+}
+
+func (l *wsListener) Addr() net.Addr { return l.ln.Addr() }
+
+// multiListener accepts from several listeners at once.
+type multiListener struct {
+	lns []net.Listener
+
+	start     sync.Once
+	conns     chan net.Conn
+	done      chan struct{}
+	err       error // first Accept error, set before done is closed
+	closeOnce sync.Once
+}
+
+// MultiListener accepts connections from all of lns, e.g. a TCP listener
+// and a ListenWebSocket one, so one game takes players over both. Its Addr
+// is that of the first listener. If any listener fails, all are closed.
+func MultiListener(lns ...net.Listener) net.Listener {
+	return &multiListener{
+		lns:   lns,
+		conns: make(chan net.Conn),
+		done:  make(chan struct{}),
+	}
+}
+
+func (m *multiListener) serve() {
+	for _, ln := range m.lns {
+		go func() {
+			for {
+				conn, err := ln.Accept()
+				if err != nil {
+					m.fail(err)
+					return
+				}
+				select {
+				case m.conns <- conn:
+				case <-m.done:
+					conn.Close()
+					return
+				}
+			}
+		}()
+	}
+}
+
+func (m *multiListener) fail(err error) {
+	m.closeOnce.Do(func() {
+		m.err = err
+		close(m.done)
+		for _, ln := range m.lns {
+			ln.Close()
+		}
+	})
+}
+
+func (m *multiListener) Accept() (net.Conn, error) {
+	m.start.Do(m.serve)
+	select {
+	case conn := <-m.conns:
+		return conn, nil
+	case <-m.done:
+		return nil, m.err
+	}
+}
+
+func (m *multiListener) Close() error {
+	m.fail(net.ErrClosed)
+	return nil
+}
+
+func (m *multiListener) Addr() net.Addr { return m.lns[0].Addr() }
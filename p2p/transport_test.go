@@ -0,0 +1,99 @@
+package p2p
+
+import (
+	"math/rand"
+	"net"
+	"testing"
+)
+
+func TestWebSocket_MixedTransports(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping server test in short mode")
+	}
+
+	tcpLn, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	wsLn, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	ln := MultiListener(tcpLn, ListenWebSocket(wsLn))
+	defer ln.Close()
+	go RunServer(ln, 2, rand.NewSource(42))
+
+	// Alice plays over TCP and Bob over WebSocket, in the same game. Each
+	// join returns once the game starts.
+	aliceCh := make(chan *RemoteClient, 1)
+	go func() {
+		rc, err := NewRemoteClient(tcpLn.Addr().String(), "Alice")
+		if err != nil {
+			t.Errorf("Alice join: %v", err)
+		}
+		aliceCh <- rc
+	}()
+	bob, err := NewRemoteClient("ws://"+wsLn.Addr().String()+"/yatz", "Bob")
+	if err != nil {
+		t.Fatalf("Bob join over WebSocket: %v", err)
+	}
+	defer bob.Close()
+	alice := <-aliceCh
+	if alice == nil {
+		t.FailNow()
+	}
+	defer alice.Close()
+
+	if bob.PlayerID() != "player-1" {
+		t.Errorf("Bob got %s, want player-1", bob.PlayerID())
+	}
+	if _, _, err := alice.WaitForTurn(); err != nil {
+		t.Fatalf("Alice wait for turn: %v", err)
+	}
+	if _, err := alice.Roll(); err != nil {
+		t.Fatalf("Alice roll: %v", err)
+	}
+
+	// Alice's roll reaches Bob over WebSocket, and his chat goes back.
+	if gs := <-bob.StateUpdateCh(); gs.RollCount != 1 || gs.CurrentPlayer != "player-0" {
+		t.Errorf("Bob saw RollCount %d, current %s", gs.RollCount, gs.CurrentPlayer)
+	}
+	chat := alice.ChatCh()
+	if err := bob.SendChat("player-1", "Bob", "hi over ws"); err != nil {
+		t.Fatalf("Bob chat: %v", err)
+	}
+	if cp := <-chat; cp.Text != "hi over ws" {
+		t.Errorf("Alice got chat %+v", cp)
+	}
+}
+
+func TestWebSocket_TLS(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping lobby test in short mode")
+	}
+	cert, err := LoadOrCreateCertificate(t.TempDir())
+	if err != nil {
+		t.Fatalf("certificate: %v", err)
+	}
+	wsLn, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	ln := ListenWebSocket(wsLn)
+	defer ln.Close()
+	go NewLobby(WithTLS(ServerTLSConfig(cert))).Serve(ln)
+
+	addr := "wss://" + wsLn.Addr().String()
+	lc, err := DialLobby(addr, "Alice", WithTLS(PinnedTLSConfig(Fingerprint(cert))))
+	if err != nil {
+		t.Fatalf("dial over wss: %v", err)
+	}
+	defer lc.Close()
+	if _, err := lc.CreateTable(2, ""); err != nil {
+		t.Errorf("create table over wss: %v", err)
+	}
+
+	if _, err := DialLobby("ws://"+wsLn.Addr().String(), "Bob"); err == nil {
+		t.Error("expected plain ws to a wss server to fail")
+	}
+}
@@ -0,0 +1,107 @@
+package p2p
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// ProtocolVersion is the version of the game protocol this build speaks.
+// Peers older than MinProtocolVersion are turned away during the handshake.
+// A handshake without a version comes from a build before versioning, which
+// counts as version 1.
+const (
+	ProtocolVersion    = 2
+	MinProtocolVersion = 2
+)
+
+// Capabilities are optional protocol features. A client lists those it
+// understands in its handshake; the reply lists those both sides will use.
+const (
+	CapChat      = "chat"       // chat messages are relayed
+	CapResume    = "resume"     // a dropped player can reclaim their seat
+	CapCoach     = "coach"      // hints, when every player asks for them
+	CapTurnClock = "turn_clock" // turns are timed
+	CapSpectate  = "spectate"   // read-only clients may watch
+	CapLobby     = "lobby"      // tables, see Lobby
+	CapFairDice  = "fair_dice"  // verifiable dice, see WithVerifiableDice
+	CapRatings   = "ratings"    // accounts and ratings, see WithRatings
+)
+
+// clientCapabilities is what this build's clients understand.
+var clientCapabilities = []string{CapChat, CapResume, CapCoach, CapTurnClock, CapSpectate, CapLobby, CapFairDice, CapRatings}
+
+// ErrIncompatibleVersion reports a peer whose protocol version this build
+// can't play with.
+var ErrIncompatibleVersion = errors.New("incompatible protocol version")
+
+// Features is what both ends of a connection agreed on in the handshake.
+type Features struct {
+	Version      int
+	Capabilities []string
+}
+
+// Has reports whether capability was negotiated.
+func (f Features) Has(capability string) bool {
+	return slices.Contains(f.Capabilities, capability)
+}
+
+func (f Features) String() string {
+	if len(f.Capabilities) == 0 {
+		return fmt.Sprintf("protocol v%d", f.Version)
+	}
+	return fmt.Sprintf("protocol v%d: %s", f.Version, strings.Join(f.Capabilities, ", "))
+}
+
+// checkVersion returns ErrIncompatibleVersion for a peer speaking version v.
+func checkVersion(v int) error {
+	if v == 0 {
+		v = 1
+	}
+	if v < MinProtocolVersion {
+		return fmt.Errorf("%w: peer speaks version %d, this build needs %d or newer; upgrade yatz", ErrIncompatibleVersion, v, MinProtocolVersion)
+	}
+	return nil
+}
+
+// negotiate returns the features a server offering offered agrees on with
+// a client whose handshake is hs: the lower of the two versions and the
+// capabilities both list, in offered's order.
+func negotiate(offered []string, hs *HandshakePayload) Features {
+	f := Features{Version: min(ProtocolVersion, hs.Version)}
+	for _, c := range offered {
+		if slices.Contains(hs.Capabilities, c) {
+			f.Capabilities = append(f.Capabilities, c)
+		}
+	}
+	return f
+}
+
+// capabilities lists what a server or host run with o offers, plus extra.
+func (o *options) capabilities(extra ...string) []string {
+	caps := []string{CapChat, CapCoach}
+	if o.grace > 0 {
+		caps = append(caps, CapResume)
+	}
+	if o.turnLimit > 0 {
+		caps = append(caps, CapTurnClock)
+	}
+	if o.fairDice {
+		caps = append(caps, CapFairDice)
+	}
+	if o.ratings != nil {
+		caps = append(caps, CapRatings)
+	}
+	return append(caps, extra...)
+}
+
+// acceptedFeatures checks a server's handshake reply: its version must be
+// one this build plays with. The reply's capabilities are the negotiated
+// set.
+func acceptedFeatures(hs *HandshakePayload) (Features, error) {
+	if err := checkVersion(hs.Version); err != nil {
+		return Features{}, err
+	}
+	return Features{Version: min(ProtocolVersion, hs.Version), Capabilities: hs.Capabilities}, nil
+}
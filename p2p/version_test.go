@@ -0,0 +1,51 @@
+package p2p
+
+import (
+	"errors"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestCheckVersion(t *testing.T) {
+	tests := []struct {
+		version int
+		ok      bool
+	}{
+		{0, false}, // before versioning
+		{1, false},
+		{ProtocolVersion, true},
+		{ProtocolVersion + 1, true}, // newer clients decide whether they can talk to us
+	}
+	for _, tt := range tests {
+		err := checkVersion(tt.version)
+		if (err == nil) != tt.ok {
+			t.Errorf("checkVersion(%d) = %v, want ok=%v", tt.version, err, tt.ok)
+		}
+		if err != nil && !errors.Is(err, ErrIncompatibleVersion) {
+			t.Errorf("checkVersion(%d) = %v, want ErrIncompatibleVersion", tt.version, err)
+		}
+	}
+}
+
+func TestNegotiate(t *testing.T) {
+	o := newOptions([]Option{WithTurnClock(time.Minute, nil)})
+	offered := o.capabilities(CapSpectate)
+	hs := &HandshakePayload{Version: ProtocolVersion + 1, Capabilities: []string{CapTurnClock, CapChat, "teleport"}}
+
+	f := negotiate(offered, hs)
+	if f.Version != ProtocolVersion {
+		t.Errorf("version = %d, want the lower %d", f.Version, ProtocolVersion)
+	}
+	if want := []string{CapChat, CapTurnClock}; !slices.Equal(f.Capabilities, want) {
+		t.Errorf("capabilities = %v, want %v", f.Capabilities, want)
+	}
+	if f.Has(CapResume) || f.Has("teleport") {
+		t.Errorf("%v has a capability only one side listed", f)
+	}
+
+	untimed := newOptions([]Option{WithGracePeriod(0)})
+	if caps := untimed.capabilities(); slices.Contains(caps, CapTurnClock) || slices.Contains(caps, CapResume) {
+		t.Errorf("untimed game without seat holding offers %v", caps)
+	}
+}
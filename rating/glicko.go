@@ -0,0 +1,132 @@
+// Package rating keeps players' skill ratings: Glicko-2 ratings updated
+// after every game, and a database of the named accounts that hold them.
+package rating
+
+import "math"
+
+// Glicko-2 defaults: a new player's rating, deviation and volatility, and
+// the system constant tau, which limits how fast volatility changes.
+const (
+	DefaultRating     = 1500
+	DefaultDeviation  = 350
+	DefaultVolatility = 0.06
+	tau               = 0.5
+
+	scale   = 173.7178 // Glicko to Glicko-2 scale
+	epsilon = 0.000001 // volatility convergence tolerance
+)
+
+// Rating is a Glicko-2 rating: the estimate of a player's strength, the
+// deviation (uncertainty) of that estimate, and its volatility.
+type Rating struct {
+	Rating     float64 `json:"rating"`
+	Deviation  float64 `json:"deviation"`
+	Volatility float64 `json:"volatility"`
+}
+
+// New returns the rating of a player who hasn't played yet.
+func New() Rating {
+	return Rating{Rating: DefaultRating, Deviation: DefaultDeviation, Volatility: DefaultVolatility}
+}
+
+// Result is the outcome of a game against an opponent: Score is 1 for a
+// win, 0.5 for a draw and 0 for a loss.
+type Result struct {
+	Opponent Rating
+	Score    float64
+}
+
+// Update returns r after a rating period with results, following
+// Glickman's "Example of the Glicko-2 system". With no results, only the
+// deviation grows.
+func (r Rating) Update(results []Result) Rating {
+	mu := (r.Rating - DefaultRating) / scale
+	phi := r.Deviation / scale
+	if len(results) == 0 {
+		r.Deviation = math.Sqrt(phi*phi+r.Volatility*r.Volatility) * scale
+		return r
+	}
+
+	var invV, sum float64
+	for _, res := range results {
+		muj := (res.Opponent.Rating - DefaultRating) / scale
+		gj := g(res.Opponent.Deviation / scale)
+		e := 1 / (1 + math.Exp(-gj*(mu-muj)))
+		invV += gj * gj * e * (1 - e)
+		sum += gj * (res.Score - e)
+	}
+	v := 1 / invV
+	delta := v * sum
+
+	sigma := volatility(phi, r.Volatility, v, delta)
+	phiStar := math.Sqrt(phi*phi + sigma*sigma)
+	phiNew := 1 / math.Sqrt(1/(phiStar*phiStar)+1/v)
+	muNew := mu + phiNew*phiNew*sum
+	return Rating{Rating: muNew*scale + DefaultRating, Deviation: phiNew * scale, Volatility: sigma}
+}
+
+func g(phi float64) float64 {
+	return 1 / math.Sqrt(1+3*phi*phi/(math.Pi*math.Pi))
+}
+
+// volatility finds the new volatility with the Illinois algorithm (step 5
+// of Glickman's paper).
+func volatility(phi, sigma, v, delta float64) float64 {
+	a := math.Log(sigma * sigma)
+	f := func(x float64) float64 {
+		ex := math.Exp(x)
+		d := phi*phi + v + ex
+		return ex*(delta*delta-phi*phi-v-ex)/(2*d*d) - (x-a)/(tau*tau)
+	}
+
+	A := a
+	var B float64
+	if delta*delta > phi*phi+v {
+		B = math.Log(delta*delta - phi*phi - v)
+	} else {
+		k := 1.0
+		for f(a-k*tau) < 0 {
+			k++
+		}
+		B = a - k*tau
+	}
+	fA, fB := f(A), f(B)
+	for math.Abs(B-A) > epsilon {
+		C := A + (A-B)*fA/(fB-fA)
+		fC := f(C)
+		if fC*fB <= 0 {
+			A, fA = B, fB
+		} else {
+			fA /= 2
+		}
+		B, fB = C, fC
+	}
+	return math.Exp(A / 2)
+}
+
+// RateGame returns the ratings of the players of a multiplayer game after
+// it, given their final scores. Each player's game counts as a result
+// against every other player: a win over those they outscored, a draw with
+// those they tied and a loss to the rest. Every update uses the ratings
+// from before the game.
+func RateGame(ratings []Rating, scores []int) []Rating {
+	out := make([]Rating, len(ratings))
+	for i, r := range ratings {
+		results := make([]Result, 0, len(ratings)-1)
+		for j, opp := range ratings {
+			if j == i {
+				continue
+			}
+			s := 0.5
+			switch {
+			case scores[i] > scores[j]:
+				s = 1
+			case scores[i] < scores[j]:
+				s = 0
+			}
+			results = append(results, Result{Opponent: opp, Score: s})
+		}
+		out[i] = r.Update(results)
+	}
+	return out
+}
@@ -0,0 +1,53 @@
+package rating
+
+import (
+	"math"
+	"testing"
+)
+
+func near(a, b, tol float64) bool { return math.Abs(a-b) <= tol }
+
+// TestUpdate_GlickmanExample checks the worked example from Glickman's
+// "Example of the Glicko-2 system".
+func TestUpdate_GlickmanExample(t *testing.T) {
+	r := Rating{Rating: 1500, Deviation: 200, Volatility: 0.06}
+	got := r.Update([]Result{
+		{Opponent: Rating{Rating: 1400, Deviation: 30, Volatility: 0.06}, Score: 1},
+		{Opponent: Rating{Rating: 1550, Deviation: 100, Volatility: 0.06}, Score: 0},
+		{Opponent: Rating{Rating: 1700, Deviation: 300, Volatility: 0.06}, Score: 0},
+	})
+	if !near(got.Rating, 1464.06, 0.01) || !near(got.Deviation, 151.52, 0.01) || !near(got.Volatility, 0.05999, 0.00001) {
+		t.Errorf("got %+v, want 1464.06 / 151.52 / 0.05999", got)
+	}
+}
+
+func TestUpdate_NoGames(t *testing.T) {
+	r := New()
+	got := r.Update(nil)
+	if got.Rating != r.Rating || got.Volatility != r.Volatility || got.Deviation <= r.Deviation {
+		t.Errorf("got %+v, want only the deviation to grow from %+v", got, r)
+	}
+}
+
+func TestRateGame(t *testing.T) {
+	got := RateGame([]Rating{New(), New(), New()}, []int{250, 180, 250})
+	if !near(got[0].Rating, got[2].Rating, 1e-9) {
+		t.Errorf("tied players rated %.2f and %.2f", got[0].Rating, got[2].Rating)
+	}
+	if got[0].Rating <= DefaultRating || got[1].Rating >= DefaultRating {
+		t.Errorf("winners %.2f, loser %.2f", got[0].Rating, got[1].Rating)
+	}
+	for i, r := range got {
+		if r.Deviation >= DefaultDeviation {
+			t.Errorf("player %d deviation %.2f did not shrink", i, r.Deviation)
+		}
+	}
+
+	// Beating a much stronger player is worth more than beating an equal.
+	strong := Rating{Rating: 1900, Deviation: 80, Volatility: DefaultVolatility}
+	upset := RateGame([]Rating{New(), strong}, []int{200, 150})
+	even := RateGame([]Rating{New(), New()}, []int{200, 150})
+	if upset[0].Rating <= even[0].Rating {
+		t.Errorf("upset win %.2f, even win %.2f", upset[0].Rating, even[0].Rating)
+	}
+}
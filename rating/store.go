@@ -0,0 +1,185 @@
+package rating
+
+import (
+	"cmp"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+var accountsBucket = []byte("accounts")
+
+// ErrNameTaken is returned by SignIn for a name that belongs to an account
+// with a different key.
+var ErrNameTaken = errors.New("name belongs to another player's account")
+
+// Account is a named player and their rating. The account is claimed by
+// the first key it is signed in with.
+type Account struct {
+	Name    string    `json:"name"`
+	KeyHash string    `json:"key_hash"` // hex SHA-256 of the account key
+	Rating  Rating    `json:"rating"`
+	Games   int       `json:"games"`
+	Wins    int       `json:"wins"` // games finished first, alone or tied
+	Created time.Time `json:"created"`
+	Updated time.Time `json:"updated,omitempty"`
+}
+
+// Store is a database of accounts, kept by a server for as long as it
+// runs.
+type Store struct {
+	db *bolt.DB
+}
+
+// Open opens the database at path, creating it if needed.
+func Open(path string) (*Store, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("create ratings directory: %w", err)
+	}
+	db, err := bolt.Open(path, 0o600, &bolt.Options{Timeout: 5 * time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("open ratings %s: %w", path, err)
+	}
+	err = db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(accountsBucket)
+		return err
+	})
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("open ratings %s: %w", path, err)
+	}
+	return &Store{db: db}, nil
+}
+
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+// SignIn checks that key opens the account called name, creating the
+// account if there is none. An empty key signs in no one: it is refused
+// for a name with an account and accepted, unrated, otherwise. SignIn
+// reports whether the player is rated.
+func (s *Store) SignIn(name, key string) (bool, error) {
+	rated := false
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(accountsBucket)
+		acct, ok, err := getAccount(b, name)
+		if err != nil {
+			return err
+		}
+		switch {
+		case ok && (key == "" || subtle.ConstantTimeCompare([]byte(acct.KeyHash), []byte(hashKey(key))) != 1):
+			return fmt.Errorf("%w: %q", ErrNameTaken, name)
+		case ok:
+			rated = true
+			return nil
+		case key == "":
+			return nil
+		}
+		rated = true
+		return putAccount(b, Account{Name: name, KeyHash: hashKey(key), Rating: New(), Created: time.Now().UTC()})
+	})
+	return rated, err
+}
+
+// RecordGame rates a finished game between the accounts called names,
+// whose final scores are scores, with RateGame.
+func (s *Store) RecordGame(names []string, scores []int) error {
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(accountsBucket)
+		accts := make([]Account, len(names))
+		ratings := make([]Rating, len(names))
+		for i, name := range names {
+			acct, ok, err := getAccount(b, name)
+			if err != nil {
+				return err
+			}
+			if !ok {
+				return fmt.Errorf("no account %q", name)
+			}
+			accts[i] = acct
+			ratings[i] = acct.Rating
+		}
+		top := slices.Max(scores)
+		now := time.Now().UTC()
+		for i, r := range RateGame(ratings, scores) {
+			accts[i].Rating = r
+			accts[i].Games++
+			if scores[i] == top {
+				accts[i].Wins++
+			}
+			accts[i].Updated = now
+			if err := putAccount(b, accts[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("rate game: %w", err)
+	}
+	return nil
+}
+
+// Leaderboard returns up to limit accounts that have played, best rated
+// first. A limit of zero or less returns them all.
+func (s *Store) Leaderboard(limit int) ([]Account, error) {
+	var accts []Account
+	err := s.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(accountsBucket).ForEach(func(k, v []byte) error {
+			var acct Account
+			if err := json.Unmarshal(v, &acct); err != nil {
+				return fmt.Errorf("account %q: %w", k, err)
+			}
+			if acct.Games > 0 {
+				accts = append(accts, acct)
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, fmt.Errorf("read ratings: %w", err)
+	}
+	slices.SortFunc(accts, func(a, b Account) int {
+		return cmp.Or(cmp.Compare(b.Rating.Rating, a.Rating.Rating), strings.Compare(a.Name, b.Name))
+	})
+	if limit > 0 && len(accts) > limit {
+		accts = accts[:limit]
+	}
+	return accts, nil
+}
+
+func getAccount(b *bolt.Bucket, name string) (Account, bool, error) {
+	v := b.Get([]byte(name))
+	if v == nil {
+		return Account{}, false, nil
+	}
+	var acct Account
+	if err := json.Unmarshal(v, &acct); err != nil {
+		return Account{}, false, fmt.Errorf("account %q: %w", name, err)
+	}
+	return acct, true, nil
+}
+
+func putAccount(b *bolt.Bucket, acct Account) error {
+	data, err := json.Marshal(acct)
+	if err != nil {
+		return err
+	}
+	return b.Put([]byte(acct.Name), data)
+}
+
+func hashKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
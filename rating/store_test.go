@@ -0,0 +1,82 @@
+package rating
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+)
+
+func TestStore_SignIn(t *testing.T) {
+	s, err := Open(filepath.Join(t.TempDir(), "ratings.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	if rated, err := s.SignIn("Alice", "alice-key"); err != nil || !rated {
+		t.Fatalf("new account: rated %v, err %v", rated, err)
+	}
+	if rated, err := s.SignIn("Alice", "alice-key"); err != nil || !rated {
+		t.Errorf("same key: rated %v, err %v", rated, err)
+	}
+	if _, err := s.SignIn("Alice", "other-key"); !errors.Is(err, ErrNameTaken) {
+		t.Errorf("wrong key: err %v, want ErrNameTaken", err)
+	}
+	if _, err := s.SignIn("Alice", ""); !errors.Is(err, ErrNameTaken) {
+		t.Errorf("no key for an account: err %v, want ErrNameTaken", err)
+	}
+	if rated, err := s.SignIn("Guest", ""); err != nil || rated {
+		t.Errorf("no key: rated %v, err %v, want an unrated guest", rated, err)
+	}
+}
+
+func TestStore_RecordGameAndLeaderboard(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ratings.db")
+	s, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"Alice", "Bob", "Carol", "Idle"} {
+		if _, err := s.SignIn(name, name+"-key"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := s.RecordGame([]string{"Alice", "Bob", "Carol"}, []int{240, 180, 200}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.RecordGame([]string{"Alice", "Bob"}, []int{210, 190}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.RecordGame([]string{"Alice", "Nobody"}, []int{1, 2}); err == nil {
+		t.Error("rated a game with no account")
+	}
+	s.Close()
+
+	// Ratings persist.
+	s, err = Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	board, err := s.Leaderboard(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, a := range board {
+		names = append(names, a.Name)
+	}
+	if len(board) != 3 || names[0] != "Alice" || names[1] != "Carol" || names[2] != "Bob" {
+		t.Fatalf("leaderboard = %v, want Alice, Carol, Bob (Idle hasn't played)", names)
+	}
+	if a := board[0]; a.Games != 2 || a.Wins != 2 || a.Rating.Rating <= DefaultRating {
+		t.Errorf("Alice = %+v", a)
+	}
+	if b := board[2]; b.Games != 2 || b.Wins != 0 {
+		t.Errorf("Bob = %+v", b)
+	}
+
+	if top, err := s.Leaderboard(1); err != nil || len(top) != 1 || top[0].Name != "Alice" {
+		t.Errorf("top 1 = %+v, %v", top, err)
+	}
+}
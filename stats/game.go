@@ -0,0 +1,65 @@
+package stats
+
+import (
+	"time"
+
+	"github.com/edge2992/yatzcli/engine"
+)
+
+// Modes name the part of yatz a game was played in.
+const (
+	ModeLocal  = "local"  // yatz play
+	ModeBattle = "battle" // yatz battle
+	ModeP2P    = "p2p"    // yatz host and yatz join
+	ModeServer = "server" // yatz serve
+	ModeMCP    = "mcp"    // the MCP server's tools
+)
+
+// Game is the record of a finished game.
+type Game struct {
+	ID       uint64    `json:"id,omitempty"` // assigned by the store
+	Finished time.Time `json:"finished"`
+	Mode     string    `json:"mode"`
+	Rules    string    `json:"rules"`
+	Players  []Player  `json:"players"`
+}
+
+// Player is one seat's result.
+type Player struct {
+	Name string `json:"name"`
+	// Strategy names the AI strategy that played the seat; it is empty for
+	// a person.
+	Strategy string `json:"strategy,omitempty"`
+	// You marks the seat played on this machine.
+	You       bool             `json:"you,omitempty"`
+	Score     int              `json:"score"`
+	Scorecard engine.Scorecard `json:"scorecard"`
+}
+
+// NewGame is the record of the finished game in state, played in mode. you
+// is the player ID of the seat played on this machine, or empty if there
+// is none; strategies maps the player IDs of AI seats to their strategy
+// names.
+func NewGame(mode string, state engine.GameState, you string, strategies map[string]string) Game {
+	g := Game{Finished: time.Now().UTC(), Mode: mode, Rules: state.Rules}
+	for _, p := range state.Players {
+		g.Players = append(g.Players, Player{
+			Name:      p.Name,
+			Strategy:  strategies[p.ID],
+			You:       you != "" && p.ID == you,
+			Score:     p.Scorecard.Total(),
+			Scorecard: p.Scorecard,
+		})
+	}
+	return g
+}
+
+// Won reports whether the player at seat i finished first, alone or tied.
+func (g Game) Won(i int) bool {
+	for _, p := range g.Players {
+		if p.Score > g.Players[i].Score {
+			return false
+		}
+	}
+	return true
+}
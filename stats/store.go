@@ -0,0 +1,112 @@
+// Package stats keeps a local database of finished games, under the user's
+// config directory, and summarizes the results it holds.
+package stats
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+var gamesBucket = []byte("games")
+
+// Store is a database of finished games. Only one process can have it open
+// at a time, so long-running processes should use Record, which opens it
+// for each game.
+type Store struct {
+	db *bolt.DB
+}
+
+// DefaultPath returns where Record keeps its database.
+func DefaultPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("locate config directory: %w", err)
+	}
+	return filepath.Join(dir, "yatzcli", "stats.db"), nil
+}
+
+// Open opens the database at path, creating it if needed. It waits up to a
+// few seconds for another process holding the database to let go.
+func Open(path string) (*Store, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("create stats directory: %w", err)
+	}
+	db, err := bolt.Open(path, 0o600, &bolt.Options{Timeout: 5 * time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("open stats %s: %w", path, err)
+	}
+	err = db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(gamesBucket)
+		return err
+	})
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("open stats %s: %w", path, err)
+	}
+	return &Store{db: db}, nil
+}
+
+// Add stores g and returns the ID it was given.
+func (s *Store) Add(g Game) (uint64, error) {
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(gamesBucket)
+		id, err := b.NextSequence()
+		if err != nil {
+			return err
+		}
+		g.ID = id
+		data, err := json.Marshal(g)
+		if err != nil {
+			return err
+		}
+		return b.Put(binary.BigEndian.AppendUint64(nil, id), data)
+	})
+	if err != nil {
+		return 0, fmt.Errorf("record game: %w", err)
+	}
+	return g.ID, nil
+}
+
+// Games returns every stored game, oldest first.
+func (s *Store) Games() ([]Game, error) {
+	var games []Game
+	err := s.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(gamesBucket).ForEach(func(k, v []byte) error {
+			var g Game
+			if err := json.Unmarshal(v, &g); err != nil {
+				return fmt.Errorf("game %d: %w", binary.BigEndian.Uint64(k), err)
+			}
+			games = append(games, g)
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, fmt.Errorf("read stats: %w", err)
+	}
+	return games, nil
+}
+
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+// Record adds g to the database at DefaultPath.
+func Record(g Game) error {
+	path, err := DefaultPath()
+	if err != nil {
+		return err
+	}
+	s, err := Open(path)
+	if err != nil {
+		return err
+	}
+	_, err = s.Add(g)
+	return errors.Join(err, s.Close())
+}
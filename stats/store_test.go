@@ -0,0 +1,90 @@
+package stats
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/edge2992/yatzcli/engine"
+)
+
+// finishedGame plays a whole game between greedy AIs under rules.
+func finishedGame(t *testing.T, rules engine.RuleSet, names ...string) engine.GameState {
+	t.Helper()
+	state, err := engine.RunBattle(engine.BattleConfig{
+		Players: []engine.BattlePlayer{
+			{Name: names[0], Strategy: &engine.GreedyStrategy{}},
+			{Name: names[1], Strategy: &engine.StatisticalStrategy{}},
+		},
+		Seed:  int64(len(names[0])),
+		Rules: rules,
+	})
+	if err != nil {
+		t.Fatalf("RunBattle: %v", err)
+	}
+	return *state
+}
+
+func TestStore_AddAndGames(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sub", "stats.db")
+	s, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+
+	kniffel, _ := engine.RuleSetByName("kniffel")
+	states := []engine.GameState{
+		finishedGame(t, engine.DefaultRules(), "Alice", "Bot"),
+		finishedGame(t, kniffel, "Alice", "Bot"),
+	}
+	for i, st := range states {
+		id, err := s.Add(NewGame(ModeLocal, st, "player-0", map[string]string{"player-1": "statistical"}))
+		if err != nil {
+			t.Fatalf("Add: %v", err)
+		}
+		if id != uint64(i+1) {
+			t.Errorf("game %d got ID %d", i, id)
+		}
+	}
+	if err := s.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	// The games survive reopening.
+	s, err = Open(path)
+	if err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+	defer s.Close()
+	games, err := s.Games()
+	if err != nil {
+		t.Fatalf("Games: %v", err)
+	}
+	if len(games) != 2 {
+		t.Fatalf("got %d games, want 2", len(games))
+	}
+	for i, g := range games {
+		if g.ID != uint64(i+1) || g.Mode != ModeLocal || g.Rules != states[i].Rules {
+			t.Errorf("game %d = %+v", i, g)
+		}
+		alice, bot := g.Players[0], g.Players[1]
+		if !alice.You || alice.Strategy != "" || bot.You || bot.Strategy != "statistical" {
+			t.Errorf("game %d seats = %+v, %+v", i, alice, bot)
+		}
+		want := states[i].Players[0].Scorecard
+		if alice.Score != want.Total() || alice.Scorecard.Total() != want.Total() {
+			t.Errorf("game %d: score %d, scorecard total %d, want %d", i, alice.Score, alice.Scorecard.Total(), want.Total())
+		}
+		if alice.Scorecard.Rules().Name() != states[i].Rules {
+			t.Errorf("game %d: scorecard rules %s, want %s", i, alice.Scorecard.Rules().Name(), states[i].Rules)
+		}
+	}
+}
+
+func TestGame_Won(t *testing.T) {
+	g := Game{Players: []Player{{Score: 200}, {Score: 250}, {Score: 250}}}
+	for i, want := range []bool{false, true, true} {
+		if got := g.Won(i); got != want {
+			t.Errorf("Won(%d) = %v, want %v", i, got, want)
+		}
+	}
+}
@@ -0,0 +1,207 @@
+package stats
+
+import (
+	"cmp"
+	"slices"
+	"strings"
+
+	"github.com/edge2992/yatzcli/engine"
+)
+
+// Profile sums up one player's games.
+type Profile struct {
+	Games      int
+	Wins       int // games finished first, alone or tied
+	TotalScore int
+	BestScore  int
+	// Yahtzees counts five of a kinds scored, bonus ones included;
+	// YahtzeeGames counts the games with at least one.
+	Yahtzees     int
+	YahtzeeGames int
+	UpperBonuses int // games that earned the upper bonus
+	// Categories holds the average of each category the player has
+	// scored, in scorecard order.
+	Categories []CategoryAverage
+	// Opponents and Strategies are the player's head-to-head record
+	// against each opponent, and against each AI strategy, by most games.
+	Opponents  []Versus
+	Strategies []Versus
+}
+
+// CategoryAverage is a player's average score in a category.
+type CategoryAverage struct {
+	Category engine.Category
+	Games    int
+	Total    int
+}
+
+func (c CategoryAverage) Average() float64 { return ratio(c.Total, c.Games) }
+
+// Versus is a head-to-head record: the games against an opponent, and how
+// many the player outscored them in or was outscored in.
+type Versus struct {
+	Name   string
+	Games  int
+	Wins   int
+	Losses int
+}
+
+func (v Versus) WinRate() float64 { return ratio(v.Wins, v.Games) }
+
+func (p Profile) WinRate() float64        { return ratio(p.Wins, p.Games) }
+func (p Profile) AverageScore() float64   { return ratio(p.TotalScore, p.Games) }
+func (p Profile) YahtzeeRate() float64    { return ratio(p.YahtzeeGames, p.Games) }
+func (p Profile) UpperBonusRate() float64 { return ratio(p.UpperBonuses, p.Games) }
+
+// fiveOfAKind are the categories only five of a kind scores in, across the
+// rule sets.
+var fiveOfAKind = []engine.Category{engine.Yahtzee, engine.Yacht, engine.Generala, engine.DoubleGenerala}
+
+// ProfileOf sums up the games of the player called name, or with an empty
+// name, those of the seats played on this machine.
+func ProfileOf(games []Game, name string) Profile {
+	var p Profile
+	categories := make(map[engine.Category]*CategoryAverage)
+	var order []engine.Category
+	opponents := make(map[string]*Versus)
+	strategies := make(map[string]*Versus)
+
+	for _, g := range games {
+		i := slices.IndexFunc(g.Players, func(pl Player) bool {
+			if name == "" {
+				return pl.You
+			}
+			return pl.Name == name
+		})
+		if i < 0 {
+			continue
+		}
+		me := g.Players[i]
+		sc := me.Scorecard
+
+		p.Games++
+		if g.Won(i) {
+			p.Wins++
+		}
+		p.TotalScore += me.Score
+		p.BestScore = max(p.BestScore, me.Score)
+		yahtzees := sc.YahtzeeBonusCount()
+		for _, c := range fiveOfAKind {
+			if sc.GetScore(c) > 0 {
+				yahtzees++
+			}
+		}
+		p.Yahtzees += yahtzees
+		if yahtzees > 0 {
+			p.YahtzeeGames++
+		}
+		if sc.HasUpperBonus() {
+			p.UpperBonuses++
+		}
+		for _, c := range sc.Rules().Categories() {
+			if !sc.IsFilled(c) {
+				continue
+			}
+			avg, ok := categories[c]
+			if !ok {
+				avg = &CategoryAverage{Category: c}
+				categories[c] = avg
+				order = append(order, c)
+			}
+			avg.Games++
+			avg.Total += sc.GetScore(c)
+		}
+
+		for j, opp := range g.Players {
+			if j == i {
+				continue
+			}
+			tally(opponents, opp.Name, me.Score, opp.Score)
+			if opp.Strategy != "" {
+				tally(strategies, opp.Strategy, me.Score, opp.Score)
+			}
+		}
+	}
+
+	for _, c := range order {
+		p.Categories = append(p.Categories, *categories[c])
+	}
+	p.Opponents = sortedVersus(opponents)
+	p.Strategies = sortedVersus(strategies)
+	return p
+}
+
+// StrategyResult is how an AI strategy fared in every game it played.
+type StrategyResult struct {
+	Strategy   string
+	Games      int
+	Wins       int // games finished first, alone or tied
+	TotalScore int
+}
+
+func (s StrategyResult) WinRate() float64      { return ratio(s.Wins, s.Games) }
+func (s StrategyResult) AverageScore() float64 { return ratio(s.TotalScore, s.Games) }
+
+// StrategyResults sums up the games of every AI strategy, battles
+// included, by most games.
+func StrategyResults(games []Game) []StrategyResult {
+	byName := make(map[string]*StrategyResult)
+	var results []*StrategyResult
+	for _, g := range games {
+		for i, pl := range g.Players {
+			if pl.Strategy == "" {
+				continue
+			}
+			r, ok := byName[pl.Strategy]
+			if !ok {
+				r = &StrategyResult{Strategy: pl.Strategy}
+				byName[pl.Strategy] = r
+				results = append(results, r)
+			}
+			r.Games++
+			if g.Won(i) {
+				r.Wins++
+			}
+			r.TotalScore += pl.Score
+		}
+	}
+	out := make([]StrategyResult, len(results))
+	for i, r := range results {
+		out[i] = *r
+	}
+	slices.SortStableFunc(out, func(a, b StrategyResult) int { return b.Games - a.Games })
+	return out
+}
+
+func tally(m map[string]*Versus, name string, mine, theirs int) {
+	v, ok := m[name]
+	if !ok {
+		v = &Versus{Name: name}
+		m[name] = v
+	}
+	v.Games++
+	switch {
+	case mine > theirs:
+		v.Wins++
+	case mine < theirs:
+		v.Losses++
+	}
+}
+
+func sortedVersus(m map[string]*Versus) []Versus {
+	out := make([]Versus, 0, len(m))
+	for _, v := range m {
+		out = append(out, *v)
+	}
+	slices.SortFunc(out, func(a, b Versus) int {
+		return cmp.Or(b.Games-a.Games, strings.Compare(a.Name, b.Name))
+	})
+	return out
+}
+
+func ratio(n, d int) float64 {
+	if d == 0 {
+		return 0
+	}
+	return float64(n) / float64(d)
+}
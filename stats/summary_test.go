@@ -0,0 +1,87 @@
+package stats
+
+import (
+	"testing"
+
+	"github.com/edge2992/yatzcli/engine"
+)
+
+// player returns a seat whose scorecard holds scores.
+func player(name, strategy string, you bool, scores map[engine.Category]int) Player {
+	sc := engine.NewScorecard()
+	for c, s := range scores {
+		sc.Fill(c, s)
+	}
+	return Player{Name: name, Strategy: strategy, You: you, Score: sc.Total(), Scorecard: sc}
+}
+
+func TestProfileOf(t *testing.T) {
+	upper := map[engine.Category]int{
+		engine.Ones: 3, engine.Twos: 6, engine.Threes: 9,
+		engine.Fours: 12, engine.Fives: 15, engine.Sixes: 18,
+	}
+	games := []Game{
+		{Players: []Player{
+			player("Alice", "", true, upper),                                           // 63 + 35 bonus = 98
+			player("Bot", "greedy", false, map[engine.Category]int{engine.Chance: 20}), // 20
+		}},
+		{Players: []Player{
+			player("Bob", "", false, map[engine.Category]int{engine.Chance: 30}),
+			player("Alice", "", true, map[engine.Category]int{engine.Yahtzee: 50, engine.Chance: 10}),
+			player("Bot", "greedy", false, map[engine.Category]int{engine.Chance: 70}),
+		}},
+		{Players: []Player{ // a battle: no seat played here
+			player("Bot", "greedy", false, nil),
+			player("Stat", "statistical", false, map[engine.Category]int{engine.Chance: 5}),
+		}},
+	}
+
+	p := ProfileOf(games, "")
+	if p.Games != 2 || p.Wins != 1 {
+		t.Errorf("games %d wins %d, want 2 and 1", p.Games, p.Wins)
+	}
+	if p.BestScore != 98 || p.AverageScore() != 79 {
+		t.Errorf("best %d average %.1f, want 98 and 79", p.BestScore, p.AverageScore())
+	}
+	if p.Yahtzees != 1 || p.YahtzeeRate() != 0.5 || p.UpperBonusRate() != 0.5 {
+		t.Errorf("yahtzees %d (rate %.2f), upper bonus rate %.2f", p.Yahtzees, p.YahtzeeRate(), p.UpperBonusRate())
+	}
+	if len(p.Categories) != 8 || p.Categories[0].Category != engine.Ones {
+		t.Fatalf("categories = %+v", p.Categories)
+	}
+	for _, c := range p.Categories {
+		if c.Category == engine.Chance && (c.Games != 1 || c.Average() != 10) {
+			t.Errorf("chance = %+v", c)
+		}
+	}
+
+	want := []Versus{{Name: "Bot", Games: 2, Wins: 1, Losses: 1}, {Name: "Bob", Games: 1, Wins: 1}}
+	if len(p.Opponents) != 2 || p.Opponents[0] != want[0] || p.Opponents[1] != want[1] {
+		t.Errorf("opponents = %+v, want %+v", p.Opponents, want)
+	}
+	if len(p.Strategies) != 1 || p.Strategies[0] != (Versus{Name: "greedy", Games: 2, Wins: 1, Losses: 1}) {
+		t.Errorf("strategies = %+v", p.Strategies)
+	}
+
+	if bob := ProfileOf(games, "Bob"); bob.Games != 1 || bob.Wins != 0 {
+		t.Errorf("Bob: games %d wins %d, want 1 and 0", bob.Games, bob.Wins)
+	}
+}
+
+func TestStrategyResults(t *testing.T) {
+	games := []Game{
+		{Players: []Player{player("A", "greedy", false, nil), player("B", "statistical", false, map[engine.Category]int{engine.Chance: 20})}},
+		{Players: []Player{player("A", "greedy", false, map[engine.Category]int{engine.Chance: 30}), player("B", "statistical", false, map[engine.Category]int{engine.Chance: 20})}},
+		{Players: []Player{player("You", "", true, nil), player("A", "greedy", false, map[engine.Category]int{engine.Chance: 10})}},
+	}
+	got := StrategyResults(games)
+	if len(got) != 2 {
+		t.Fatalf("got %+v", got)
+	}
+	if g := got[0]; g.Strategy != "greedy" || g.Games != 3 || g.Wins != 2 || g.AverageScore() != 40.0/3 {
+		t.Errorf("greedy = %+v", g)
+	}
+	if s := got[1]; s.Strategy != "statistical" || s.Games != 2 || s.Wins != 1 || s.WinRate() != 0.5 {
+		t.Errorf("statistical = %+v", s)
+	}
+}
@@ -0,0 +1,37 @@
+package tournament
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"strconv"
+)
+
+// WriteCSV writes one row per game: round, seed, and each seat's player
+// and score.
+func (r *Result) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	_ = cw.Write([]string{"round", "seed", "player_1", "score_1", "player_2", "score_2"})
+	for _, g := range r.Games {
+		_ = cw.Write([]string{
+			strconv.Itoa(g.Round),
+			strconv.FormatInt(g.Seed, 10),
+			g.Players[0], strconv.Itoa(g.Scores[0]),
+			g.Players[1], strconv.Itoa(g.Scores[1]),
+		})
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// WriteJSON writes the tournament with its standings and head-to-head
+// records: head_to_head[i][j] is entries[i] against entries[j].
+func (r *Result) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(struct {
+		*Result
+		Standings  []Standing `json:"standings"`
+		HeadToHead [][]Record `json:"head_to_head"`
+	}{r, r.Standings(), r.HeadToHead()})
+}
@@ -0,0 +1,215 @@
+package tournament
+
+import (
+	"cmp"
+	"math"
+	"slices"
+)
+
+// z95 is the normal quantile for two-sided 95% confidence intervals.
+const z95 = 1.959964
+
+// Result is a played tournament: every game, in the order they were
+// scheduled.
+type Result struct {
+	Format  Format       `json:"format"`
+	Rules   string       `json:"rules"`
+	Seed    int64        `json:"seed"`
+	Rounds  int          `json:"rounds"`
+	Entries []string     `json:"entries"`
+	Games   []GameResult `json:"games"`
+}
+
+// Standing is an entry's record over the tournament. A win scores a point
+// and a draw half a point.
+type Standing struct {
+	Name   string  `json:"name"`
+	Games  int     `json:"games"`
+	Wins   int     `json:"wins"`
+	Draws  int     `json:"draws"`
+	Losses int     `json:"losses"`
+	Points float64 `json:"points"`
+	// Mean is the entry's mean score; CI95 is the half-width of its 95%
+	// confidence interval.
+	Mean   float64 `json:"mean"`
+	StdDev float64 `json:"stddev"`
+	CI95   float64 `json:"ci95"`
+	Min    int     `json:"min"`
+	Median float64 `json:"median"`
+	Max    int     `json:"max"`
+}
+
+// PointRate is the entry's points per game.
+func (s Standing) PointRate() float64 {
+	if s.Games == 0 {
+		return 0
+	}
+	return s.Points / float64(s.Games)
+}
+
+// Record is one entry's results against another.
+type Record struct {
+	Games  int `json:"games"`
+	Wins   int `json:"wins"`
+	Draws  int `json:"draws"`
+	Losses int `json:"losses"`
+	// MeanDiff is the mean of the entry's score minus the opponent's, game
+	// by game; CI95 is the half-width of its 95% confidence interval.
+	MeanDiff float64 `json:"mean_diff"`
+	CI95     float64 `json:"ci95"`
+}
+
+// Significant reports whether the 95% confidence interval of the score
+// difference excludes zero: one entry outscores the other beyond luck.
+func (r Record) Significant() bool {
+	return r.Games > 1 && math.Abs(r.MeanDiff) > r.CI95
+}
+
+// Bin counts the scores in [Lo, Hi).
+type Bin struct {
+	Lo    int `json:"lo"`
+	Hi    int `json:"hi"`
+	Count int `json:"count"`
+}
+
+// scores returns each entry's scores, by entry index.
+func (r *Result) scores() [][]int {
+	scores := make([][]int, len(r.Entries))
+	for _, g := range r.Games {
+		for seat, name := range g.Players {
+			i := slices.Index(r.Entries, name)
+			scores[i] = append(scores[i], g.Scores[seat])
+		}
+	}
+	return scores
+}
+
+// Standings returns the entries' records, best first: by points per game,
+// then mean score.
+func (r *Result) Standings() []Standing {
+	standings := make([]Standing, len(r.Entries))
+	for i, name := range r.Entries {
+		standings[i].Name = name
+	}
+	for _, g := range r.Games {
+		a, b := slices.Index(r.Entries, g.Players[0]), slices.Index(r.Entries, g.Players[1])
+		tallyGame(&standings[a].Wins, &standings[a].Draws, &standings[a].Losses, g.Scores[0], g.Scores[1])
+		tallyGame(&standings[b].Wins, &standings[b].Draws, &standings[b].Losses, g.Scores[1], g.Scores[0])
+	}
+	for i, scores := range r.scores() {
+		s := &standings[i]
+		s.Games = len(scores)
+		s.Points = float64(s.Wins) + float64(s.Draws)/2
+		if len(scores) == 0 {
+			continue
+		}
+		xs := make([]float64, len(scores))
+		for j, v := range scores {
+			xs[j] = float64(v)
+		}
+		s.Mean, s.StdDev, s.CI95 = meanCI(xs)
+		sorted := slices.Sorted(slices.Values(scores))
+		s.Min, s.Max = sorted[0], sorted[len(sorted)-1]
+		mid := len(sorted) / 2
+		s.Median = float64(sorted[mid])
+		if len(sorted)%2 == 0 {
+			s.Median = float64(sorted[mid-1]+sorted[mid]) / 2
+		}
+	}
+	slices.SortStableFunc(standings, func(a, b Standing) int {
+		return cmp.Or(cmp.Compare(b.PointRate(), a.PointRate()), cmp.Compare(b.Mean, a.Mean))
+	})
+	return standings
+}
+
+// ranking returns the entry indices in standings order.
+func (r *Result) ranking() []int {
+	var order []int
+	for _, s := range r.Standings() {
+		order = append(order, slices.Index(r.Entries, s.Name))
+	}
+	return order
+}
+
+// HeadToHead returns the record of each entry against each other:
+// HeadToHead()[i][j] is Entries[i] against Entries[j].
+func (r *Result) HeadToHead() [][]Record {
+	n := len(r.Entries)
+	recs := make([][]Record, n)
+	diffs := make([][][]float64, n)
+	for i := range n {
+		recs[i] = make([]Record, n)
+		diffs[i] = make([][]float64, n)
+	}
+	for _, g := range r.Games {
+		a, b := slices.Index(r.Entries, g.Players[0]), slices.Index(r.Entries, g.Players[1])
+		tallyGame(&recs[a][b].Wins, &recs[a][b].Draws, &recs[a][b].Losses, g.Scores[0], g.Scores[1])
+		tallyGame(&recs[b][a].Wins, &recs[b][a].Draws, &recs[b][a].Losses, g.Scores[1], g.Scores[0])
+		d := float64(g.Scores[0] - g.Scores[1])
+		diffs[a][b] = append(diffs[a][b], d)
+		diffs[b][a] = append(diffs[b][a], -d)
+	}
+	for i := range n {
+		for j := range n {
+			rec := &recs[i][j]
+			rec.Games = len(diffs[i][j])
+			if rec.Games > 0 {
+				rec.MeanDiff, _, rec.CI95 = meanCI(diffs[i][j])
+			}
+		}
+	}
+	return recs
+}
+
+// Distribution returns a histogram of the named entry's scores in bins of
+// width points, from the bin of the lowest score to that of the highest.
+func (r *Result) Distribution(name string, width int) []Bin {
+	i := slices.Index(r.Entries, name)
+	if i < 0 || width <= 0 {
+		return nil
+	}
+	scores := r.scores()[i]
+	if len(scores) == 0 {
+		return nil
+	}
+	lo := slices.Min(scores) / width * width
+	hi := slices.Max(scores)/width*width + width
+	bins := make([]Bin, (hi-lo)/width)
+	for k := range bins {
+		bins[k] = Bin{Lo: lo + k*width, Hi: lo + (k+1)*width}
+	}
+	for _, s := range scores {
+		bins[(s-lo)/width].Count++
+	}
+	return bins
+}
+
+func tallyGame(wins, draws, losses *int, mine, theirs int) {
+	switch {
+	case mine > theirs:
+		*wins++
+	case mine < theirs:
+		*losses++
+	default:
+		*draws++
+	}
+}
+
+// meanCI returns the mean and sample standard deviation of xs, and the
+// half-width of the mean's 95% confidence interval (normal approximation).
+func meanCI(xs []float64) (mean, stddev, ci float64) {
+	n := float64(len(xs))
+	for _, x := range xs {
+		mean += x
+	}
+	mean /= n
+	if len(xs) < 2 {
+		return mean, 0, 0
+	}
+	var ss float64
+	for _, x := range xs {
+		ss += (x - mean) * (x - mean)
+	}
+	stddev = math.Sqrt(ss / (n - 1))
+	return mean, stddev, z95 * stddev / math.Sqrt(n)
+}
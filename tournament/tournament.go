@@ -0,0 +1,245 @@
+// Package tournament plays AI strategies against each other in many
+// head-to-head games, and reports how they compare with confidence
+// intervals.
+//
+// Every game is rolled with engine.PairedDice, and each seed is played
+// from both seats: the two players of a game see the same dice, and every
+// match of a round uses the same seeds, so the luck of the dice cancels
+// out of the comparisons.
+package tournament
+
+import (
+	"cmp"
+	"fmt"
+	"math"
+	"runtime"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/edge2992/yatzcli/engine"
+)
+
+// Format is how entries are paired.
+type Format string
+
+const (
+	// RoundRobin pairs every entry with every other once.
+	RoundRobin Format = "round-robin"
+	// Swiss plays rounds in which entries with similar records meet,
+	// avoiding rematches.
+	Swiss Format = "swiss"
+)
+
+// DefaultGames is the number of seeds a match is played with by default.
+const DefaultGames = 10
+
+// Config describes a tournament.
+type Config struct {
+	// Entries are the competitors. Their strategies play many games at
+	// once, so they must be safe for concurrent use.
+	Entries []engine.BattlePlayer
+	Format  Format // RoundRobin if empty
+	// Games is the number of seeds each match is played with; each seed is
+	// played twice, once from each seat. DefaultGames if zero.
+	Games int
+	// Rounds is the number of Swiss rounds; zero plays enough to rank the
+	// entries, ceil(log2(len(Entries))).
+	Rounds  int
+	Seed    int64          // first seed; zero picks one at random
+	Workers int            // games played at once; GOMAXPROCS if zero
+	Rules   engine.RuleSet // nil uses DefaultRules
+	// OnGame, if set, is called after each game. Calls don't overlap.
+	OnGame func(GameResult)
+}
+
+// GameResult is the outcome of one game.
+type GameResult struct {
+	Round   int       `json:"round"`
+	Seed    int64     `json:"seed"`
+	Players [2]string `json:"players"` // in seat order
+	Scores  [2]int    `json:"scores"`
+}
+
+// Run plays the tournament described by cfg.
+func Run(cfg Config) (*Result, error) {
+	n := len(cfg.Entries)
+	if n < 2 {
+		return nil, fmt.Errorf("a tournament needs at least 2 entries, got %d", n)
+	}
+	names := make([]string, n)
+	for i, e := range cfg.Entries {
+		if slices.Contains(names[:i], e.Name) {
+			return nil, fmt.Errorf("duplicate entry name %q", e.Name)
+		}
+		names[i] = e.Name
+	}
+	if cfg.Games <= 0 {
+		cfg.Games = DefaultGames
+	}
+	if cfg.Workers <= 0 {
+		cfg.Workers = runtime.GOMAXPROCS(0)
+	}
+	if cfg.Rules == nil {
+		cfg.Rules = engine.DefaultRules()
+	}
+	if cfg.Seed == 0 {
+		cfg.Seed = time.Now().UnixNano()
+	}
+
+	rounds := 1
+	switch cfg.Format {
+	case "", RoundRobin:
+		cfg.Format = RoundRobin
+	case Swiss:
+		rounds = cfg.Rounds
+		if rounds <= 0 {
+			rounds = max(1, int(math.Ceil(math.Log2(float64(n)))))
+		}
+	default:
+		return nil, fmt.Errorf("unknown tournament format %q (available: %s, %s)", cfg.Format, RoundRobin, Swiss)
+	}
+
+	r := &Result{Format: cfg.Format, Rules: cfg.Rules.Name(), Seed: cfg.Seed, Rounds: rounds, Entries: names}
+	played := make(map[[2]int]bool)
+	byes := make([]bool, n)
+	for round := range rounds {
+		var pairs [][2]int
+		if cfg.Format == RoundRobin {
+			pairs = roundRobinPairs(n)
+		} else {
+			pairs = swissPairs(r.ranking(), played, byes)
+		}
+		games, err := playRound(cfg, round, pairs)
+		if err != nil {
+			return nil, err
+		}
+		r.Games = append(r.Games, games...)
+		for _, p := range pairs {
+			played[p] = true
+			played[[2]int{p[1], p[0]}] = true
+		}
+	}
+	return r, nil
+}
+
+func roundRobinPairs(n int) [][2]int {
+	var pairs [][2]int
+	for i := range n {
+		for j := i + 1; j < n; j++ {
+			pairs = append(pairs, [2]int{i, j})
+		}
+	}
+	return pairs
+}
+
+// swissPairs pairs the entries in ranking order, each with the next
+// entry it hasn't met, or failing that the next entry. With an odd number
+// of entries, the lowest-ranked entry without a bye sits the round out.
+func swissPairs(ranking []int, played map[[2]int]bool, byes []bool) [][2]int {
+	order := slices.Clone(ranking)
+	if len(order)%2 == 1 {
+		bye := len(order) - 1
+		for i := len(order) - 1; i >= 0; i-- {
+			if !byes[order[i]] {
+				bye = i
+				break
+			}
+		}
+		byes[order[bye]] = true
+		order = slices.Delete(order, bye, bye+1)
+	}
+
+	var pairs [][2]int
+	for len(order) > 0 {
+		a := order[0]
+		j := slices.IndexFunc(order[1:], func(b int) bool { return !played[[2]int{a, b}] }) + 1
+		if j == 0 {
+			j = 1
+		}
+		pairs = append(pairs, [2]int{a, order[j]})
+		order = slices.Delete(order, j, j+1)[1:]
+	}
+	return pairs
+}
+
+// job is one game of a round: the entries of pair play seed, with their
+// seats swapped or not. index is its place in the round's results.
+type job struct {
+	index   int
+	pair    [2]int
+	seed    int64
+	swapped bool
+}
+
+// playRound plays every match of round on cfg.Workers goroutines. The
+// games come back in a fixed order, whatever order they finished in.
+func playRound(cfg Config, round int, pairs [][2]int) ([]GameResult, error) {
+	var jobs []job
+	for _, p := range pairs {
+		for g := range cfg.Games {
+			seed := cfg.Seed + int64(round*cfg.Games+g)
+			jobs = append(jobs, job{len(jobs), p, seed, false}, job{len(jobs) + 1, p, seed, true})
+		}
+	}
+
+	results := make([]GameResult, len(jobs))
+	var (
+		mu       sync.Mutex
+		firstErr error
+		wg       sync.WaitGroup
+	)
+	ch := make(chan job)
+	for range min(cfg.Workers, len(jobs)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range ch {
+				res, err := playGame(cfg, round, j)
+				mu.Lock()
+				if err != nil {
+					firstErr = cmp.Or(firstErr, err)
+				} else {
+					results[j.index] = res
+					if cfg.OnGame != nil {
+						cfg.OnGame(res)
+					}
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	for _, j := range jobs {
+		mu.Lock()
+		failed := firstErr != nil
+		mu.Unlock()
+		if failed {
+			break
+		}
+		ch <- j
+	}
+	close(ch)
+	wg.Wait()
+	return results, firstErr
+}
+
+func playGame(cfg Config, round int, j job) (GameResult, error) {
+	a, b := cfg.Entries[j.pair[0]], cfg.Entries[j.pair[1]]
+	if j.swapped {
+		a, b = b, a
+	}
+	state, err := engine.RunBattle(engine.BattleConfig{
+		Players: []engine.BattlePlayer{a, b},
+		Dice:    engine.NewPairedDice(j.seed),
+		Rules:   cfg.Rules,
+	})
+	if err != nil {
+		return GameResult{}, fmt.Errorf("%s vs %s, seed %d: %w", a.Name, b.Name, j.seed, err)
+	}
+	return GameResult{
+		Round:   round + 1,
+		Seed:    j.seed,
+		Players: [2]string{a.Name, b.Name},
+		Scores:  [2]int{state.Players[0].Scorecard.Total(), state.Players[1].Scorecard.Total()},
+	}, nil
+}
@@ -0,0 +1,178 @@
+package tournament
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"testing"
+
+	"github.com/edge2992/yatzcli/engine"
+)
+
+// firstOpenStrategy scores its first roll in the first open category.
+type firstOpenStrategy struct{}
+
+func (firstOpenStrategy) Name() string { return "first-open" }
+
+func (firstOpenStrategy) DecideAction(dice [5]int, rollCount int, scorecard engine.Scorecard, available []engine.Category) engine.TurnAction {
+	return engine.TurnAction{Type: "score", Category: available[0]}
+}
+
+func entries() []engine.BattlePlayer {
+	return []engine.BattlePlayer{
+		{Name: "Greedy", Strategy: &engine.GreedyStrategy{}},
+		{Name: "Greedy2", Strategy: &engine.GreedyStrategy{}},
+		{Name: "First", Strategy: firstOpenStrategy{}},
+	}
+}
+
+func TestRun_RoundRobin(t *testing.T) {
+	var seen int
+	r, err := Run(Config{Entries: entries(), Games: 8, Seed: 1, Workers: 4, OnGame: func(GameResult) { seen++ }})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 3 matches of 8 seeds, each played from both seats.
+	if len(r.Games) != 48 || seen != 48 {
+		t.Fatalf("%d games, %d reported, want 48", len(r.Games), seen)
+	}
+
+	st := r.Standings()
+	if st[2].Name != "First" || st[0].Games != 32 || st[0].Mean <= st[2].Mean || st[0].CI95 <= 0 {
+		t.Errorf("standings = %+v", st)
+	}
+	if st[0].Min > int(st[0].Median) || int(st[0].Median) > st[0].Max {
+		t.Errorf("min %d median %.1f max %d", st[0].Min, st[0].Median, st[0].Max)
+	}
+
+	h2h := r.HeadToHead()
+	// Identical strategies on paired dice play identical games.
+	if twin := h2h[0][1]; twin.Games != 16 || twin.Draws != 16 || twin.MeanDiff != 0 || twin.Significant() {
+		t.Errorf("Greedy vs Greedy2 = %+v, want 16 draws", twin)
+	}
+	if rec := h2h[0][2]; rec.MeanDiff <= 0 || !rec.Significant() {
+		t.Errorf("Greedy vs First = %+v, want a significant win", rec)
+	}
+	if a, b := h2h[0][2], h2h[2][0]; a.Wins != b.Losses || a.MeanDiff != -b.MeanDiff {
+		t.Errorf("records disagree: %+v and %+v", a, b)
+	}
+
+	// The same seed plays the same tournament, however many workers.
+	again, err := Run(Config{Entries: entries(), Games: 8, Seed: 1, Workers: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range r.Games {
+		if r.Games[i] != again.Games[i] {
+			t.Fatalf("game %d: %+v, then %+v", i, r.Games[i], again.Games[i])
+		}
+	}
+}
+
+func TestRun_Swiss(t *testing.T) {
+	es := append(entries(), engine.BattlePlayer{Name: "Greedy3", Strategy: &engine.GreedyStrategy{}},
+		engine.BattlePlayer{Name: "First2", Strategy: firstOpenStrategy{}})
+	r, err := Run(Config{Entries: es, Format: Swiss, Games: 2, Seed: 5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Rounds != 3 {
+		t.Fatalf("rounds = %d, want 3 for 5 entries", r.Rounds)
+	}
+	// Each round pairs 4 of the 5 entries: 2 matches of 2 seeds, both seats.
+	if len(r.Games) != 3*2*4 {
+		t.Fatalf("%d games, want 24", len(r.Games))
+	}
+	met := make(map[[2]string]int)
+	sitting := make(map[string]int)
+	for round := 1; round <= 3; round++ {
+		playing := make(map[string]bool)
+		for _, g := range r.Games {
+			if g.Round != round {
+				continue
+			}
+			playing[g.Players[0]], playing[g.Players[1]] = true, true
+			if g.Players[0] < g.Players[1] {
+				met[g.Players]++
+			}
+		}
+		for _, e := range es {
+			if !playing[e.Name] {
+				sitting[e.Name]++
+			}
+		}
+	}
+	for pair, n := range met {
+		if n != 2 {
+			t.Errorf("%v met in %d games, want one match of 2", pair, n)
+		}
+	}
+	for name, n := range sitting {
+		if n > 1 {
+			t.Errorf("%s sat out %d rounds", name, n)
+		}
+	}
+}
+
+func TestRun_Errors(t *testing.T) {
+	if _, err := Run(Config{Entries: entries()[:1]}); err == nil {
+		t.Error("expected an error for one entry")
+	}
+	dup := append(entries(), engine.BattlePlayer{Name: "Greedy", Strategy: &engine.GreedyStrategy{}})
+	if _, err := Run(Config{Entries: dup}); err == nil {
+		t.Error("expected an error for a duplicate name")
+	}
+	if _, err := Run(Config{Entries: entries(), Format: "knockout"}); err == nil {
+		t.Error("expected an error for an unknown format")
+	}
+}
+
+func TestResult_DistributionAndExport(t *testing.T) {
+	r := &Result{
+		Format:  RoundRobin,
+		Entries: []string{"A", "B"},
+		Games: []GameResult{
+			{Round: 1, Seed: 1, Players: [2]string{"A", "B"}, Scores: [2]int{105, 90}},
+			{Round: 1, Seed: 1, Players: [2]string{"B", "A"}, Scores: [2]int{90, 139}},
+			{Round: 1, Seed: 2, Players: [2]string{"A", "B"}, Scores: [2]int{150, 150}},
+		},
+	}
+	bins := r.Distribution("A", 25)
+	want := []Bin{{100, 125, 1}, {125, 150, 1}, {150, 175, 1}}
+	if len(bins) != len(want) {
+		t.Fatalf("bins = %+v", bins)
+	}
+	for i := range want {
+		if bins[i] != want[i] {
+			t.Errorf("bin %d = %+v, want %+v", i, bins[i], want[i])
+		}
+	}
+	if st := r.Standings(); st[0].Name != "A" || st[0].Wins != 2 || st[0].Draws != 1 || st[0].Points != 2.5 {
+		t.Errorf("standings = %+v", st)
+	}
+
+	var buf bytes.Buffer
+	if err := r.WriteCSV(&buf); err != nil {
+		t.Fatal(err)
+	}
+	rows, err := csv.NewReader(&buf).ReadAll()
+	if err != nil || len(rows) != 4 || rows[2][2] != "B" || rows[2][5] != "139" {
+		t.Errorf("csv = %v, %v", rows, err)
+	}
+
+	buf.Reset()
+	if err := r.WriteJSON(&buf); err != nil {
+		t.Fatal(err)
+	}
+	var out struct {
+		Games      []GameResult `json:"games"`
+		Standings  []Standing   `json:"standings"`
+		HeadToHead [][]Record   `json:"head_to_head"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &out); err != nil {
+		t.Fatal(err)
+	}
+	if len(out.Games) != 3 || len(out.Standings) != 2 || out.HeadToHead[0][1].Wins != 2 {
+		t.Errorf("json = %+v", out)
+	}
+}